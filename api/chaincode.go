@@ -2,9 +2,13 @@ package api
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric/msp"
+
+	"github.com/s7techlab/hlf-sdk-go/block"
 )
 
 type TransArgs map[string][]byte
@@ -33,13 +37,62 @@ type TxWaiter interface {
 	Wait(ctx context.Context, channel string, txId string) error
 }
 
+// TxWaitRegisterer - tx waiter, which registers transaction before it is sent to orderer,
+// so commit isn't missed if commit status is requested after transaction is committed
+type TxWaitRegisterer interface {
+	Register(ctx context.Context, channel string, txId string) (TxWait, error)
+}
+
+// TxWait waits for registered transaction commit
+type TxWait func(ctx context.Context) error
+
+// TxWaiterBuilder - tx waiter constructor, called after endorsement with EndorsingMspIDs of actual endorsers
+type TxWaiterBuilder func(cfg *DoOptions) (TxWaiter, error)
+
 type DoOptions struct {
 	Identity msp.SigningIdentity
 	Pool     PeerPool
 
 	TxWaiter TxWaiter
-	// necessary only for 'tx waiter all'
+	// TxWaiterBuilder - if set, tx waiter is built after endorsement instead of TxWaiter,
+	// as endorsing MSPs are known only when endorsement layout is chosen
+	TxWaiterBuilder TxWaiterBuilder
+	// CommitNotifiers - if set, tx waiters use shared block streams instead of subscription per transaction
+	CommitNotifiers CommitNotifiers
+	// EndorsingMspIDs - MSPs, proposal is endorsed on if layouts are not set.
+	// Tx waiter builder gets MSPs, which actually endorsed proposal
 	EndorsingMspIDs []string
+	// EndorsementLayouts - if set, proposal is endorsed on first layout which MSPs are able to endorse
+	// instead of all EndorsingMspIDs
+	EndorsementLayouts []EndorsementLayout
+	// MVCCRetry - if set, transaction invalidated due to MVCC or phantom read conflict
+	// is re-endorsed and resubmitted with new tx id
+	MVCCRetry *MVCCRetry
+}
+
+// MVCCRetry describes retries of transaction invalidated due to MVCC conflict
+type MVCCRetry struct {
+	// MaxAttempts - total number of attempts including the first one
+	MaxAttempts int
+	// Backoff - delay before the first retry, delay is doubled on each next retry
+	Backoff time.Duration
+	// MaxBackoff - max delay between retries, not limited if zero
+	MaxBackoff time.Duration
+	// Jitter - fraction of delay in [0, 1], delay is randomly changed on, i.e. 0.2 is +/- 20%
+	Jitter float64
+	// OnAttempt - if set, called after each attempt
+	OnAttempt func(attempt MVCCRetryAttempt)
+}
+
+// MVCCRetryAttempt describes result of transaction submit attempt
+type MVCCRetryAttempt struct {
+	// Attempt - number of attempt, starting from 1
+	Attempt int
+	TxID    string
+	Err     error
+	// Retry - transaction will be resubmitted after Delay
+	Retry bool
+	Delay time.Duration
 }
 
 type DoOption func(opt *DoOptions) error
@@ -47,11 +100,37 @@ type DoOption func(opt *DoOptions) error
 func WithEndorsingMpsIDs(mspIDs []string) DoOption {
 	return func(opt *DoOptions) error {
 		opt.EndorsingMspIDs = mspIDs
+		// explicitly defined MSPs take precedence over endorsement policy
+		opt.EndorsementLayouts = nil
+
+		return nil
+	}
+}
+
+// WithEndorsementLayouts sets combinations of MSPs satisfying endorsement policy
+func WithEndorsementLayouts(layouts []EndorsementLayout) DoOption {
+	return func(opt *DoOptions) error {
+		opt.EndorsementLayouts = layouts
 
 		return nil
 	}
 }
 
+// WithMVCCRetry enables resubmitting of transaction invalidated due to MVCC or phantom read conflict
+func WithMVCCRetry(retry MVCCRetry) DoOption {
+	return func(opt *DoOptions) error {
+		if retry.MaxAttempts < 1 {
+			return fmt.Errorf(`mvcc retry max attempts=%d, must be positive`, retry.MaxAttempts)
+		}
+		if retry.Jitter < 0 || retry.Jitter > 1 {
+			return fmt.Errorf(`mvcc retry jitter=%f, must be in [0, 1]`, retry.Jitter)
+		}
+
+		opt.MVCCRetry = &retry
+		return nil
+	}
+}
+
 func WithIdentity(identity msp.SigningIdentity) DoOption {
 	return func(opt *DoOptions) error {
 		opt.Identity = identity
@@ -74,6 +153,29 @@ type ChaincodeInvokeBuilder interface {
 	ArgString(args ...string) ChaincodeInvokeBuilder
 	// Do makes invoke with built arguments
 	Do(ctx context.Context, opts ...DoOption) (response *peer.Response, txID string, err error)
+	// Endorse endorses proposal with built arguments, endorsed transaction can be inspected before submit
+	Endorse(ctx context.Context, opts ...DoOption) (EndorsedTransaction, error)
+}
+
+// EndorsedTransaction - transaction, endorsed by peers and not sent to orderer yet
+type EndorsedTransaction interface {
+	TxID() string
+	// ProposalResponses returns responses of endorsing peers
+	ProposalResponses() []*peer.ProposalResponse
+	// Result returns chaincode response, simulated by endorsing peers
+	Result() *peer.Response
+	// ReadWriteSet returns read-write set, simulated by endorsing peers
+	ReadWriteSet() (*block.TxReadWriteSet, error)
+	// Submit sends transaction to orderer
+	Submit(ctx context.Context) (Commit, error)
+}
+
+// Commit - transaction, sent to orderer
+type Commit interface {
+	TxID() string
+	// Status waits for transaction commit with tx waiter and returns validation code,
+	// error is returned if transaction is invalid
+	Status(ctx context.Context) (peer.TxValidationCode, error)
 }
 
 // ChaincodeQueryBuilder describe possibilities how to get query results
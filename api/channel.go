@@ -16,4 +16,12 @@ type Channel interface {
 const (
 	TxWaiterSelfType string = "self"
 	TxWaiterAllType  string = "all"
+	// TxWaiterAnyType - wait for one peer of any organization from endorsement policy
+	TxWaiterAnyType string = "any"
+	// TxWaiterNoneType - "fire and forget", don't wait for transaction commit
+	TxWaiterNoneType string = "none"
+	// TxWaiterQuorumPrefix - wait for N organizations from endorsement policy, i.e. "quorum:2"
+	TxWaiterQuorumPrefix string = "quorum:"
+	// TxWaiterMSPsPrefix - wait for each of listed organizations, i.e. "msp:Org1MSP,Org2MSP"
+	TxWaiterMSPsPrefix string = "msp:"
 )
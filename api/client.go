@@ -75,7 +75,9 @@ type Invoker interface {
 	// Invoke - shortcut for invoking chaincodes
 	// if provided 'identity' is 'nil' default one will be set
 	// txWaiterType - param which identify transaction waiting policy.
-	// available: 'self'(wait for one peer of endorser org), 'all'(wait for each organization from endorsement policy)
+	// available: 'self'(wait for one peer of endorser org), 'all'(wait for each organization from endorsement policy),
+	// 'any'(wait for any organization from endorsement policy), 'none'(don't wait for commit),
+	// 'quorum:N'(wait for N organizations from endorsement policy), 'msp:MSP1,MSP2'(wait for each of listed organizations)
 	// default is 'self'(even if you pass empty string)
 	Invoke(
 		ctx context.Context,
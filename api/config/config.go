@@ -17,6 +17,16 @@ type Config struct {
 	Pool PoolConfig  `yaml:"pool"`
 	// if tls is enabled maps TLS certs to discovered peers
 	EndpointsMap []Endpoint `yaml:"endpoints_map"`
+	// Gateway - connection to peer with Fabric Gateway service (fabric v2.4+),
+	// if set, invokes and queries of client are sent through gateway
+	Gateway *ConnectionConfig `yaml:"gateway"`
+	// BroadcastStream - if set, transactions are sent to each orderer through one long-lived broadcast stream
+	BroadcastStream *BroadcastStreamConfig `yaml:"broadcast_stream"`
+}
+
+type BroadcastStreamConfig struct {
+	// MaxInFlight - max number of transactions waiting for orderer response, default: 1000
+	MaxInFlight int `yaml:"max_in_flight"`
 }
 
 type ConnectionConfig struct {
@@ -34,6 +44,22 @@ type CAConfig struct {
 
 type PoolConfig struct {
 	DeliverTimeout Duration `yaml:"deliver_timeout"`
+	// Balancer - strategy of choosing MSP peer for endorsement:
+	// first (default), round_robin, least_in_flight, latency, random
+	Balancer string `yaml:"balancer"`
+	// CircuitBreaker - per peer circuit breaker, disabled if not set
+	CircuitBreaker *CircuitBreakerConfig `yaml:"circuit_breaker"`
+}
+
+type CircuitBreakerConfig struct {
+	// ConsecutiveFailures trips breaker after number of consecutive peer failures, 0 - disabled
+	ConsecutiveFailures uint `yaml:"consecutive_failures"`
+	// ErrorRate trips breaker if failures part in the last Window endorsements reaches it, 0 - disabled
+	ErrorRate float64 `yaml:"error_rate"`
+	// Window - number of last endorsements for ErrorRate calculation, default: 20
+	Window uint `yaml:"window"`
+	// Cooldown - time breaker stays open before probing peer, default: 10 sec.
+	Cooldown Duration `yaml:"cooldown"`
 }
 
 type MSPConfig struct {
@@ -81,10 +107,32 @@ type DiscoveryConfig struct {
 	Type string `yaml:"type"`
 	// connection to local MSP which will be used for gossip discovery
 	Connection *ConnectionConfig `yaml:"connection"`
+	// RefreshPeriod - period of gossip discovery re-query for reconciling peer pool and chaincodes endorsers, 0 - disabled
+	RefreshPeriod Duration `yaml:"refresh_period"`
+	// Cache - caching of discovery results, disabled if not set
+	Cache *DiscoveryCacheConfig `yaml:"cache"`
 	// configuration of channels/chaincodes in local(from config) discovery type
 	Options DiscoveryConfigOpts `yaml:"options"`
 }
 
+type DiscoveryCacheConfig struct {
+	// TTL - default TTL of cached discovery results, default: 1 min.
+	TTL Duration `yaml:"ttl"`
+	// Channels - channels and chaincodes with own TTL
+	Channels []DiscoveryCacheChannel `yaml:"channels"`
+}
+
+type DiscoveryCacheChannel struct {
+	Name       string                    `yaml:"name"`
+	TTL        Duration                  `yaml:"ttl"`
+	Chaincodes []DiscoveryCacheChaincode `yaml:"chaincodes"`
+}
+
+type DiscoveryCacheChaincode struct {
+	Name string   `yaml:"name"`
+	TTL  Duration `yaml:"ttl"`
+}
+
 // DiscoveryConfigOpts - channel configuration for local config
 // contains []DiscoveryChannel
 type DiscoveryConfigOpts map[string]interface{}
@@ -99,6 +147,8 @@ type DiscoveryChaincode struct {
 	Name    string `json:"chaincode_name" yaml:"name"`
 	Version string `json:"version"`
 	Policy  string `json:"policy"`
+	// Pinned - endorsers are always taken from local config policy, even if gossip discovery is available (hybrid discovery)
+	Pinned bool `json:"pinned" yaml:"pinned"`
 }
 
 type Duration struct {
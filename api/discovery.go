@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"strings"
 
 	"github.com/s7techlab/hlf-sdk-go/api/config"
 )
@@ -12,15 +13,61 @@ type DiscoveryProvider interface {
 	LocalPeers(ctx context.Context) (LocalPeersDiscoverer, error)
 }
 
+// InvocationChainDiscoveryProvider - discovery provider, which finds endorsers for transaction,
+// calling several chaincodes and/or writing private data collections
+type InvocationChainDiscoveryProvider interface {
+	// InvocationChain returns endorsers, satisfying endorsement policies of all chaincodes and collections in chain.
+	// First chain call is invoked chaincode
+	InvocationChain(ctx context.Context, channelName string, chain InvocationChain) (ChaincodeDiscoverer, error)
+}
+
+// ChaincodeCall - chaincode and its private data collections, used in transaction
+type ChaincodeCall struct {
+	Name        string
+	Collections []string
+}
+
+// InvocationChain - chaincode calls of transaction, first call is invoked chaincode
+type InvocationChain []ChaincodeCall
+
+// Key returns string representation of chain, i.e. for using as map key
+func (c InvocationChain) Key() string {
+	var key string
+	for i, call := range c {
+		if i > 0 {
+			key += `,`
+		}
+		key += call.Name
+		if len(call.Collections) > 0 {
+			key += `(` + strings.Join(call.Collections, `,`) + `)`
+		}
+	}
+	return key
+}
+
+// Simple reports whether chain contains only one chaincode call without collections
+func (c InvocationChain) Simple() bool {
+	return len(c) == 1 && len(c[0].Collections) == 0
+}
+
 // ChaincodeDiscoverer - looking for info about network, channel, chaincode in local configs or gossip
 type ChaincodeDiscoverer interface {
 	Endorsers() []*HostEndpoint
+	// EndorsementLayouts returns combinations of MSPs satisfying chaincode endorsement policy,
+	// empty if policy is unknown and all endorsers must be used
+	EndorsementLayouts() []EndorsementLayout
 	ChaincodeName() string
 	ChaincodeVersion() string
 
 	ChannelDiscoverer
 }
 
+// LayoutsEndorsersDiscoverer - chaincode discoverer, which exposes endorsers of all endorsement layouts.
+// Endorsers returns endorsers of the first (minimal) layout only
+type LayoutsEndorsersDiscoverer interface {
+	LayoutsEndorsers() []*HostEndpoint
+}
+
 // ChannelDiscoverer - info about orderers in channel
 type ChannelDiscoverer interface {
 	Orderers() []*HostEndpoint
@@ -32,6 +79,9 @@ type LocalPeersDiscoverer interface {
 	Peers() []*HostEndpoint
 }
 
+// EndorsementLayout - MSP IDs, endorsements from one peer of each of them satisfy endorsement policy
+type EndorsementLayout []string
+
 type HostEndpoint struct {
 	MspID string
 	// each host could have own tls settings
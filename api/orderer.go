@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/hyperledger/fabric-protos-go/common"
 	"github.com/hyperledger/fabric-protos-go/orderer"
@@ -16,3 +17,13 @@ type Orderer interface {
 	// GetConfigBlock returns last config block
 	GetConfigBlock(ctx context.Context, signer msp.SigningIdentity, channelName string) (*common.Block, error)
 }
+
+// OrdererStatusError - orderer responded with non-success status, i.e. rejected broadcast transaction
+type OrdererStatusError struct {
+	Status common.Status
+	Info   string
+}
+
+func (e *OrdererStatusError) Error() string {
+	return fmt.Sprintf("unexpected status: %s. message: %v", e.Status.String(), e.Info)
+}
@@ -49,11 +49,13 @@ type Peer interface {
 	Close() error
 }
 
-// PeerEndorseError describes peer endorse error
-// TODO currently not working cause peer embeds error in string
+// PeerEndorseError describes peer endorse error, i.e. chaincode returned error status.
+// MspID and PeerURI are set by peer pool
 type PeerEndorseError struct {
 	Status  int32
 	Message string
+	MspID   string
+	PeerURI string
 }
 
 func (e PeerEndorseError) Error() string {
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 
@@ -90,6 +91,24 @@ type TxSubscription interface {
 	Close() error
 }
 
+// CommitNotifier resolves transactions validation codes from one long-lived block stream per channel,
+// instead of opening deliver stream for each transaction
+type CommitNotifier interface {
+	// WaitTx waits for transaction commit, error is returned if transaction is invalid
+	WaitTx(ctx context.Context, channelName string, txID string) (peer.TxValidationCode, error)
+	// Register registers transaction waiter before transaction is sent to orderer, so commit isn't missed,
+	// returned func waits for transaction commit
+	Register(ctx context.Context, channelName string, txID string) (TxCommitWait, error)
+}
+
+// TxCommitWait waits for registered transaction commit, error is returned if transaction is invalid
+type TxCommitWait func(ctx context.Context) (peer.TxValidationCode, error)
+
+// CommitNotifiers returns commit notifier, which receives blocks from MSP peers
+type CommitNotifiers interface {
+	CommitNotifier(mspID string) (CommitNotifier, error)
+}
+
 type BlockSubscription interface {
 	Blocks() <-chan *common.Block
 	// DEPRECATED: will migrate to just once Err() <- chan error
@@ -134,6 +153,29 @@ type InvalidTxError struct {
 	Code peer.TxValidationCode
 }
 
+// ErrMVCCConflict matches InvalidTxError with MVCC or phantom read conflict validation code
+var ErrMVCCConflict = errors.New(`mvcc conflict`)
+
 func (e InvalidTxError) Error() string {
 	return fmt.Sprintf("invalid tx: %s with validation code: %s", e.TxId, e.Code.String())
 }
+
+// Is reports whether tx is invalidated due to MVCC conflict, if target is ErrMVCCConflict
+func (e InvalidTxError) Is(target error) bool {
+	return target == ErrMVCCConflict &&
+		(e.Code == peer.TxValidationCode_MVCC_READ_CONFLICT || e.Code == peer.TxValidationCode_PHANTOM_READ_CONFLICT)
+}
+
+// TxCommitTimeoutError - transaction is sent to orderer, but its commit isn't received before context is done
+type TxCommitTimeoutError struct {
+	TxId string
+	Err  error
+}
+
+func (e TxCommitTimeoutError) Error() string {
+	return fmt.Sprintf("wait for tx: %s commit: %s", e.TxId, e.Err)
+}
+
+func (e TxCommitTimeoutError) Unwrap() error {
+	return e.Err
+}
@@ -19,9 +19,15 @@ type PeerPool interface {
 	GetMSPPeers(mspID string) []Peer
 	FirstReadyPeer(mspID string) (Peer, error)
 	Add(mspId string, peer Peer, strategy PeerPoolCheckStrategy) error
+	// Remove stops peer checks, removes peer with specified uri from pool and closes its connection
+	Remove(mspId string, uri string) error
+	// Replace swaps pool peer with the same uri to the new one (i.e. with new TLS settings) and closes old peer connection.
+	// If there is no such peer, new peer is added to pool
+	Replace(mspId string, peer Peer, strategy PeerPoolCheckStrategy) error
 	EndorseOnMSP(ctx context.Context, mspId string, proposal *peer.SignedProposal) (*peer.ProposalResponse, error)
 	EndorseOnMSPs(ctx context.Context, endorsingMspIDs []string, proposal *peer.SignedProposal) ([]*peer.ProposalResponse, error)
 	DeliverClient(mspId string, identity msp.SigningIdentity) (DeliverClient, error)
+	// Close stops all peer checks and closes peer connections
 	Close() error
 }
 
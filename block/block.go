@@ -165,6 +165,24 @@ func ParseBTFOrderersIdentities(block *common.Block, configBlock *common.Block)
 	return ordererSignatures, nil
 }
 
+// ParseConfigBlock returns channel config of config block
+func ParseConfigBlock(configBlock *common.Block) (*common.Config, error) {
+	if configBlock == nil {
+		return nil, ErrNilConfigBlock
+	}
+
+	if len(configBlock.GetData().GetData()) == 0 {
+		return nil, fmt.Errorf("config block has no data")
+	}
+
+	configEnvelope, err := createConfigEnvelope(configBlock.Data.Data[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return configEnvelope.Config, nil
+}
+
 // createConfigEnvelope creates configuration envelope proto
 func createConfigEnvelope(data []byte) (*common.ConfigEnvelope, error) {
 	envelope := &common.Envelope{}
@@ -13,12 +13,24 @@ import (
 	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric/common/channelconfig"
 	"google.golang.org/protobuf/encoding/protojson"
+
+	bft "github.com/s7techlab/hlf-sdk-go/block/smartbft"
 )
 
 var (
 	ErrUnknownFabricVersion = errors.New(`unknown fabric version`)
 )
 
+// OrderersKey - orderer group value key of BFT consenters, available since Fabric v3
+const OrderersKey = "Orderers"
+
+const (
+	// ConsensusTypeBFT - consensus type of Fabric v3 BFT orderer
+	ConsensusTypeBFT = "BFT"
+	// ConsensusTypeSmartBFT - consensus type of SmartBFT orderer, consenters are set in consensus type metadata
+	ConsensusTypeSmartBFT = "smartbft"
+)
+
 type FabricVersion string
 
 const (
@@ -220,6 +232,73 @@ func ParseOrdererEndpoints(b []byte) ([]string, error) {
 	return oa.Addresses, nil
 }
 
+// ParseBFTConsenters returns consenters of BFT orderer from channel config, nil if channel orderer isn't BFT
+func ParseBFTConsenters(cfg common.Config) ([]*common.Consenter, error) {
+	ordererGroup, exists := cfg.ChannelGroup.Groups[channelconfig.OrdererGroupKey]
+	if !exists {
+		return nil, fmt.Errorf("%v type group doesn't exists", channelconfig.OrdererGroupKey)
+	}
+
+	orderers, exists := ordererGroup.Values[OrderersKey]
+	if !exists {
+		return nil, nil
+	}
+
+	return ParseBFTConsentersFromBytes(orderers.Value)
+}
+
+// ParseOrdererConsenters returns consenters of BFT orderer from Orderers value (Fabric v3) or, if it isn't set,
+// from SmartBFT consensus type metadata. Nil is returned if channel orderer isn't BFT
+func ParseOrdererConsenters(cfg common.Config) ([]*common.Consenter, error) {
+	consenters, err := ParseBFTConsenters(cfg)
+	if err != nil || len(consenters) > 0 {
+		return consenters, err
+	}
+
+	consensusType, err := ParseOrdererConsensusType(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if consensusType.Type != ConsensusTypeSmartBFT {
+		return nil, nil
+	}
+
+	return ParseSmartBFTConsentersFromBytes(consensusType.Metadata)
+}
+
+// ParseSmartBFTConsentersFromBytes returns consenters from SmartBFT consensus type metadata
+func ParseSmartBFTConsentersFromBytes(b []byte) ([]*common.Consenter, error) {
+	configMetadata := &bft.ConfigMetadata{}
+	if err := proto.Unmarshal(b, configMetadata); err != nil {
+		return nil, fmt.Errorf("unmarshal SmartBFT config metadata: %w", err)
+	}
+
+	consenters := make([]*common.Consenter, len(configMetadata.Consenters))
+	for i, c := range configMetadata.Consenters {
+		consenters[i] = &common.Consenter{
+			Id:            uint32(c.ConsenterId),
+			Host:          c.Host,
+			Port:          c.Port,
+			MspId:         c.MspId,
+			Identity:      c.Identity,
+			ClientTlsCert: c.ClientTlsCert,
+			ServerTlsCert: c.ServerTlsCert,
+		}
+	}
+
+	return consenters, nil
+}
+
+func ParseBFTConsentersFromBytes(b []byte) ([]*common.Consenter, error) {
+	orderers := &common.Orderers{}
+	if err := proto.Unmarshal(b, orderers); err != nil {
+		return nil, fmt.Errorf("unmarshal Orderers: %w", err)
+	}
+
+	return orderers.ConsenterMapping, nil
+}
+
 func ParseAnchorPeers(mspConfigGroup *common.ConfigGroup) ([]*peer.AnchorPeer, error) {
 	if cv, ok := mspConfigGroup.Values[channelconfig.AnchorPeersKey]; ok {
 		return ParseAnchorPeersFromBytes(cv.Value)
@@ -3,6 +3,7 @@ package chaincode
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/hyperledger/fabric/msp"
 
@@ -14,10 +15,62 @@ type Core struct {
 	name          string
 	channelName   string
 	endorsingMSPs []string
+	layouts       []api.EndorsementLayout
+	endorsementMx sync.RWMutex
 	peerPool      api.PeerPool
 	orderer       api.Orderer
 
 	identity msp.SigningIdentity
+
+	commitNotifiers api.CommitNotifiers
+	// endorserVerifier - provider of endorsers signatures verifier, signatures are not verified if nil
+	endorserVerifier func(ctx context.Context) (EndorserVerifier, error)
+
+	onEndorsementPolicyFailure func(ctx context.Context)
+
+	// queryCache - if set, query responses are cached until new channel block
+	queryCache *QueryCache
+}
+
+// CoreOpt describes option which will be applied to chaincode Core
+type CoreOpt func(c *Core)
+
+// WithEndorsementLayouts sets combinations of MSPs satisfying chaincode endorsement policy,
+// invoke will be endorsed on one of them instead of all endorsing MSPs
+func WithEndorsementLayouts(layouts []api.EndorsementLayout) CoreOpt {
+	return func(c *Core) {
+		c.layouts = layouts
+	}
+}
+
+// WithEndorsementPolicyFailureHandler sets handler, called when invoke fails due to endorsement policy,
+// i.e. for refreshing chaincode endorsers
+func WithEndorsementPolicyFailureHandler(handler func(ctx context.Context)) CoreOpt {
+	return func(c *Core) {
+		c.onEndorsementPolicyFailure = handler
+	}
+}
+
+// WithCommitNotifiers sets shared commit notifiers, used by tx waiters instead of subscription per transaction
+func WithCommitNotifiers(notifiers api.CommitNotifiers) CoreOpt {
+	return func(c *Core) {
+		c.commitNotifiers = notifiers
+	}
+}
+
+// WithEndorserVerifier sets provider of verifier, used for checking endorsers signatures before sending
+// transaction to orderer. Provider can return nil verifier, then signatures are not verified
+func WithEndorserVerifier(provider func(ctx context.Context) (EndorserVerifier, error)) CoreOpt {
+	return func(c *Core) {
+		c.endorserVerifier = provider
+	}
+}
+
+// WithQueryCache sets cache of query responses, invalidated on new channel blocks
+func WithQueryCache(cache *QueryCache) CoreOpt {
+	return func(c *Core) {
+		c.queryCache = cache
+	}
 }
 
 func NewCore(
@@ -28,8 +81,9 @@ func NewCore(
 	peerPool api.PeerPool,
 	orderer api.Orderer,
 	identity msp.SigningIdentity,
+	opts ...CoreOpt,
 ) *Core {
-	return &Core{
+	c := &Core{
 		mspId:         mspId,
 		name:          ccName,
 		channelName:   channelName,
@@ -38,13 +92,43 @@ func NewCore(
 		orderer:       orderer,
 		identity:      identity,
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// handleEndorsementPolicyFailure calls endorsement policy failure handler, if error is caused by endorsement policy
+func (c *Core) handleEndorsementPolicyFailure(ctx context.Context, err error) {
+	if err != nil && c.onEndorsementPolicyFailure != nil && IsEndorsementPolicyFailure(err) {
+		c.onEndorsementPolicyFailure(ctx)
+	}
+}
+
+// SetEndorsement updates chaincode endorsing MSPs and endorsement layouts, i.e. after discovery refresh
+func (c *Core) SetEndorsement(endorsingMSPs []string, layouts []api.EndorsementLayout) {
+	c.endorsementMx.Lock()
+	defer c.endorsementMx.Unlock()
+
+	c.endorsingMSPs = endorsingMSPs
+	c.layouts = layouts
+}
+
+func (c *Core) endorsement() ([]string, []api.EndorsementLayout) {
+	c.endorsementMx.RLock()
+	defer c.endorsementMx.RUnlock()
+
+	return c.endorsingMSPs, c.layouts
 }
 
 func (c *Core) GetPeers() []api.Peer {
 	peers := make([]api.Peer, 0)
+	endorsingMSPs, _ := c.endorsement()
 
 	peersMap := c.peerPool.GetPeers()
-	for _, endorsingMSP := range c.endorsingMSPs {
+	for _, endorsingMSP := range endorsingMSPs {
 		if ps, ok := peersMap[endorsingMSP]; ok {
 			peers = append(peers, ps...)
 		}
@@ -0,0 +1,132 @@
+package chaincode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	fabricPeer "github.com/hyperledger/fabric-protos-go/peer"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	clienterrors "github.com/s7techlab/hlf-sdk-go/client/errors"
+	"github.com/s7techlab/hlf-sdk-go/client/policy"
+)
+
+var (
+	ErrEndorsementLayoutsRequired = errors.New(`endorsement layouts required`)
+	ErrEndorsementPolicyFailure   = errors.New(`no endorsement layout can be satisfied`)
+)
+
+// IsEndorsementPolicyFailure reports whether error is caused by endorsement policy, which can't be satisfied
+// by endorsing MSPs or by endorsements, sent to orderer
+func IsEndorsementPolicyFailure(err error) bool {
+	if errors.Is(err, ErrEndorsementPolicyFailure) {
+		return true
+	}
+
+	var invalidTxErr api.InvalidTxError
+	return errors.As(err, &invalidTxErr) && invalidTxErr.Code == fabricPeer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE
+}
+
+// WithEndorsementPolicy - add option for endorsing proposal according to signature policy
+// instead of on all endorsing MSPs
+func WithEndorsementPolicy(env *common.SignaturePolicyEnvelope) api.DoOption {
+	return func(cfg *api.DoOptions) error {
+		layouts, err := policy.Layouts(env)
+		if err != nil {
+			return fmt.Errorf(`endorsement layouts: %w`, err)
+		}
+
+		cfg.EndorsementLayouts = layouts
+		return nil
+	}
+}
+
+type mspEndorseResponse struct {
+	mspID    string
+	response *fabricPeer.ProposalResponse
+	err      error
+}
+
+// EndorseOnLayouts endorses proposal on first layout (the smallest ones go first) which MSPs have ready peers.
+// If endorsement on some MSP fails, next layout without failed MSPs is tried,
+// endorsements already received are reused. Returns proposal responses and MSPs of chosen layout
+func EndorseOnLayouts(
+	ctx context.Context,
+	pool api.PeerPool,
+	layouts []api.EndorsementLayout,
+	proposal *fabricPeer.SignedProposal,
+) ([]*fabricPeer.ProposalResponse, []string, error) {
+	if len(layouts) == 0 {
+		return nil, nil, ErrEndorsementLayoutsRequired
+	}
+
+	var (
+		responses = make(map[string]*fabricPeer.ProposalResponse)
+		failed    = make(map[string]error)
+	)
+
+layouts:
+	for _, layout := range policy.SortLayouts(layouts) {
+		var toEndorse []string
+
+		for _, mspID := range layout {
+			if _, ok := failed[mspID]; ok {
+				continue layouts
+			}
+
+			if _, ok := responses[mspID]; ok {
+				continue
+			}
+
+			if _, err := pool.FirstReadyPeer(mspID); err != nil {
+				failed[mspID] = err
+				continue layouts
+			}
+
+			toEndorse = append(toEndorse, mspID)
+		}
+
+		respChan := make(chan mspEndorseResponse, len(toEndorse))
+		for _, mspID := range toEndorse {
+			go func(mspID string) {
+				resp, err := pool.EndorseOnMSP(ctx, mspID, proposal)
+				respChan <- mspEndorseResponse{mspID: mspID, response: resp, err: err}
+			}(mspID)
+		}
+
+		layoutFailed := false
+		for range toEndorse {
+			resp := <-respChan
+			if resp.err != nil {
+				failed[resp.mspID] = resp.err
+				layoutFailed = true
+				continue
+			}
+			responses[resp.mspID] = resp.response
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		if layoutFailed {
+			continue
+		}
+
+		peerResponses := make([]*fabricPeer.ProposalResponse, 0, len(layout))
+		for _, mspID := range layout {
+			peerResponses = append(peerResponses, responses[mspID])
+		}
+
+		return peerResponses, layout, nil
+	}
+
+	mErr := new(clienterrors.MultiError)
+	for mspID, err := range failed {
+		mErr.Add(fmt.Errorf(`msp_id=%s: %w`, mspID, err))
+	}
+
+	return nil, nil, fmt.Errorf(`%w: %s`, ErrEndorsementPolicyFailure, mErr)
+}
@@ -0,0 +1,100 @@
+package chaincode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	fabricPeer "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+var (
+	ErrEndorsementStatus          = errors.New(`endorsement response status is not successful`)
+	ErrEndorsementPayloadMismatch = errors.New(`endorsement response payload differs`)
+	ErrEndorsementMissing         = errors.New(`endorsement is missing`)
+	ErrEndorserSignature          = errors.New(`endorser signature is invalid`)
+)
+
+const (
+	// endorsement response statuses, 4xx and 5xx statuses are errors
+	minSuccessStatus = 200
+	maxSuccessStatus = 399
+)
+
+// EndorsementError - endorsement of MSP is inconsistent with others or can't be verified
+type EndorsementError struct {
+	MspID string
+	Err   error
+}
+
+func (e EndorsementError) Error() string {
+	return fmt.Sprintf(`endorsement of %s: %s`, e.MspID, e.Err)
+}
+
+func (e EndorsementError) Unwrap() error {
+	return e.Err
+}
+
+// EndorserVerifier verifies endorser identity and signature of endorsement
+type EndorserVerifier interface {
+	Verify(endorsement *fabricPeer.Endorsement, payload []byte) error
+}
+
+// CheckEndorsements verifies that proposal responses are successful, have the same payload
+// and, if verifier is set, are signed by valid endorsers. Payload, returned by the most endorsers, is reference one
+func CheckEndorsements(responses []*fabricPeer.ProposalResponse, verifier EndorserVerifier) error {
+	var (
+		reference      []byte
+		referenceCount int
+	)
+
+	for _, r := range responses {
+		count := 0
+		for _, other := range responses {
+			if bytes.Equal(r.GetPayload(), other.GetPayload()) {
+				count++
+			}
+		}
+
+		if count > referenceCount {
+			reference, referenceCount = r.GetPayload(), count
+		}
+	}
+
+	for _, r := range responses {
+		mspID := endorserMSPID(r.GetEndorsement())
+
+		if status := r.GetResponse().GetStatus(); status < minSuccessStatus || status > maxSuccessStatus {
+			return EndorsementError{MspID: mspID,
+				Err: fmt.Errorf(`%w: status=%d, message=%s`, ErrEndorsementStatus, status, r.GetResponse().GetMessage())}
+		}
+
+		if !bytes.Equal(r.GetPayload(), reference) {
+			return EndorsementError{MspID: mspID, Err: ErrEndorsementPayloadMismatch}
+		}
+
+		if r.GetEndorsement() == nil {
+			return EndorsementError{MspID: mspID, Err: ErrEndorsementMissing}
+		}
+
+		if verifier != nil {
+			if err := verifier.Verify(r.GetEndorsement(), r.GetPayload()); err != nil {
+				return EndorsementError{MspID: mspID, Err: err}
+			}
+		}
+	}
+
+	return nil
+}
+
+// endorserMSPID returns MSP identifier of endorser or empty string if endorser can't be parsed
+func endorserMSPID(endorsement *fabricPeer.Endorsement) string {
+	identity := new(msp.SerializedIdentity)
+	if err := proto.Unmarshal(endorsement.GetEndorser(), identity); err != nil {
+		return ``
+	}
+
+	return identity.Mspid
+}
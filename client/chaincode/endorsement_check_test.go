@@ -0,0 +1,142 @@
+package chaincode_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/s7techlab/hlf-sdk-go/client/chaincode"
+	"github.com/s7techlab/hlf-sdk-go/crypto"
+)
+
+type testMSP struct {
+	mspID   string
+	caCert  *x509.Certificate
+	caKey   *ecdsa.PrivateKey
+	rootPEM []byte
+}
+
+func newTestMSP(t *testing.T, mspID string) *testMSP {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: `ca.` + mspID},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testMSP{mspID: mspID, caCert: cert, caKey: key,
+		rootPEM: pem.EncodeToMemory(&pem.Block{Type: `CERTIFICATE`, Bytes: der})}
+}
+
+// endorse returns proposal response, endorsed by new peer identity of MSP
+func (m *testMSP) endorse(t *testing.T, payload []byte) *peer.ProposalResponse {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: `peer.` + m.mspID},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}, m.caCert, &key.PublicKey, m.caKey)
+	require.NoError(t, err)
+
+	endorser, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   m.mspID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: `CERTIFICATE`, Bytes: der}),
+	})
+	require.NoError(t, err)
+
+	signature, err := crypto.DefaultSuite.Sign(append(append([]byte{}, payload...), endorser...), key)
+	require.NoError(t, err)
+
+	return &peer.ProposalResponse{
+		Response:    &peer.Response{Status: 200},
+		Payload:     payload,
+		Endorsement: &peer.Endorsement{Endorser: endorser, Signature: signature},
+	}
+}
+
+func TestCheckEndorsements(t *testing.T) {
+	org1, org2, org3 := newTestMSP(t, `Org1MSP`), newTestMSP(t, `Org2MSP`), newTestMSP(t, `Org3MSP`)
+
+	verifier, err := chaincode.NewMSPEndorserVerifier([]*msp.FabricMSPConfig{
+		{Name: org1.mspID, RootCerts: [][]byte{org1.rootPEM}},
+		{Name: org2.mspID, RootCerts: [][]byte{org2.rootPEM}},
+	}, crypto.DefaultSuite)
+	require.NoError(t, err)
+
+	payload := []byte(`payload`)
+
+	t.Run(`consistent`, func(t *testing.T) {
+		assert.NoError(t, chaincode.CheckEndorsements([]*peer.ProposalResponse{
+			org1.endorse(t, payload), org2.endorse(t, payload)}, verifier))
+	})
+
+	t.Run(`payload mismatch`, func(t *testing.T) {
+		err := chaincode.CheckEndorsements([]*peer.ProposalResponse{
+			org1.endorse(t, payload), org2.endorse(t, []byte(`other`)), org1.endorse(t, payload)}, verifier)
+
+		var endorsementErr chaincode.EndorsementError
+		require.ErrorAs(t, err, &endorsementErr)
+		assert.Equal(t, `Org2MSP`, endorsementErr.MspID)
+		assert.ErrorIs(t, err, chaincode.ErrEndorsementPayloadMismatch)
+	})
+
+	t.Run(`error status`, func(t *testing.T) {
+		failed := org2.endorse(t, payload)
+		failed.Response = &peer.Response{Status: 500, Message: `chaincode error`}
+
+		err := chaincode.CheckEndorsements([]*peer.ProposalResponse{org1.endorse(t, payload), failed}, verifier)
+		assert.ErrorIs(t, err, chaincode.ErrEndorsementStatus)
+	})
+
+	t.Run(`unknown MSP`, func(t *testing.T) {
+		err := chaincode.CheckEndorsements([]*peer.ProposalResponse{org3.endorse(t, payload)}, verifier)
+		assert.ErrorIs(t, err, chaincode.ErrEndorserMSPUnknown)
+	})
+
+	t.Run(`invalid signature`, func(t *testing.T) {
+		forged := org1.endorse(t, payload)
+		forged.Endorsement.Signature = org1.endorse(t, payload).Endorsement.Signature
+
+		err := chaincode.CheckEndorsements([]*peer.ProposalResponse{forged}, verifier)
+		assert.ErrorIs(t, err, chaincode.ErrEndorserSignature)
+	})
+
+	t.Run(`certificate issued by other MSP`, func(t *testing.T) {
+		response := org3.endorse(t, payload)
+		identity := new(msp.SerializedIdentity)
+		require.NoError(t, proto.Unmarshal(response.Endorsement.Endorser, identity))
+		identity.Mspid = org1.mspID
+		response.Endorsement.Endorser, err = proto.Marshal(identity)
+		require.NoError(t, err)
+
+		err := chaincode.CheckEndorsements([]*peer.ProposalResponse{response}, verifier)
+		assert.ErrorIs(t, err, chaincode.ErrEndorserCertInvalid)
+	})
+}
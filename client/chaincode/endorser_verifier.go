@@ -0,0 +1,121 @@
+package chaincode
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	fabricPeer "github.com/hyperledger/fabric-protos-go/peer"
+
+	"github.com/s7techlab/hlf-sdk-go/block"
+	"github.com/s7techlab/hlf-sdk-go/crypto"
+)
+
+var (
+	ErrEndorserMSPUnknown     = errors.New(`endorser MSP is not in channel config`)
+	ErrEndorserCertInvalid    = errors.New(`endorser certificate is not issued by MSP`)
+	ErrEndorserIdentityFormat = errors.New(`endorser identity can't be parsed`)
+)
+
+var _ EndorserVerifier = (*MSPEndorserVerifier)(nil)
+
+// MSPEndorserVerifier verifies that endorser certificate is issued by MSP from channel config
+// and endorsement is signed by endorser
+type MSPEndorserVerifier struct {
+	msps  map[string]*mspCertPool
+	suite crypto.Suite
+}
+
+type mspCertPool struct {
+	roots         *x509.CertPool
+	intermediates *x509.CertPool
+}
+
+// NewMSPEndorserVerifier creates verifier from MSPs config
+func NewMSPEndorserVerifier(msps []*msp.FabricMSPConfig, suite crypto.Suite) (*MSPEndorserVerifier, error) {
+	v := &MSPEndorserVerifier{
+		msps:  make(map[string]*mspCertPool),
+		suite: suite,
+	}
+
+	for _, mspConfig := range msps {
+		pool := &mspCertPool{roots: x509.NewCertPool(), intermediates: x509.NewCertPool()}
+
+		for _, cert := range mspConfig.RootCerts {
+			if !pool.roots.AppendCertsFromPEM(cert) {
+				return nil, fmt.Errorf(`msp=%s: invalid root certificate`, mspConfig.Name)
+			}
+		}
+
+		for _, cert := range mspConfig.IntermediateCerts {
+			if !pool.intermediates.AppendCertsFromPEM(cert) {
+				return nil, fmt.Errorf(`msp=%s: invalid intermediate certificate`, mspConfig.Name)
+			}
+		}
+
+		v.msps[mspConfig.Name] = pool
+	}
+
+	return v, nil
+}
+
+// NewMSPEndorserVerifierFromConfig creates verifier from application MSPs of channel config
+func NewMSPEndorserVerifierFromConfig(channelConfig *common.Config, suite crypto.Suite) (*MSPEndorserVerifier, error) {
+	appConfig, err := block.ParseApplicationConfig(*channelConfig)
+	if err != nil {
+		return nil, fmt.Errorf(`parse application config: %w`, err)
+	}
+
+	var msps []*msp.FabricMSPConfig
+	for _, app := range appConfig {
+		msps = append(msps, app.GetMsp().GetConfig())
+	}
+
+	return NewMSPEndorserVerifier(msps, suite)
+}
+
+// Verify - implementation of EndorserVerifier interface
+func (v *MSPEndorserVerifier) Verify(endorsement *fabricPeer.Endorsement, payload []byte) error {
+	identity := new(msp.SerializedIdentity)
+	if err := proto.Unmarshal(endorsement.Endorser, identity); err != nil {
+		return fmt.Errorf(`%w: %s`, ErrEndorserIdentityFormat, err)
+	}
+
+	pool, ok := v.msps[identity.Mspid]
+	if !ok {
+		return fmt.Errorf(`%w: %s`, ErrEndorserMSPUnknown, identity.Mspid)
+	}
+
+	certBlock, _ := pem.Decode(identity.IdBytes)
+	if certBlock == nil {
+		return fmt.Errorf(`%w: certificate is not PEM encoded`, ErrEndorserIdentityFormat)
+	}
+
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return fmt.Errorf(`%w: %s`, ErrEndorserIdentityFormat, err)
+	}
+
+	// like fabric MSP, certificate expiration is not checked here
+	if _, err = cert.Verify(x509.VerifyOptions{
+		Roots:         pool.roots,
+		Intermediates: pool.intermediates,
+		CurrentTime:   cert.NotBefore.Add(time.Second),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return fmt.Errorf(`%w: %s`, ErrEndorserCertInvalid, err)
+	}
+
+	// endorsement is signed over response payload concatenated with endorser identity
+	if err = v.suite.Verify(cert.PublicKey, append(append([]byte{}, payload...), endorsement.Endorser...),
+		endorsement.Signature); err != nil {
+		return fmt.Errorf(`%w: %s`, ErrEndorserSignature, err)
+	}
+
+	return nil
+}
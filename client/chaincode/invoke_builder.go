@@ -5,6 +5,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"math/rand"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-protos-go/common"
@@ -75,33 +78,166 @@ func (b *invokeBuilder) ArgString(args ...string) api.ChaincodeInvokeBuilder {
 }
 
 func (b *invokeBuilder) Do(ctx context.Context, options ...api.DoOption) (*fabricPeer.Response, string, error) {
-	err := b.err.Err()
+	resp, txID, err := b.do(ctx, options...)
+	b.ccCore.handleEndorsementPolicyFailure(ctx, err)
+
+	return resp, txID, err
+}
+
+func (b *invokeBuilder) do(ctx context.Context, options ...api.DoOption) (*fabricPeer.Response, string, error) {
+	doOpts, err := b.options(options...)
 	if err != nil {
 		return nil, ``, err
 	}
 
+	if doOpts.MVCCRetry == nil {
+		return b.submit(ctx, doOpts)
+	}
+
+	return b.submitWithMVCCRetry(ctx, doOpts, doOpts.MVCCRetry)
+}
+
+// options returns default options with applied builder and call options
+func (b *invokeBuilder) options(options ...api.DoOption) (*api.DoOptions, error) {
+	err := b.err.Err()
+	if err != nil {
+		return nil, err
+	}
+
 	if b.ccCore.orderer == nil {
-		return nil, ``, ErrOrdererNotDefined
+		return nil, ErrOrdererNotDefined
 	}
 
+	endorsingMSPs, layouts := b.ccCore.endorsement()
+
 	// set default options
 	doOpts := &api.DoOptions{
-		Identity:        b.ccCore.identity,
-		Pool:            b.ccCore.peerPool,
-		EndorsingMspIDs: b.ccCore.endorsingMSPs,
+		Identity:           b.ccCore.identity,
+		Pool:               b.ccCore.peerPool,
+		EndorsingMspIDs:    endorsingMSPs,
+		EndorsementLayouts: layouts,
+		CommitNotifiers:    b.ccCore.commitNotifiers,
 	}
 	doOpts.TxWaiter, err = txwaiter.Self(doOpts)
 	if err != nil {
-		return nil, "", nil
+		return nil, err
 	}
 
 	// apply options
 	for _, applyOpt := range append(b.doOptions, options...) {
 		if err = applyOpt(doOpts); err != nil {
-			return nil, ``, fmt.Errorf("apply options: %s", err)
+			return nil, fmt.Errorf("apply options: %s", err)
+		}
+	}
+
+	return doOpts, nil
+}
+
+// submitWithMVCCRetry resubmits transaction with new tx id, while it is invalidated due to MVCC conflict
+func (b *invokeBuilder) submitWithMVCCRetry(ctx context.Context, doOpts *api.DoOptions, retry *api.MVCCRetry) (
+	*fabricPeer.Response, string, error) {
+	for attempt := 1; ; attempt++ {
+		resp, txID, err := b.submit(ctx, doOpts)
+
+		result := api.MVCCRetryAttempt{
+			Attempt: attempt,
+			TxID:    txID,
+			Err:     err,
+			Retry:   errors.Is(err, api.ErrMVCCConflict) && attempt < retry.MaxAttempts,
+		}
+		if result.Retry {
+			result.Delay = mvccRetryDelay(retry, attempt)
 		}
+
+		if retry.OnAttempt != nil {
+			retry.OnAttempt(result)
+		}
+
+		if !result.Retry {
+			return resp, txID, err
+		}
+
+		select {
+		case <-time.After(result.Delay):
+		case <-ctx.Done():
+			return nil, txID, err
+		}
+	}
+}
+
+// maxMVCCRetryDelay - limit of doubled delay, if max backoff isn't set. Leaves room for jitter without overflow
+const maxMVCCRetryDelay = time.Duration(math.MaxInt64 / 4)
+
+// mvccRetryDelay returns exponential backoff delay with jitter before next attempt
+func mvccRetryDelay(retry *api.MVCCRetry, attempt int) time.Duration {
+	maxDelay := retry.MaxBackoff
+	if maxDelay <= 0 || maxDelay > maxMVCCRetryDelay {
+		maxDelay = maxMVCCRetryDelay
+	}
+
+	delay := retry.Backoff
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	if retry.Jitter > 0 {
+		delay += time.Duration(float64(delay) * retry.Jitter * (2*rand.Float64() - 1))
+	}
+
+	if delay < 0 {
+		delay = 0
 	}
 
+	return delay
+}
+
+// submit endorses proposal with new tx id, sends transaction to orderer and waits for its commit
+func (b *invokeBuilder) submit(ctx context.Context, doOpts *api.DoOptions) (*fabricPeer.Response, string, error) {
+	endorsed, err := b.endorse(ctx, doOpts)
+	if err != nil {
+		var txID string
+		if endorsed != nil {
+			txID = endorsed.txID
+		}
+		return nil, txID, err
+	}
+
+	commit, err := endorsed.submit(ctx)
+	if err != nil {
+		return nil, endorsed.txID, err
+	}
+
+	// endorsement policy failure is handled by Do
+	if _, err = commit.status(ctx); err != nil {
+		return nil, endorsed.txID, err
+	}
+
+	return endorsed.Result(), endorsed.txID, nil
+}
+
+// Endorse endorses proposal, transaction is sent to orderer on endorsed transaction Submit
+func (b *invokeBuilder) Endorse(ctx context.Context, options ...api.DoOption) (api.EndorsedTransaction, error) {
+	doOpts, err := b.options(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	endorsed, err := b.endorse(ctx, doOpts)
+	if err != nil {
+		b.ccCore.handleEndorsementPolicyFailure(ctx, err)
+		return nil, err
+	}
+
+	return endorsed, nil
+}
+
+// endorse endorses proposal with new tx id and checks endorsements,
+// endorsed transaction with tx id is returned on endorsement errors too
+func (b *invokeBuilder) endorse(ctx context.Context, doOpts *api.DoOptions) (*endorsedTx, error) {
 	proposal, txID, err := tx.Endorsement{
 		Channel:      b.ccCore.channelName,
 		Chaincode:    b.ccCore.name,
@@ -111,34 +247,64 @@ func (b *invokeBuilder) Do(ctx context.Context, options ...api.DoOption) (*fabri
 	}.SignedProposal()
 
 	if err != nil {
-		return nil, ``, fmt.Errorf("create proposal: %w", err)
+		return nil, fmt.Errorf("create proposal: %w", err)
 	}
 
-	peerResponses, err := b.ccCore.peerPool.EndorseOnMSPs(ctx, doOpts.EndorsingMspIDs, proposal)
+	endorsed := &endorsedTx{
+		ccCore: b.ccCore,
+		txID:   txID,
+	}
+
+	var peerResponses []*fabricPeer.ProposalResponse
+	endorsingMSPs := doOpts.EndorsingMspIDs
+
+	if len(doOpts.EndorsementLayouts) > 0 {
+		peerResponses, endorsingMSPs, err = EndorseOnLayouts(ctx, b.ccCore.peerPool, doOpts.EndorsementLayouts, proposal)
+	} else {
+		peerResponses, err = b.ccCore.peerPool.EndorseOnMSPs(ctx, endorsingMSPs, proposal)
+	}
 	if err != nil {
-		return nil, txID, fmt.Errorf("send proposal: %w", err)
+		return endorsed, fmt.Errorf("send proposal: %w", err)
 	}
 
-	if len(peerResponses) == 0 || len(peerResponses) != len(doOpts.EndorsingMspIDs) {
-		return nil, ``, fmt.Errorf(`endorsements received num=%d, required=%d: %w`,
-			len(peerResponses), len(doOpts.EndorsingMspIDs), ErrNotEnoughEndorsements)
+	if len(peerResponses) == 0 || len(peerResponses) != len(endorsingMSPs) {
+		return endorsed, fmt.Errorf(`endorsements received num=%d, required=%d: %w`,
+			len(peerResponses), len(endorsingMSPs), ErrNotEnoughEndorsements)
 	}
 
-	envelope, err := CreateEnvelope(proposal, peerResponses, doOpts.Identity)
-	if err != nil {
-		return nil, txID, fmt.Errorf("create signed transaction: %w", err)
+	if endorsed.txWaiter, err = txWaiter(doOpts, endorsingMSPs); err != nil {
+		return endorsed, fmt.Errorf("tx waiter: %w", err)
 	}
 
-	_, err = b.ccCore.orderer.Broadcast(ctx, envelope)
-	if err != nil {
-		return nil, txID, fmt.Errorf("broadcast transaction: %w", err)
+	// nondeterministic chaincode or faulty endorser are detected before ordering
+	var verifier EndorserVerifier
+	if b.ccCore.endorserVerifier != nil {
+		if verifier, err = b.ccCore.endorserVerifier(ctx); err != nil {
+			return endorsed, fmt.Errorf("endorser verifier: %w", err)
+		}
 	}
 
-	if err = doOpts.TxWaiter.Wait(ctx, b.ccCore.channelName, txID); err != nil {
-		return nil, txID, err
+	if err = CheckEndorsements(peerResponses, verifier); err != nil {
+		return endorsed, fmt.Errorf("check endorsements: %w", err)
+	}
+
+	if endorsed.envelope, err = CreateEnvelope(proposal, peerResponses, doOpts.Identity); err != nil {
+		return endorsed, fmt.Errorf("create signed transaction: %w", err)
+	}
+	endorsed.responses = peerResponses
+
+	return endorsed, nil
+}
+
+// txWaiter returns tx waiter from options or builds it for MSPs, which endorsed transaction
+func txWaiter(doOpts *api.DoOptions, endorsingMSPs []string) (api.TxWaiter, error) {
+	if doOpts.TxWaiterBuilder == nil {
+		return doOpts.TxWaiter, nil
 	}
 
-	return peerResponses[0].Response, txID, nil
+	endorsedOpts := *doOpts
+	endorsedOpts.EndorsingMspIDs = endorsingMSPs
+	return doOpts.TxWaiterBuilder(&endorsedOpts)
 }
 
 func CreateEnvelope(
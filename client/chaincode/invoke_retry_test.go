@@ -0,0 +1,171 @@
+package chaincode_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/client/chaincode"
+	"github.com/s7techlab/hlf-sdk-go/identity"
+)
+
+type endorsePoolMock struct {
+	api.PeerPool
+}
+
+func (p *endorsePoolMock) EndorseOnMSPs(_ context.Context, mspIDs []string, _ *peer.SignedProposal) ([]*peer.ProposalResponse, error) {
+	var responses []*peer.ProposalResponse
+	for range mspIDs {
+		responses = append(responses, &peer.ProposalResponse{
+			Response:    &peer.Response{Status: 200, Payload: []byte(`ok`)},
+			Payload:     simulatedPayload(),
+			Endorsement: &peer.Endorsement{},
+		})
+	}
+	return responses, nil
+}
+
+// simulatedPayload returns proposal response payload with write of key `k` in chaincode `cc`
+func simulatedPayload() []byte {
+	return protoutil.MarshalOrPanic(&peer.ProposalResponsePayload{
+		Extension: protoutil.MarshalOrPanic(&peer.ChaincodeAction{
+			Results: protoutil.MarshalOrPanic(&rwset.TxReadWriteSet{
+				NsRwset: []*rwset.NsReadWriteSet{{
+					Namespace: `cc`,
+					Rwset: protoutil.MarshalOrPanic(&kvrwset.KVRWSet{
+						Writes: []*kvrwset.KVWrite{{Key: `k`, Value: []byte(`v`)}},
+					}),
+				}},
+			}),
+		}),
+	})
+}
+
+type broadcastOrdererMock struct {
+	api.Orderer
+
+	broadcasts int
+}
+
+func (o *broadcastOrdererMock) Broadcast(context.Context, *common.Envelope) (*orderer.BroadcastResponse, error) {
+	o.broadcasts++
+	return &orderer.BroadcastResponse{Status: common.Status_SUCCESS}, nil
+}
+
+// txWaiterMock returns validation codes of consecutive transactions
+type txWaiterMock struct {
+	codes []peer.TxValidationCode
+	txIDs []string
+}
+
+func (w *txWaiterMock) Wait(_ context.Context, _ string, txID string) error {
+	code := w.codes[len(w.txIDs)]
+	w.txIDs = append(w.txIDs, txID)
+
+	if code != peer.TxValidationCode_VALID {
+		return api.InvalidTxError{TxId: txID, Code: code}
+	}
+	return nil
+}
+
+func newRetryTestCore(t *testing.T, ord api.Orderer) *chaincode.Core {
+	signer, err := identity.NewSigningFromMSPPath(`Org1MSP`, `testdata/msp`)
+	require.NoError(t, err)
+
+	return chaincode.NewCore(`Org1MSP`, `cc`, `channel`, []string{`Org1MSP`}, &endorsePoolMock{}, ord, signer)
+}
+
+func TestInvokeMVCCRetry(t *testing.T) {
+	ord := &broadcastOrdererMock{}
+	waiter := &txWaiterMock{codes: []peer.TxValidationCode{
+		peer.TxValidationCode_MVCC_READ_CONFLICT,
+		peer.TxValidationCode_PHANTOM_READ_CONFLICT,
+		peer.TxValidationCode_VALID,
+	}}
+
+	var attempts []api.MVCCRetryAttempt
+	resp, txID, err := newRetryTestCore(t, ord).Invoke(`fn`).Do(context.Background(),
+		chaincode.WithTxWaiter(func(*api.DoOptions) (api.TxWaiter, error) { return waiter, nil }),
+		api.WithMVCCRetry(api.MVCCRetry{
+			MaxAttempts: 3,
+			Backoff:     time.Millisecond,
+			Jitter:      0.5,
+			OnAttempt:   func(attempt api.MVCCRetryAttempt) { attempts = append(attempts, attempt) },
+		}))
+
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`ok`), resp.Payload)
+	assert.Equal(t, 3, ord.broadcasts)
+
+	// each attempt is submitted with new tx id
+	require.Len(t, waiter.txIDs, 3)
+	assert.NotEqual(t, waiter.txIDs[0], waiter.txIDs[1])
+	assert.Equal(t, waiter.txIDs[2], txID)
+
+	require.Len(t, attempts, 3)
+	assert.True(t, attempts[0].Retry)
+	assert.ErrorIs(t, attempts[1].Err, api.ErrMVCCConflict)
+	assert.False(t, attempts[2].Retry)
+	assert.NoError(t, attempts[2].Err)
+}
+
+func TestInvokeMVCCRetryExhausted(t *testing.T) {
+	ord := &broadcastOrdererMock{}
+	waiter := &txWaiterMock{codes: []peer.TxValidationCode{
+		peer.TxValidationCode_MVCC_READ_CONFLICT,
+		peer.TxValidationCode_MVCC_READ_CONFLICT,
+	}}
+
+	_, _, err := newRetryTestCore(t, ord).Invoke(`fn`).Do(context.Background(),
+		chaincode.WithTxWaiter(func(*api.DoOptions) (api.TxWaiter, error) { return waiter, nil }),
+		api.WithMVCCRetry(api.MVCCRetry{MaxAttempts: 2}))
+
+	assert.ErrorIs(t, err, api.ErrMVCCConflict)
+	assert.Equal(t, 2, ord.broadcasts)
+}
+
+func TestInvokeNotMVCCErrorIsNotRetried(t *testing.T) {
+	ord := &broadcastOrdererMock{}
+	waiter := &txWaiterMock{codes: []peer.TxValidationCode{peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE}}
+
+	_, _, err := newRetryTestCore(t, ord).Invoke(`fn`).Do(context.Background(),
+		chaincode.WithTxWaiter(func(*api.DoOptions) (api.TxWaiter, error) { return waiter, nil }),
+		api.WithMVCCRetry(api.MVCCRetry{MaxAttempts: 3}))
+
+	assert.ErrorAs(t, err, &api.InvalidTxError{})
+	assert.Equal(t, 1, ord.broadcasts)
+}
+
+// noEndorsementsPoolMock returns no endorsements
+type noEndorsementsPoolMock struct {
+	api.PeerPool
+}
+
+func (p *noEndorsementsPoolMock) EndorseOnMSPs(context.Context, []string, *peer.SignedProposal) ([]*peer.ProposalResponse, error) {
+	return nil, nil
+}
+
+func TestInvokeNotEnoughEndorsementsTxID(t *testing.T) {
+	signer, err := identity.NewSigningFromMSPPath(`Org1MSP`, `testdata/msp`)
+	require.NoError(t, err)
+
+	ord := &broadcastOrdererMock{}
+	core := chaincode.NewCore(`Org1MSP`, `cc`, `channel`, []string{`Org1MSP`}, &noEndorsementsPoolMock{}, ord, signer)
+
+	_, txID, err := core.Invoke(`fn`).Do(context.Background())
+	require.ErrorIs(t, err, chaincode.ErrNotEnoughEndorsements)
+
+	// tx id is returned to correlate failed invoke
+	assert.NotEmpty(t, txID)
+	assert.Equal(t, 0, ord.broadcasts)
+}
@@ -0,0 +1,163 @@
+package chaincode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	fabricPeer "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/protoutil"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/block"
+)
+
+var (
+	_ api.EndorsedTransaction = (*endorsedTx)(nil)
+	_ api.Commit              = (*txCommit)(nil)
+)
+
+// endorsedTx - signed transaction with endorsements, ready to be sent to orderer
+type endorsedTx struct {
+	ccCore    *Core
+	txID      string
+	responses []*fabricPeer.ProposalResponse
+	envelope  *common.Envelope
+	txWaiter  api.TxWaiter
+}
+
+func (t *endorsedTx) TxID() string {
+	return t.txID
+}
+
+func (t *endorsedTx) ProposalResponses() []*fabricPeer.ProposalResponse {
+	return t.responses
+}
+
+// Result returns chaincode response, endorsements are checked to be the same
+func (t *endorsedTx) Result() *fabricPeer.Response {
+	return t.responses[0].Response
+}
+
+func (t *endorsedTx) ReadWriteSet() (*block.TxReadWriteSet, error) {
+	responsePayload, err := protoutil.UnmarshalProposalResponsePayload(t.responses[0].Payload)
+	if err != nil {
+		return nil, fmt.Errorf(`unmarshal proposal response payload: %w`, err)
+	}
+
+	chaincodeAction, err := protoutil.UnmarshalChaincodeAction(responsePayload.Extension)
+	if err != nil {
+		return nil, fmt.Errorf(`unmarshal chaincode action: %w`, err)
+	}
+
+	return block.ParseTxReadWriteSet(chaincodeAction)
+}
+
+func (t *endorsedTx) Submit(ctx context.Context) (api.Commit, error) {
+	return t.submit(ctx)
+}
+
+func (t *endorsedTx) submit(ctx context.Context) (*txCommit, error) {
+	commit := &txCommit{
+		ccCore:   t.ccCore,
+		txID:     t.txID,
+		txWaiter: t.txWaiter,
+	}
+
+	// transaction is registered before broadcast, so commit isn't missed if status is requested after block delivery
+	if registerer, ok := t.txWaiter.(api.TxWaitRegisterer); ok {
+		wait, err := registerer.Register(ctx, t.ccCore.channelName, t.txID)
+		if err != nil {
+			return nil, fmt.Errorf("register transaction: %w", err)
+		}
+		commit.wait = wait
+	}
+
+	if _, err := t.ccCore.orderer.Broadcast(ctx, t.envelope); err != nil {
+		commit.release()
+		return nil, fmt.Errorf("broadcast transaction: %w", err)
+	}
+
+	return commit, nil
+}
+
+// txCommit waits for transaction commit with tx waiter, once received result is reused
+type txCommit struct {
+	ccCore   *Core
+	txID     string
+	txWaiter api.TxWaiter
+	// wait - waiter of transaction, registered before broadcast, used by first status request
+	wait api.TxWait
+
+	done bool
+	code fabricPeer.TxValidationCode
+	err  error
+	mu   sync.Mutex
+}
+
+func (c *txCommit) TxID() string {
+	return c.txID
+}
+
+func (c *txCommit) Status(ctx context.Context) (fabricPeer.TxValidationCode, error) {
+	code, err := c.status(ctx)
+	c.ccCore.handleEndorsementPolicyFailure(ctx, err)
+
+	return code, err
+}
+
+// release cancels registered waiter of transaction, which won't be committed
+func (c *txCommit) release() {
+	if c.wait == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = c.wait(ctx)
+}
+
+func (c *txCommit) status(ctx context.Context) (fabricPeer.TxValidationCode, error) {
+	c.mu.Lock()
+	if c.done {
+		c.mu.Unlock()
+		return c.code, c.err
+	}
+	// registered waiter is used once, concurrent and next status requests wait with tx waiter
+	wait := c.wait
+	c.wait = nil
+	c.mu.Unlock()
+
+	var err error
+	if wait != nil {
+		err = wait(ctx)
+	} else {
+		err = c.txWaiter.Wait(ctx, c.ccCore.channelName, c.txID)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		// commit status can be requested again
+		return -1, api.TxCommitTimeoutError{TxId: c.txID, Err: err}
+	}
+
+	code := fabricPeer.TxValidationCode_VALID
+	if err != nil {
+		code = -1
+
+		var invalidTxErr api.InvalidTxError
+		if errors.As(err, &invalidTxErr) {
+			code = invalidTxErr.Code
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.done {
+		c.done, c.code, c.err = true, code, err
+	}
+
+	return c.code, c.err
+}
@@ -0,0 +1,131 @@
+package chaincode_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/client/chaincode"
+	"github.com/s7techlab/hlf-sdk-go/identity"
+)
+
+func TestInvokeStaged(t *testing.T) {
+	ord := &broadcastOrdererMock{}
+	waiter := &txWaiterMock{codes: []peer.TxValidationCode{peer.TxValidationCode_MVCC_READ_CONFLICT}}
+
+	endorsed, err := newRetryTestCore(t, ord).Invoke(`fn`).Endorse(context.Background(),
+		chaincode.WithTxWaiter(func(*api.DoOptions) (api.TxWaiter, error) { return waiter, nil }))
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, endorsed.TxID())
+	assert.Len(t, endorsed.ProposalResponses(), 1)
+	assert.Equal(t, []byte(`ok`), endorsed.Result().Payload)
+
+	rwSet, err := endorsed.ReadWriteSet()
+	require.NoError(t, err)
+	require.Len(t, rwSet.NsRwset, 1)
+	assert.Equal(t, `cc`, rwSet.NsRwset[0].Namespace)
+	assert.Equal(t, `k`, rwSet.NsRwset[0].Rwset.Writes[0].Key)
+
+	// transaction isn't sent to orderer until submit
+	assert.Equal(t, 0, ord.broadcasts)
+
+	commit, err := endorsed.Submit(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, ord.broadcasts)
+	assert.Equal(t, endorsed.TxID(), commit.TxID())
+
+	code, err := commit.Status(context.Background())
+	assert.Equal(t, peer.TxValidationCode_MVCC_READ_CONFLICT, code)
+	assert.ErrorIs(t, err, api.ErrMVCCConflict)
+
+	// commit status is received once
+	code, _ = commit.Status(context.Background())
+	assert.Equal(t, peer.TxValidationCode_MVCC_READ_CONFLICT, code)
+	assert.Equal(t, []string{endorsed.TxID()}, waiter.txIDs)
+}
+
+// layoutsPoolMock - peer pool, MSPs of which are ready, if they are listed
+type layoutsPoolMock struct {
+	endorsePoolMock
+
+	ready map[string]bool
+}
+
+func (p *layoutsPoolMock) FirstReadyPeer(mspID string) (api.Peer, error) {
+	if !p.ready[mspID] {
+		return nil, errors.New(`no ready peers`)
+	}
+	return nil, nil
+}
+
+func (p *layoutsPoolMock) EndorseOnMSP(ctx context.Context, mspID string, proposal *peer.SignedProposal) (*peer.ProposalResponse, error) {
+	responses, err := p.EndorseOnMSPs(ctx, []string{mspID}, proposal)
+	if err != nil {
+		return nil, err
+	}
+	return responses[0], nil
+}
+
+func TestInvokeTxWaiterOfEndorsers(t *testing.T) {
+	signer, err := identity.NewSigningFromMSPPath(`Org1MSP`, `testdata/msp`)
+	require.NoError(t, err)
+
+	// Org1MSP has no ready peers, endorsement falls back to the second layout
+	pool := &layoutsPoolMock{ready: map[string]bool{`Org2MSP`: true, `Org3MSP`: true}}
+	core := chaincode.NewCore(`Org1MSP`, `cc`, `channel`, []string{`Org1MSP`, `Org2MSP`, `Org3MSP`},
+		pool, &broadcastOrdererMock{}, signer,
+		chaincode.WithEndorsementLayouts([]api.EndorsementLayout{{`Org1MSP`}, {`Org2MSP`, `Org3MSP`}}))
+
+	var waiterMSPs []string
+	_, err = core.Invoke(`fn`).Endorse(context.Background(),
+		chaincode.WithTxWaiter(func(cfg *api.DoOptions) (api.TxWaiter, error) {
+			waiterMSPs = cfg.EndorsingMspIDs
+			return &txWaiterMock{}, nil
+		}))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{`Org2MSP`, `Org3MSP`}, waiterMSPs)
+}
+
+// registeringWaiterMock - tx waiter, which registers transaction before broadcast
+type registeringWaiterMock struct {
+	txWaiterMock
+
+	ord *broadcastOrdererMock
+	// broadcastsOnRegister - number of broadcasts, made before transaction registration
+	broadcastsOnRegister []int
+}
+
+func (w *registeringWaiterMock) Register(_ context.Context, channel string, txID string) (api.TxWait, error) {
+	w.broadcastsOnRegister = append(w.broadcastsOnRegister, w.ord.broadcasts)
+	return func(ctx context.Context) error {
+		return w.txWaiterMock.Wait(ctx, channel, txID)
+	}, nil
+}
+
+func TestInvokeStagedRegistersTxBeforeBroadcast(t *testing.T) {
+	ord := &broadcastOrdererMock{}
+	waiter := &registeringWaiterMock{
+		txWaiterMock: txWaiterMock{codes: []peer.TxValidationCode{peer.TxValidationCode_VALID}},
+		ord:          ord,
+	}
+
+	endorsed, err := newRetryTestCore(t, ord).Invoke(`fn`).Endorse(context.Background(),
+		chaincode.WithTxWaiter(func(*api.DoOptions) (api.TxWaiter, error) { return waiter, nil }))
+	require.NoError(t, err)
+
+	commit, err := endorsed.Submit(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []int{0}, waiter.broadcastsOnRegister)
+
+	code, err := commit.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, peer.TxValidationCode_VALID, code)
+	assert.Equal(t, []string{endorsed.TxID()}, waiter.txIDs)
+}
@@ -20,6 +20,7 @@ type QueryBuilder struct {
 	identity      msp.SigningIdentity
 	peerPool      api.PeerPool
 	transientArgs api.TransArgs
+	cache         *QueryCache
 }
 
 func (q *QueryBuilder) WithIdentity(identity msp.SigningIdentity) api.ChaincodeQueryBuilder {
@@ -53,6 +54,26 @@ func (q *QueryBuilder) AsJSON(ctx context.Context, out interface{}) error {
 }
 
 func (q *QueryBuilder) AsProposalResponse(ctx context.Context) (*fabricPeer.ProposalResponse, error) {
+	if q.cache == nil || len(q.transientArgs) > 0 {
+		return q.endorse(ctx)
+	}
+
+	key := newQueryCacheKey(q.channel, q.chaincode, tx.FnArgs(q.fn, q.args...), q.identity.GetMSPIdentifier())
+	cached, generation := q.cache.get(key)
+	if cached != nil {
+		return cached, nil
+	}
+
+	response, err := q.endorse(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q.cache.put(key, generation, response)
+	return response, nil
+}
+
+func (q *QueryBuilder) endorse(ctx context.Context) (*fabricPeer.ProposalResponse, error) {
 	proposal, _, err := tx.Endorsement{
 		Channel:      q.channel,
 		Chaincode:    q.chaincode,
@@ -92,6 +113,7 @@ func NewQueryBuilder(ccCore *Core, identity msp.SigningIdentity, fn string, args
 		args:      tx.StringArgsBytes(args...),
 		identity:  identity,
 		peerPool:  ccCore.peerPool,
+		cache:     ccCore.queryCache,
 	}
 
 	return q
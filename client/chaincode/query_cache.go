@@ -0,0 +1,238 @@
+package chaincode
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	fabricPeer "github.com/hyperledger/fabric-protos-go/peer"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	hlfproto "github.com/s7techlab/hlf-sdk-go/block"
+	"github.com/s7techlab/hlf-sdk-go/observer"
+)
+
+// lifecycleNamespaces - writes to these namespaces (chaincode definition change) invalidate all channel queries
+var lifecycleNamespaces = map[string]struct{}{
+	`_lifecycle`: {},
+	`lscc`:       {},
+}
+
+// QueryCacheOpt describes option which will be applied to QueryCache
+type QueryCacheOpt func(c *QueryCache)
+
+// WithQueryCacheNamespaceInvalidation invalidates only queries of chaincodes, which state is written by observed block.
+// By default, all queries of channel are invalidated on new block
+func WithQueryCacheNamespaceInvalidation() QueryCacheOpt {
+	return func(c *QueryCache) {
+		c.byNamespace = true
+	}
+}
+
+// QueryCache caches successful chaincode query responses, keyed by channel, chaincode, args and identity MSP,
+// until new block of channel is observed with ObserveBlock, ObserveHeight, ObserveBlocks or WatchHeight.
+// Queries with transient args are not cached
+type QueryCache struct {
+	byNamespace bool
+
+	channels map[string]*queryCacheChannel
+	mu       sync.Mutex
+}
+
+type queryCacheChannel struct {
+	// height - observed channel ledger height, zero if unknown
+	height     uint64
+	chaincodes map[string]*queryCacheChaincode
+}
+
+type queryCacheChaincode struct {
+	// generation is changed on each invalidation, so response of query started before it isn't cached
+	generation uint64
+	responses  map[string]*fabricPeer.ProposalResponse
+}
+
+func NewQueryCache(opts ...QueryCacheOpt) *QueryCache {
+	c := &QueryCache{
+		channels: make(map[string]*queryCacheChannel),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// queryCacheKey identifies query response
+type queryCacheKey struct {
+	channel   string
+	chaincode string
+	query     string
+}
+
+func newQueryCacheKey(channel, chaincode string, args [][]byte, mspID string) queryCacheKey {
+	h := sha256.New()
+	for _, arg := range append([][]byte{[]byte(mspID)}, args...) {
+		// length prefix makes args boundaries unambiguous
+		_ = binary.Write(h, binary.BigEndian, uint64(len(arg)))
+		h.Write(arg)
+	}
+
+	return queryCacheKey{channel: channel, chaincode: chaincode, query: hex.EncodeToString(h.Sum(nil))}
+}
+
+// get returns cached response and current generation of chaincode cache, which is passed to put
+func (c *QueryCache) get(key queryCacheKey) (*fabricPeer.ProposalResponse, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cc := c.chaincode(key.channel, key.chaincode)
+	if response, ok := cc.responses[key.query]; ok {
+		return proto.Clone(response).(*fabricPeer.ProposalResponse), cc.generation
+	}
+
+	return nil, cc.generation
+}
+
+// put caches response if chaincode cache wasn't invalidated since generation was received
+func (c *QueryCache) put(key queryCacheKey, generation uint64, response *fabricPeer.ProposalResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cc := c.chaincode(key.channel, key.chaincode)
+	if cc.generation == generation {
+		cc.responses[key.query] = proto.Clone(response).(*fabricPeer.ProposalResponse)
+	}
+}
+
+// channel returns channel cache, must be called under lock
+func (c *QueryCache) channel(channel string) *queryCacheChannel {
+	ch, ok := c.channels[channel]
+	if !ok {
+		ch = &queryCacheChannel{chaincodes: make(map[string]*queryCacheChaincode)}
+		c.channels[channel] = ch
+	}
+
+	return ch
+}
+
+// chaincode returns chaincode cache, must be called under lock
+func (c *QueryCache) chaincode(channel, chaincode string) *queryCacheChaincode {
+	ch := c.channel(channel)
+
+	cc, ok := ch.chaincodes[chaincode]
+	if !ok {
+		cc = &queryCacheChaincode{responses: make(map[string]*fabricPeer.ProposalResponse)}
+		ch.chaincodes[chaincode] = cc
+	}
+
+	return cc
+}
+
+// ObserveHeight invalidates all cached queries of channel, if ledger height is changed
+func (c *QueryCache) ObserveHeight(channel string, height uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := c.channel(channel)
+	if ch.height == height {
+		return
+	}
+
+	ch.height = height
+	for _, cc := range ch.chaincodes {
+		cc.invalidate()
+	}
+}
+
+// ObserveBlock invalidates cached queries of channel on new block. With namespace invalidation
+// only queries of chaincodes, written by block valid transactions, are invalidated, if previous block was observed too
+func (c *QueryCache) ObserveBlock(channel string, block *hlfproto.Block) {
+	number := block.GetHeader().GetNumber()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := c.channel(channel)
+	if number < ch.height {
+		// block is already observed
+		return
+	}
+
+	namespaces, all := blockWrittenNamespaces(block)
+	// blocks are missed, so writes are unknown
+	all = all || !c.byNamespace || number != ch.height
+
+	ch.height = number + 1
+	for name, cc := range ch.chaincodes {
+		if _, written := namespaces[name]; all || written {
+			cc.invalidate()
+		}
+	}
+}
+
+// ObserveBlocks observes parsed blocks of channel until blocks channel is closed or context is done,
+// i.e. blocks from observer.ChannelBlocksParsed
+func (c *QueryCache) ObserveBlocks(ctx context.Context, blocks <-chan *observer.Block[*hlfproto.Block]) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case block, ok := <-blocks:
+			if !ok {
+				return
+			}
+			c.ObserveBlock(block.Channel, block.Block)
+		}
+	}
+}
+
+// WatchHeight polls channel ledger height of peer with period and observes it until context is done.
+// Errors of height request are skipped
+func (c *QueryCache) WatchHeight(ctx context.Context, peer api.ChainInfoGetter, channel string, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		if info, err := peer.GetChainInfo(ctx, channel); err == nil {
+			c.ObserveHeight(channel, info.GetHeight())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (cc *queryCacheChaincode) invalidate() {
+	cc.generation++
+	cc.responses = make(map[string]*fabricPeer.ProposalResponse)
+}
+
+// blockWrittenNamespaces returns namespaces, written by block valid transactions,
+// all is true if block changes chaincode definitions
+func blockWrittenNamespaces(block *hlfproto.Block) (namespaces map[string]struct{}, all bool) {
+	namespaces = make(map[string]struct{})
+	for _, e := range block.ValidEnvelopes() {
+		for _, a := range e.TxActions() {
+			for _, rwSet := range a.NsReadWriteSet() {
+				if len(rwSet.GetRwset().GetWrites()) == 0 && len(rwSet.GetCollectionHashedRwset()) == 0 {
+					continue
+				}
+
+				if _, ok := lifecycleNamespaces[rwSet.GetNamespace()]; ok {
+					all = true
+				}
+				namespaces[rwSet.GetNamespace()] = struct{}{}
+			}
+		}
+	}
+
+	return namespaces, all
+}
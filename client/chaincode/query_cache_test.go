@@ -0,0 +1,128 @@
+package chaincode_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	hlfproto "github.com/s7techlab/hlf-sdk-go/block"
+	"github.com/s7techlab/hlf-sdk-go/client/chaincode"
+	"github.com/s7techlab/hlf-sdk-go/identity"
+)
+
+// queryPoolMock responds with number of endorsements
+type queryPoolMock struct {
+	api.PeerPool
+
+	mu       sync.Mutex
+	endorsed int
+}
+
+func (p *queryPoolMock) EndorseOnMSP(context.Context, string, *peer.SignedProposal) (*peer.ProposalResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.endorsed++
+	return &peer.ProposalResponse{
+		Response: &peer.Response{Status: 200, Payload: []byte(fmt.Sprintf(`%d`, p.endorsed))},
+	}, nil
+}
+
+// blockWithWrites returns parsed block with valid transaction, writing to namespaces
+func blockWithWrites(number uint64, namespaces ...string) *hlfproto.Block {
+	var nsRwSets []*hlfproto.NsReadWriteSet
+	for _, ns := range namespaces {
+		nsRwSets = append(nsRwSets, &hlfproto.NsReadWriteSet{
+			Namespace: ns,
+			Rwset:     &kvrwset.KVRWSet{Writes: []*kvrwset.KVWrite{{Key: `k`, Value: []byte(`v`)}}},
+		})
+	}
+
+	return &hlfproto.Block{
+		Header: &common.BlockHeader{Number: number},
+		Data: &hlfproto.BlockData{Envelopes: []*hlfproto.Envelope{{
+			ValidationCode: peer.TxValidationCode_VALID,
+			Payload: &hlfproto.Payload{Transaction: &hlfproto.Transaction{Actions: []*hlfproto.TransactionAction{{
+				Payload: &hlfproto.ChaincodeActionPayload{Action: &hlfproto.ChaincodeEndorsedAction{
+					ProposalResponsePayload: &hlfproto.ProposalResponsePayload{Extension: &hlfproto.ChaincodeAction{
+						Results: &hlfproto.TxReadWriteSet{NsRwset: nsRwSets},
+					}},
+				}},
+			}}}},
+		}}},
+	}
+}
+
+func TestQueryCache(t *testing.T) {
+	signer, err := identity.NewSigningFromMSPPath(`Org1MSP`, `testdata/msp`)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	pool := &queryPoolMock{}
+
+	query := func(cache *chaincode.QueryCache, cc string, args ...string) string {
+		core := chaincode.NewCore(`Org1MSP`, cc, `channel`, []string{`Org1MSP`}, pool, nil, signer,
+			chaincode.WithQueryCache(cache))
+
+		res, err := core.Query(`get`, args...).AsBytes(ctx)
+		require.NoError(t, err)
+		return string(res)
+	}
+
+	t.Run(`cached until new block`, func(t *testing.T) {
+		cache := chaincode.NewQueryCache()
+		cache.ObserveHeight(`channel`, 10)
+
+		first := query(cache, `cc1`, `a`)
+		assert.Equal(t, first, query(cache, `cc1`, `a`))
+		// args are part of key
+		assert.NotEqual(t, first, query(cache, `cc1`, `b`))
+
+		cache.ObserveHeight(`channel`, 10)
+		assert.Equal(t, first, query(cache, `cc1`, `a`))
+
+		cache.ObserveBlock(`channel`, blockWithWrites(10, `cc2`))
+		assert.NotEqual(t, first, query(cache, `cc1`, `a`))
+	})
+
+	t.Run(`namespace invalidation`, func(t *testing.T) {
+		cache := chaincode.NewQueryCache(chaincode.WithQueryCacheNamespaceInvalidation())
+		cache.ObserveBlock(`channel`, blockWithWrites(5))
+
+		cc1, cc2 := query(cache, `cc1`), query(cache, `cc2`)
+
+		// block doesn't write cc1
+		cache.ObserveBlock(`channel`, blockWithWrites(6, `cc2`))
+		assert.Equal(t, cc1, query(cache, `cc1`))
+		assert.NotEqual(t, cc2, query(cache, `cc2`))
+
+		// block 7 is missed, so all queries are invalidated
+		cache.ObserveBlock(`channel`, blockWithWrites(8, `cc2`))
+		assert.NotEqual(t, cc1, query(cache, `cc1`))
+
+		// chaincode definition change invalidates all queries
+		cc1 = query(cache, `cc1`)
+		cache.ObserveBlock(`channel`, blockWithWrites(9, `_lifecycle`))
+		assert.NotEqual(t, cc1, query(cache, `cc1`))
+	})
+
+	t.Run(`transient queries are not cached`, func(t *testing.T) {
+		cache := chaincode.NewQueryCache()
+		core := chaincode.NewCore(`Org1MSP`, `cc1`, `channel`, []string{`Org1MSP`}, pool, nil, signer,
+			chaincode.WithQueryCache(cache))
+
+		first, err := core.Query(`get`).Transient(api.TransArgs{`k`: []byte(`v`)}).AsBytes(ctx)
+		require.NoError(t, err)
+		second, err := core.Query(`get`).Transient(api.TransArgs{`k`: []byte(`v`)}).AsBytes(ctx)
+		require.NoError(t, err)
+		assert.NotEqual(t, first, second)
+	})
+}
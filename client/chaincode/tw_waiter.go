@@ -2,15 +2,30 @@ package chaincode
 
 import (
 	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/client/chaincode/txwaiter"
 )
 
 // TxWaitBuilder function signature for pluggable setter on Do options
 type TxWaitBuilder func(cfg *api.DoOptions) (api.TxWaiter, error)
 
-// WithTxWaiter - add option for set custom tx waiter
+// WithTxWaiter - add option for set custom tx waiter, waiter is built after endorsement
+// with MSPs, which endorsed transaction
 func WithTxWaiter(builder TxWaitBuilder) api.DoOption {
+	return func(cfg *api.DoOptions) error {
+		cfg.TxWaiter, cfg.TxWaiterBuilder = nil, api.TxWaiterBuilder(builder)
+		return nil
+	}
+}
+
+// WithTxWaiterType - add option for set tx waiter by its type, i.e. 'all', 'any', 'none', 'quorum:2'
+func WithTxWaiterType(txWaiterType string) api.DoOption {
 	return func(cfg *api.DoOptions) (err error) {
-		cfg.TxWaiter, err = builder(cfg)
+		builder, err := txwaiter.ByType(txWaiterType)
+		if err != nil {
+			return err
+		}
+
+		cfg.TxWaiter, cfg.TxWaiterBuilder = nil, api.TxWaiterBuilder(builder)
 		return
 	}
 }
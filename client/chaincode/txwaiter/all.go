@@ -4,8 +4,6 @@ import (
 	"context"
 	"sync"
 
-	"github.com/pkg/errors"
-
 	"github.com/s7techlab/hlf-sdk-go/api"
 	clienterr "github.com/s7techlab/hlf-sdk-go/client/errors"
 )
@@ -17,16 +15,16 @@ func All(cfg *api.DoOptions) (api.TxWaiter, error) {
 		onceSet: new(sync.Once),
 	}
 
-	// make delivers for each mspID
+	// make commit waiters for each mspID
 	errD := new(clienterr.MultiError)
 	for i := range cfg.EndorsingMspIDs {
-		peerDeliver, err := cfg.Pool.DeliverClient(cfg.EndorsingMspIDs[i], cfg.Identity)
+		waitCommit, err := newCommitWaiter(cfg, cfg.EndorsingMspIDs[i])
 		if err != nil {
-			errD.Add(errors.Wrapf(err, "%s: failed to get delivery client", cfg.EndorsingMspIDs[i]))
+			errD.Add(err)
 			continue
 		}
 
-		waiter.delivers = append(waiter.delivers, peerDeliver)
+		waiter.waiters = append(waiter.waiters, waitCommit)
 	}
 	if len(errD.Errors) != 0 {
 		return nil, errD
@@ -36,9 +34,9 @@ func All(cfg *api.DoOptions) (api.TxWaiter, error) {
 }
 
 type allMspWaiter struct {
-	delivers []api.DeliverClient
-	onceSet  *sync.Once
-	hasErr   bool
+	waiters []commitWaiter
+	onceSet *sync.Once
+	hasErr  bool
 }
 
 func (w *allMspWaiter) setErr() {
@@ -47,15 +45,38 @@ func (w *allMspWaiter) setErr() {
 
 // Wait - implementation of api.TxWaiter interface
 func (w *allMspWaiter) Wait(ctx context.Context, channel string, txId string) error {
+	return waitRegistered(ctx, w, channel, txId)
+}
+
+// Register - implementation of api.TxWaitRegisterer interface, failed registrations are returned as wait errors
+func (w *allMspWaiter) Register(ctx context.Context, channel string, txId string) (api.TxWait, error) {
+	waits := make([]api.TxWait, len(w.waiters))
+	regErrs := make([]error, len(w.waiters))
+	for i := range w.waiters {
+		waits[i], regErrs[i] = w.waiters[i](ctx, channel, txId)
+	}
+
+	return func(ctx context.Context) error {
+		return w.wait(ctx, waits, regErrs)
+	}, nil
+}
+
+func (w *allMspWaiter) wait(ctx context.Context, waits []api.TxWait, regErrs []error) error {
 	var (
 		wg   = new(sync.WaitGroup)
-		errS = make(chan error, len(w.delivers))
+		errS = make(chan error, len(waits))
 	)
 
-	for i := range w.delivers {
+	for i := range waits {
+		if regErrs[i] != nil {
+			w.setErr()
+			errS <- regErrs[i]
+			continue
+		}
+
 		wg.Add(1)
 		go func(j int) {
-			err := waitPerOne(ctx, w.delivers[j], channel, txId)
+			err := waits[j](ctx)
 			if err != nil {
 				w.setErr()
 				errS <- err
@@ -78,14 +99,3 @@ func (w *allMspWaiter) Wait(ctx context.Context, channel string, txId string) er
 
 	return nil
 }
-
-func waitPerOne(ctx context.Context, deliver api.DeliverClient, channelName string, txId string) error {
-	sub, err := deliver.SubscribeTx(ctx, channelName, txId)
-	if err != nil {
-		return errors.Wrap(err, "failed to subscribe on tx event")
-	}
-	defer func() { _ = sub.Close() }()
-
-	_, err = sub.Result()
-	return err
-}
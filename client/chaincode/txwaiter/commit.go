@@ -0,0 +1,78 @@
+package txwaiter
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+)
+
+// commitWaiter registers waiter of transaction commit on peers of one MSP
+type commitWaiter func(ctx context.Context, channel string, txID string) (api.TxWait, error)
+
+// newCommitWaiter returns waiter, which uses shared MSP commit notifier if it is set in options,
+// or subscribes on transaction with MSP deliver client otherwise
+func newCommitWaiter(cfg *api.DoOptions, mspID string) (commitWaiter, error) {
+	if cfg.CommitNotifiers != nil {
+		notifier, err := cfg.CommitNotifiers.CommitNotifier(mspID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: failed to get commit notifier", mspID)
+		}
+
+		return func(ctx context.Context, channel string, txID string) (api.TxWait, error) {
+			wait, err := notifier.Register(ctx, channel, txID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "%s: failed to register tx", mspID)
+			}
+
+			return func(ctx context.Context) error {
+				_, err := wait(ctx)
+				return err
+			}, nil
+		}, nil
+	}
+
+	peerDeliver, err := cfg.Pool.DeliverClient(mspID, cfg.Identity)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: failed to get delivery client", mspID)
+	}
+
+	return func(ctx context.Context, channel string, txID string) (api.TxWait, error) {
+		return subscribePerOne(ctx, peerDeliver, channel, txID)
+	}, nil
+}
+
+// subscribePerOne subscribes on transaction, subscription is closed after returned func is called
+func subscribePerOne(ctx context.Context, deliver api.DeliverClient, channelName string, txId string) (api.TxWait, error) {
+	sub, err := deliver.SubscribeTx(ctx, channelName, txId)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to subscribe on tx event")
+	}
+
+	return func(ctx context.Context) error {
+		defer func() { _ = sub.Close() }()
+
+		result := make(chan error, 1)
+		go func() {
+			_, err := sub.Result()
+			result <- err
+		}()
+
+		select {
+		case err := <-result:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}, nil
+}
+
+// waitRegistered registers transaction with tx waiter and waits for its commit
+func waitRegistered(ctx context.Context, registerer api.TxWaitRegisterer, channel string, txID string) error {
+	wait, err := registerer.Register(ctx, channel, txID)
+	if err != nil {
+		return err
+	}
+	return wait(ctx)
+}
@@ -0,0 +1,25 @@
+package txwaiter
+
+import (
+	"context"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+)
+
+// None - "fire and forget" tx waiter, invoke returns right after transaction is broadcast to orderer
+// without waiting for commit
+func None(*api.DoOptions) (api.TxWaiter, error) {
+	return noneWaiter{}, nil
+}
+
+type noneWaiter struct{}
+
+// Wait - implementation of api.TxWaiter interface
+func (noneWaiter) Wait(context.Context, string, string) error {
+	return nil
+}
+
+// Register - implementation of api.TxWaitRegisterer interface
+func (noneWaiter) Register(context.Context, string, string) (api.TxWait, error) {
+	return func(context.Context) error { return nil }, nil
+}
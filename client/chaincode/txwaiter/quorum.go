@@ -0,0 +1,141 @@
+package txwaiter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	clienterr "github.com/s7techlab/hlf-sdk-go/client/errors"
+)
+
+// Builder - tx waiter constructor, compatible with chaincode.WithTxWaiter
+type Builder func(cfg *api.DoOptions) (api.TxWaiter, error)
+
+// Any - tx waiter returns after transaction is committed on peer of any organization from endorsement policy
+func Any(cfg *api.DoOptions) (api.TxWaiter, error) {
+	return newQuorumWaiter(cfg, cfg.EndorsingMspIDs, 1)
+}
+
+// Quorum - tx waiter returns after transaction is committed on peers of N organizations from endorsement policy
+func Quorum(n int) Builder {
+	return func(cfg *api.DoOptions) (api.TxWaiter, error) {
+		if n < 1 || n > len(cfg.EndorsingMspIDs) {
+			return nil, fmt.Errorf(`quorum=%d, endorsing MSPs=%d: %w`, n, len(cfg.EndorsingMspIDs), ErrInvalidQuorum)
+		}
+
+		return newQuorumWaiter(cfg, cfg.EndorsingMspIDs, n)
+	}
+}
+
+// MSPs - tx waiter returns after transaction is committed on peers of each of provided organizations,
+// organizations may differ from endorsing ones
+func MSPs(mspIDs ...string) Builder {
+	return func(cfg *api.DoOptions) (api.TxWaiter, error) {
+		return newQuorumWaiter(cfg, mspIDs, len(mspIDs))
+	}
+}
+
+type quorumWaiter struct {
+	mspIDs   []string
+	waiters  []commitWaiter
+	required int
+}
+
+func newQuorumWaiter(cfg *api.DoOptions, mspIDs []string, required int) (*quorumWaiter, error) {
+	if len(mspIDs) == 0 {
+		return nil, ErrNoMSPs
+	}
+
+	waiter := &quorumWaiter{
+		required: required,
+	}
+
+	errD := new(clienterr.MultiError)
+	for _, mspID := range mspIDs {
+		waitCommit, err := newCommitWaiter(cfg, mspID)
+		if err != nil {
+			errD.Add(err)
+			continue
+		}
+
+		waiter.mspIDs = append(waiter.mspIDs, mspID)
+		waiter.waiters = append(waiter.waiters, waitCommit)
+	}
+
+	// tx waiter can't reach quorum without commit waiters
+	if len(waiter.waiters) < required {
+		return nil, errD
+	}
+
+	return waiter, nil
+}
+
+// Wait - implementation of api.TxWaiter interface,
+// waits are cancelled as soon as required number of organizations committed transaction
+func (w *quorumWaiter) Wait(ctx context.Context, channel string, txId string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	return waitRegistered(ctx, w, channel, txId)
+}
+
+// Register - implementation of api.TxWaitRegisterer interface, transaction is registered on each organization,
+// failed registrations are counted as failed commits
+func (w *quorumWaiter) Register(ctx context.Context, channel string, txId string) (api.TxWait, error) {
+	waits := make([]api.TxWait, len(w.waiters))
+	regErrs := make([]error, len(w.waiters))
+	for i := range w.waiters {
+		waits[i], regErrs[i] = w.waiters[i](ctx, channel, txId)
+	}
+
+	return func(ctx context.Context) error {
+		return w.wait(ctx, waits, regErrs)
+	}, nil
+}
+
+func (w *quorumWaiter) wait(ctx context.Context, waits []api.TxWait, regErrs []error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errS := make(chan error, len(waits))
+	for i := range waits {
+		if regErrs[i] != nil {
+			errS <- errors.Wrap(regErrs[i], w.mspIDs[i])
+			continue
+		}
+
+		go func(j int) {
+			err := waits[j](ctx)
+			if err != nil {
+				err = errors.Wrap(err, w.mspIDs[j])
+			}
+			errS <- err
+		}(i)
+	}
+
+	var (
+		committed int
+		mErr      = new(clienterr.MultiError)
+	)
+	for range w.waiters {
+		err := <-errS
+		if err != nil {
+			mErr.Add(err)
+		} else {
+			committed++
+		}
+
+		if committed >= w.required {
+			return nil
+		}
+
+		// quorum can't be reached anymore
+		if len(mErr.Errors) > len(w.waiters)-w.required {
+			return mErr
+		}
+	}
+
+	return mErr
+}
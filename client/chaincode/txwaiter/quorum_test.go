@@ -0,0 +1,143 @@
+package txwaiter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+)
+
+var errTxInvalid = errors.New(`tx invalid`)
+
+// txResult - result of tx commit on MSP peer, nil channel blocks until context is done
+type txResult chan error
+
+type poolMock struct {
+	api.PeerPool
+
+	results map[string]txResult
+}
+
+func (p *poolMock) DeliverClient(mspID string, _ msp.SigningIdentity) (api.DeliverClient, error) {
+	result, ok := p.results[mspID]
+	if !ok {
+		return nil, errors.New(`no peers for MSP`)
+	}
+	return &deliverMock{result: result}, nil
+}
+
+type deliverMock struct {
+	api.DeliverClient
+
+	result txResult
+}
+
+func (d *deliverMock) SubscribeTx(ctx context.Context, _ string, _ string, _ ...api.EventCCSeekOption) (api.TxSubscription, error) {
+	return &txSubscriptionMock{ctx: ctx, result: d.result}, nil
+}
+
+type txSubscriptionMock struct {
+	ctx    context.Context
+	result txResult
+}
+
+func (s *txSubscriptionMock) Result() (peer.TxValidationCode, error) {
+	select {
+	case err := <-s.result:
+		return peer.TxValidationCode_VALID, err
+	case <-s.ctx.Done():
+		return peer.TxValidationCode_INVALID_OTHER_REASON, s.ctx.Err()
+	}
+}
+
+func (s *txSubscriptionMock) Close() error {
+	return nil
+}
+
+func committed(err error) txResult {
+	result := make(txResult, 1)
+	result <- err
+	return result
+}
+
+func TestQuorum(t *testing.T) {
+	pool := &poolMock{results: map[string]txResult{
+		`Org1MSP`: committed(nil),
+		`Org2MSP`: committed(errTxInvalid),
+		`Org3MSP`: committed(nil),
+		`Org4MSP`: nil, // peer never responds
+	}}
+	cfg := &api.DoOptions{Pool: pool, EndorsingMspIDs: []string{`Org1MSP`, `Org2MSP`, `Org3MSP`, `Org4MSP`}}
+
+	waiter, err := Quorum(2)(cfg)
+	require.NoError(t, err)
+	assert.NoError(t, waiter.Wait(context.Background(), `channel`, `tx`))
+
+	_, err = Quorum(5)(cfg)
+	assert.ErrorIs(t, err, ErrInvalidQuorum)
+}
+
+func TestQuorumNotReached(t *testing.T) {
+	pool := &poolMock{results: map[string]txResult{
+		`Org1MSP`: committed(nil),
+		`Org2MSP`: committed(errTxInvalid),
+		`Org3MSP`: committed(errTxInvalid),
+	}}
+	cfg := &api.DoOptions{Pool: pool, EndorsingMspIDs: []string{`Org1MSP`, `Org2MSP`, `Org3MSP`}}
+
+	waiter, err := Quorum(2)(cfg)
+	require.NoError(t, err)
+	assert.ErrorContains(t, waiter.Wait(context.Background(), `channel`, `tx`), errTxInvalid.Error())
+}
+
+func TestAny(t *testing.T) {
+	pool := &poolMock{results: map[string]txResult{
+		`Org1MSP`: nil,
+		`Org2MSP`: committed(nil),
+	}}
+
+	waiter, err := Any(&api.DoOptions{Pool: pool, EndorsingMspIDs: []string{`Org1MSP`, `Org2MSP`, `Org3MSP`}})
+	require.NoError(t, err)
+	assert.NoError(t, waiter.Wait(context.Background(), `channel`, `tx`))
+}
+
+func TestMSPs(t *testing.T) {
+	pool := &poolMock{results: map[string]txResult{
+		`Org1MSP`: committed(nil),
+		`Org2MSP`: committed(errTxInvalid),
+	}}
+	cfg := &api.DoOptions{Pool: pool}
+
+	waiter, err := MSPs(`Org1MSP`)(cfg)
+	require.NoError(t, err)
+	assert.NoError(t, waiter.Wait(context.Background(), `channel`, `tx`))
+
+	waiter, err = MSPs(`Org1MSP`, `Org2MSP`)(cfg)
+	require.NoError(t, err)
+	assert.ErrorContains(t, waiter.Wait(context.Background(), `channel`, `tx`), errTxInvalid.Error())
+
+	_, err = MSPs(`Org1MSP`, `Org3MSP`)(cfg)
+	assert.Error(t, err)
+}
+
+func TestByType(t *testing.T) {
+	for _, txWaiterType := range []string{``, `self`, `all`, `any`, `none`, `quorum:2`, `msp:Org1MSP, Org2MSP`} {
+		_, err := ByType(txWaiterType)
+		assert.NoError(t, err, txWaiterType)
+	}
+
+	_, err := ByType(`quorum:zero`)
+	assert.ErrorIs(t, err, ErrInvalidQuorum)
+
+	_, err = ByType(`msp:`)
+	assert.ErrorIs(t, err, ErrNoMSPs)
+
+	_, err = ByType(`majority`)
+	assert.ErrorIs(t, err, ErrUnknownType)
+}
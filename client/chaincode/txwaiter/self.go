@@ -3,9 +3,6 @@ package txwaiter
 import (
 	"context"
 
-	"github.com/hyperledger/fabric/msp"
-	"github.com/pkg/errors"
-
 	"github.com/s7techlab/hlf-sdk-go/api"
 )
 
@@ -13,30 +10,25 @@ import (
 // txwaiter.Self  make subscribe tx on one peer endorser organization
 func Self(cfg *api.DoOptions) (api.TxWaiter, error) {
 	return &selfPeerWaiter{
-		pool:     cfg.Pool,
-		identity: cfg.Identity,
+		cfg: cfg,
 	}, nil
 }
 
 type selfPeerWaiter struct {
-	pool     api.PeerPool
-	identity msp.SigningIdentity
+	cfg *api.DoOptions
 }
 
 // Wait - implementation of api.TxWaiter interface
 func (w *selfPeerWaiter) Wait(ctx context.Context, channel string, txID string) error {
-	mspID := w.identity.GetMSPIdentifier()
-	deliver, err := w.pool.DeliverClient(mspID, w.identity)
-	if err != nil {
-		return errors.Wrapf(err, "%s: failed to get delivery client", mspID)
-	}
+	return waitRegistered(ctx, w, channel, txID)
+}
 
-	sub, err := deliver.SubscribeTx(ctx, channel, txID)
+// Register - implementation of api.TxWaitRegisterer interface
+func (w *selfPeerWaiter) Register(ctx context.Context, channel string, txID string) (api.TxWait, error) {
+	register, err := newCommitWaiter(w.cfg, w.cfg.Identity.GetMSPIdentifier())
 	if err != nil {
-		return errors.Wrapf(err, "%s: failed to subscribe on tx event", mspID)
+		return nil, err
 	}
-	defer func() { _ = sub.Close() }()
 
-	_, err = sub.Result()
-	return err
+	return register(ctx, channel, txID)
 }
@@ -0,0 +1,55 @@
+package txwaiter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+)
+
+var (
+	ErrUnknownType   = errors.New(`unknown tx waiter type`)
+	ErrInvalidQuorum = errors.New(`invalid tx waiter quorum`)
+	ErrNoMSPs        = errors.New(`no MSPs to wait tx`)
+)
+
+// ByType returns tx waiter builder by its type:
+// 'self' (default), 'all', 'any', 'none', 'quorum:N' or 'msp:Org1MSP,Org2MSP'
+func ByType(txWaiterType string) (Builder, error) {
+	switch {
+	case txWaiterType == `` || txWaiterType == api.TxWaiterSelfType:
+		return Self, nil
+	case txWaiterType == api.TxWaiterAllType:
+		return All, nil
+	case txWaiterType == api.TxWaiterAnyType:
+		return Any, nil
+	case txWaiterType == api.TxWaiterNoneType:
+		return None, nil
+
+	case strings.HasPrefix(txWaiterType, api.TxWaiterQuorumPrefix):
+		n, err := strconv.Atoi(strings.TrimPrefix(txWaiterType, api.TxWaiterQuorumPrefix))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf(`%s: %w`, txWaiterType, ErrInvalidQuorum)
+		}
+		return Quorum(n), nil
+
+	case strings.HasPrefix(txWaiterType, api.TxWaiterMSPsPrefix):
+		var mspIDs []string
+		for _, mspID := range strings.Split(strings.TrimPrefix(txWaiterType, api.TxWaiterMSPsPrefix), `,`) {
+			if mspID = strings.TrimSpace(mspID); mspID != `` {
+				mspIDs = append(mspIDs, mspID)
+			}
+		}
+		if len(mspIDs) == 0 {
+			return nil, fmt.Errorf(`%s: %w`, txWaiterType, ErrNoMSPs)
+		}
+		return MSPs(mspIDs...), nil
+	}
+
+	return nil, fmt.Errorf(`%s, available: '%s', '%s', '%s', '%s', '%sN', '%sMSP1,MSP2': %w`,
+		txWaiterType, api.TxWaiterSelfType, api.TxWaiterAllType, api.TxWaiterAnyType, api.TxWaiterNoneType,
+		api.TxWaiterQuorumPrefix, api.TxWaiterMSPsPrefix, ErrUnknownType)
+}
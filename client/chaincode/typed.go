@@ -0,0 +1,232 @@
+package chaincode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/golang/protobuf/proto"
+	fabricPeer "github.com/hyperledger/fabric-protos-go/peer"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/client/tx"
+)
+
+var ErrNotProtoMessage = errors.New(`value is not proto message`)
+
+// Codec marshals typed chaincode args and unmarshals responses and events payloads
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	// JSONCodec marshals values as JSON
+	JSONCodec Codec = jsonCodec{}
+	// ProtoCodec marshals values as protobuf, values must be proto messages
+	ProtoCodec Codec = protoCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type protoCodec struct{}
+
+func (protoCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf(`%w: %T`, ErrNotProtoMessage, v)
+	}
+	return proto.Marshal(msg)
+}
+
+func (protoCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf(`%w: %T`, ErrNotProtoMessage, v)
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+// NoArgs - request type of chaincode function without arguments
+type NoArgs struct{}
+
+// Contract - chaincode on channel, which functions are called with typed methods, see NewTyped
+type Contract struct {
+	Invoker   api.Invoker
+	Channel   string
+	Chaincode string
+	Codec     Codec
+}
+
+func NewContract(invoker api.Invoker, channel, chaincode string, codec Codec) *Contract {
+	return &Contract{
+		Invoker:   invoker,
+		Channel:   channel,
+		Chaincode: chaincode,
+		Codec:     codec,
+	}
+}
+
+// Typed - typed method of chaincode, mapped to chaincode function.
+// Request is marshaled with contract codec as the single function argument, response payload is unmarshaled to Resp.
+// Signer, transient map and tx waiter type are taken from context, see tx.ContextWithSigner,
+// tx.ContextWithTransientMap and tx.ContextWithTxWaiter
+type Typed[Req, Resp any] struct {
+	contract *Contract
+	fn       string
+}
+
+func NewTyped[Req, Resp any](contract *Contract, fn string) *Typed[Req, Resp] {
+	return &Typed[Req, Resp]{
+		contract: contract,
+		fn:       fn,
+	}
+}
+
+// Query evaluates chaincode function without sending transaction to orderer
+func (t *Typed[Req, Resp]) Query(ctx context.Context, req Req) (Resp, error) {
+	var resp Resp
+
+	args, err := t.args(req)
+	if err != nil {
+		return resp, err
+	}
+
+	res, err := t.contract.Invoker.Query(ctx, t.contract.Channel, t.contract.Chaincode, args,
+		tx.SignerFromContext(ctx), tx.TransientFromContext(ctx))
+	if err != nil {
+		return resp, fmt.Errorf(`query fn=%s: %w`, t.fn, err)
+	}
+
+	return t.response(res)
+}
+
+// Invoke invokes chaincode function and returns its response with transaction id
+func (t *Typed[Req, Resp]) Invoke(ctx context.Context, req Req) (Resp, string, error) {
+	var resp Resp
+
+	args, err := t.args(req)
+	if err != nil {
+		return resp, ``, err
+	}
+
+	res, txID, err := t.contract.Invoker.Invoke(ctx, t.contract.Channel, t.contract.Chaincode, args,
+		tx.SignerFromContext(ctx), tx.TransientFromContext(ctx), tx.TxWaiterFromContext(ctx))
+	if err != nil {
+		return resp, txID, fmt.Errorf(`invoke fn=%s: %w`, t.fn, err)
+	}
+
+	resp, err = t.response(res)
+	return resp, txID, err
+}
+
+func (t *Typed[Req, Resp]) args(req Req) ([][]byte, error) {
+	if _, ok := any(req).(NoArgs); ok {
+		return tx.FnArgs(t.fn), nil
+	}
+
+	arg, err := t.contract.Codec.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf(`marshal fn=%s request: %w`, t.fn, err)
+	}
+
+	return tx.FnArgs(t.fn, arg), nil
+}
+
+func (t *Typed[Req, Resp]) response(res *fabricPeer.Response) (Resp, error) {
+	resp, err := decode[Resp](t.contract.Codec, res.GetPayload())
+	if err != nil {
+		return resp, fmt.Errorf(`unmarshal fn=%s response to %T: %w`, t.fn, resp, err)
+	}
+
+	return resp, nil
+}
+
+// TypedEvent - chaincode event with decoded payload, Err is set if payload can't be decoded
+type TypedEvent[T any] struct {
+	EventName string
+	TxID      string
+	Payload   T
+	Err       error
+}
+
+// DecodeEvent decodes chaincode event payload with codec
+func DecodeEvent[T any](codec Codec, event *fabricPeer.ChaincodeEvent) TypedEvent[T] {
+	payload, err := decode[T](codec, event.GetPayload())
+	if err != nil {
+		err = fmt.Errorf(`unmarshal event=%s payload to %T: %w`, event.GetEventName(), payload, err)
+	}
+
+	return TypedEvent[T]{
+		EventName: event.GetEventName(),
+		TxID:      event.GetTxId(),
+		Payload:   payload,
+		Err:       err,
+	}
+}
+
+// TypedEvents decodes chaincode events with name (all events if name is empty), i.e. from api.EventCCSubscription,
+// returned channel is closed when events channel is closed or context is done
+func TypedEvents[T any](
+	ctx context.Context, events <-chan *fabricPeer.ChaincodeEvent, codec Codec, eventName string) <-chan TypedEvent[T] {
+	typed := make(chan TypedEvent[T])
+
+	go func() {
+		defer close(typed)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+
+				if eventName != `` && event.GetEventName() != eventName {
+					continue
+				}
+
+				select {
+				case typed <- DecodeEvent[T](codec, event):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return typed
+}
+
+// decode unmarshals data to new value of T, if T is pointer type, value is allocated.
+// Zero value is returned for empty data, i.e. chaincode function without response
+func decode[T any](codec Codec, data []byte) (T, error) {
+	var v T
+	if len(data) == 0 {
+		return v, nil
+	}
+
+	target := interface{}(&v)
+
+	if rt := reflect.TypeOf(v); rt != nil && rt.Kind() == reflect.Ptr {
+		v = reflect.New(rt.Elem()).Interface().(T)
+		target = v
+	}
+
+	if err := codec.Unmarshal(data, target); err != nil {
+		return v, err
+	}
+
+	return v, nil
+}
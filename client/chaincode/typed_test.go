@@ -0,0 +1,140 @@
+package chaincode_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/client/chaincode"
+	"github.com/s7techlab/hlf-sdk-go/client/tx"
+)
+
+// invokerMock responds with payload and records received args
+type invokerMock struct {
+	api.Invoker
+
+	args         [][]byte
+	txWaiterType string
+	payload      []byte
+}
+
+func (i *invokerMock) Query(_ context.Context, _, _ string, args [][]byte, _ msp.SigningIdentity,
+	_ map[string][]byte) (*peer.Response, error) {
+	i.args = args
+	return &peer.Response{Status: 200, Payload: i.payload}, nil
+}
+
+func (i *invokerMock) Invoke(_ context.Context, _, _ string, args [][]byte, _ msp.SigningIdentity,
+	_ map[string][]byte, txWaiterType string) (*peer.Response, string, error) {
+	i.args = args
+	i.txWaiterType = txWaiterType
+	return &peer.Response{Status: 200, Payload: i.payload}, `tx1`, nil
+}
+
+type asset struct {
+	ID    string `json:"id"`
+	Value int    `json:"value"`
+}
+
+func TestTyped(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run(`json`, func(t *testing.T) {
+		invoker := &invokerMock{payload: []byte(`{"id":"a1","value":10}`)}
+		contract := chaincode.NewContract(invoker, `channel`, `cc`, chaincode.JSONCodec)
+
+		res, err := chaincode.NewTyped[string, asset](contract, `get`).Query(ctx, `a1`)
+		require.NoError(t, err)
+		assert.Equal(t, asset{ID: `a1`, Value: 10}, res)
+		assert.Equal(t, [][]byte{[]byte(`get`), []byte(`"a1"`)}, invoker.args)
+
+		put := chaincode.NewTyped[asset, *asset](contract, `put`)
+		ptrRes, txID, err := put.Invoke(tx.ContextWithTxWaiter(ctx, `all`), asset{ID: `a1`, Value: 10})
+		require.NoError(t, err)
+		assert.Equal(t, `tx1`, txID)
+		assert.Equal(t, &asset{ID: `a1`, Value: 10}, ptrRes)
+		assert.Equal(t, `all`, invoker.txWaiterType)
+		assert.JSONEq(t, `{"id":"a1","value":10}`, string(invoker.args[1]))
+	})
+
+	t.Run(`proto`, func(t *testing.T) {
+		payload, err := proto.Marshal(&peer.ChaincodeID{Name: `cc`, Version: `1`})
+		require.NoError(t, err)
+
+		invoker := &invokerMock{payload: payload}
+		contract := chaincode.NewContract(invoker, `channel`, `cc`, chaincode.ProtoCodec)
+
+		res, err := chaincode.NewTyped[*peer.ChaincodeID, *peer.ChaincodeID](contract, `get`).
+			Query(ctx, &peer.ChaincodeID{Name: `cc`})
+		require.NoError(t, err)
+		assert.Equal(t, `1`, res.Version)
+
+		req := &peer.ChaincodeID{}
+		require.NoError(t, proto.Unmarshal(invoker.args[1], req))
+		assert.Equal(t, `cc`, req.Name)
+
+		_, err = chaincode.NewTyped[string, *peer.ChaincodeID](contract, `get`).Query(ctx, `cc`)
+		assert.ErrorIs(t, err, chaincode.ErrNotProtoMessage)
+	})
+
+	t.Run(`no args`, func(t *testing.T) {
+		invoker := &invokerMock{payload: []byte(`["a1","a2"]`)}
+		contract := chaincode.NewContract(invoker, `channel`, `cc`, chaincode.JSONCodec)
+
+		res, err := chaincode.NewTyped[chaincode.NoArgs, []string](contract, `list`).Query(ctx, chaincode.NoArgs{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{`a1`, `a2`}, res)
+		assert.Equal(t, [][]byte{[]byte(`list`)}, invoker.args)
+	})
+
+	t.Run(`empty response`, func(t *testing.T) {
+		contract := chaincode.NewContract(&invokerMock{}, `channel`, `cc`, chaincode.JSONCodec)
+
+		res, err := chaincode.NewTyped[string, asset](contract, `get`).Query(ctx, `a1`)
+		require.NoError(t, err)
+		assert.Equal(t, asset{}, res)
+
+		ptrRes, _, err := chaincode.NewTyped[asset, *asset](contract, `put`).Invoke(ctx, asset{ID: `a1`})
+		require.NoError(t, err)
+		assert.Nil(t, ptrRes)
+	})
+
+	t.Run(`invalid response`, func(t *testing.T) {
+		invoker := &invokerMock{payload: []byte(`not json`)}
+		contract := chaincode.NewContract(invoker, `channel`, `cc`, chaincode.JSONCodec)
+
+		_, err := chaincode.NewTyped[string, asset](contract, `get`).Query(ctx, `a1`)
+		var syntaxErr *json.SyntaxError
+		assert.ErrorAs(t, err, &syntaxErr)
+	})
+}
+
+func TestTypedEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan *peer.ChaincodeEvent, 3)
+	events <- &peer.ChaincodeEvent{EventName: `created`, TxId: `tx1`, Payload: []byte(`{"id":"a1","value":1}`)}
+	events <- &peer.ChaincodeEvent{EventName: `deleted`, TxId: `tx2`, Payload: []byte(`{"id":"a1"}`)}
+	events <- &peer.ChaincodeEvent{EventName: `created`, TxId: `tx3`, Payload: []byte(`invalid`)}
+	close(events)
+
+	var typed []chaincode.TypedEvent[asset]
+	for e := range chaincode.TypedEvents[asset](ctx, events, chaincode.JSONCodec, `created`) {
+		typed = append(typed, e)
+	}
+
+	require.Len(t, typed, 2)
+	assert.NoError(t, typed[0].Err)
+	assert.Equal(t, `tx1`, typed[0].TxID)
+	assert.Equal(t, asset{ID: `a1`, Value: 1}, typed[0].Payload)
+	assert.Equal(t, `tx3`, typed[1].TxID)
+	assert.Error(t, typed[1].Err)
+}
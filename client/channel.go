@@ -2,11 +2,15 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/hyperledger/fabric-protos-go/common"
+	fabricPeer "github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protoutil"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 
@@ -14,56 +18,280 @@ import (
 	"github.com/s7techlab/hlf-sdk-go/api/config"
 	"github.com/s7techlab/hlf-sdk-go/block"
 	"github.com/s7techlab/hlf-sdk-go/client/chaincode"
-	"github.com/s7techlab/hlf-sdk-go/client/grpc"
+	"github.com/s7techlab/hlf-sdk-go/client/discovery"
+	clienterrors "github.com/s7techlab/hlf-sdk-go/client/errors"
 	"github.com/s7techlab/hlf-sdk-go/client/tx"
+	"github.com/s7techlab/hlf-sdk-go/crypto"
 	"github.com/s7techlab/hlf-sdk-go/service/systemcc/cscc"
 )
 
+// DefaultEndorsementPolicyRefreshTimeout - timeout of chaincode endorsers refresh after endorsement policy failure
+const DefaultEndorsementPolicyRefreshTimeout = 30 * time.Second
+
+const (
+	// DefaultEndorserVerifierRetryDelay - min delay between channel config fetches for endorser verifier
+	DefaultEndorserVerifierRetryDelay    = time.Second
+	DefaultEndorserVerifierMaxRetryDelay = time.Minute
+)
+
 type Channel struct {
-	mspId        string
-	chanName     string
-	peerPool     api.PeerPool
-	orderer      api.Orderer
-	chaincodes   map[string]*chaincode.Core
+	mspId      string
+	chanName   string
+	peerPool   api.PeerPool
+	orderer    api.Orderer
+	chaincodes map[string]*chaincode.Core
+	// chains - invocation chains of chaincodes, key is chain key
+	chains       map[string]api.InvocationChain
 	chaincodesMx sync.Mutex
-	dp           api.DiscoveryProvider
-	identity     msp.SigningIdentity
-	fabricV2     bool
-	log          *zap.Logger
+	// refreshing - keys of chaincodes, refreshed after endorsement policy failure
+	refreshing map[string]struct{}
+	dp         api.DiscoveryProvider
+	identity   msp.SigningIdentity
+	fabricV2   bool
+	log        *zap.Logger
+
+	peerCheckStrategy PeerCheckStrategyProvider
+	peerAdder         PeerAdder
+	commitNotifiers   api.CommitNotifiers
+	queryCache        *chaincode.QueryCache
+
+	// cryptoSuite - if set, endorsers signatures are verified against channel config MSPs
+	cryptoSuite crypto.Suite
+	// verifierOptional - signatures aren't verified, if channel config can't be fetched
+	verifierOptional bool
+	verifier         chaincode.EndorserVerifier
+	// verifierErr - last channel config fetch error, returned until verifierFetchAt
+	verifierErr      error
+	verifierFetchAt  time.Time
+	verifierFailures int
+	// verifierFetching - closed, when channel config fetch by one of invokes is finished
+	verifierFetching chan struct{}
+	verifierMx       sync.Mutex
 }
 
+// PeerAdder connects to discovered MSP peer and adds it to pool
+type PeerAdder func(ctx context.Context, mspID string, peerConfig config.ConnectionConfig) error
+
 var _ api.Channel = (*Channel)(nil)
 
+// ChannelOpt describes option which will be applied to Channel
+type ChannelOpt func(c *Channel)
+
+// WithChannelPeerCheckStrategy sets health check strategies for discovered endorsers added to pool
+func WithChannelPeerCheckStrategy(provider PeerCheckStrategyProvider) ChannelOpt {
+	return func(c *Channel) {
+		c.peerCheckStrategy = provider
+	}
+}
+
+// WithChannelPeerAdder sets func, used for adding discovered chaincode endorsers to pool
+func WithChannelPeerAdder(adder PeerAdder) ChannelOpt {
+	return func(c *Channel) {
+		c.peerAdder = adder
+	}
+}
+
+// WithChannelCommitNotifiers sets shared commit notifiers for channel chaincodes tx waiters
+func WithChannelCommitNotifiers(notifiers api.CommitNotifiers) ChannelOpt {
+	return func(c *Channel) {
+		c.commitNotifiers = notifiers
+	}
+}
+
+// WithChannelQueryCache sets cache of channel chaincodes query responses
+func WithChannelQueryCache(cache *chaincode.QueryCache) ChannelOpt {
+	return func(c *Channel) {
+		c.queryCache = cache
+	}
+}
+
+// WithChannelEndorserVerification enables verification of endorsers signatures against channel config MSPs.
+// If channel config can't be fetched, invoke fails, or, if optional is set, signatures are not verified
+func WithChannelEndorserVerification(suite crypto.Suite, optional bool) ChannelOpt {
+	return func(c *Channel) {
+		c.cryptoSuite = suite
+		c.verifierOptional = optional
+	}
+}
+
 // Chaincode - returns interface with actions over chaincode
 // ctx is necessary for service discovery
 func (c *Channel) Chaincode(serviceDiscCtx context.Context, ccName string) (api.Chaincode, error) {
+	return c.ChaincodeInvocationChain(serviceDiscCtx, api.InvocationChain{{Name: ccName}})
+}
+
+// ChaincodeInvocationChain - returns interface with actions over the first chaincode of chain,
+// invokes are endorsed by peers, satisfying endorsement policies of all chaincodes and collections in chain
+func (c *Channel) ChaincodeInvocationChain(serviceDiscCtx context.Context, chain api.InvocationChain) (api.Chaincode, error) {
+	if len(chain) == 0 {
+		return nil, discovery.ErrEmptyInvocationChain
+	}
+
 	c.chaincodesMx.Lock()
 	defer c.chaincodesMx.Unlock()
 
-	cc, ok := c.chaincodes[ccName]
+	key := chain.Key()
+	cc, ok := c.chaincodes[key]
 	if ok {
 		return cc, nil
 	}
 
+	ccName := chain[0].Name
 	if c.chanName == `` {
-		cc = chaincode.NewCore(c.mspId, ccName, c.chanName, []string{c.mspId}, c.peerPool, c.orderer, c.identity)
-		c.chaincodes[ccName] = cc
+		cc = chaincode.NewCore(c.mspId, ccName, c.chanName, []string{c.mspId}, c.peerPool, c.orderer, c.identity,
+			chaincode.WithCommitNotifiers(c.commitNotifiers),
+			chaincode.WithQueryCache(c.queryCache))
+		c.chaincodes[key] = cc
 
 		return cc, nil
 	}
 
-	cd, err := c.dp.Chaincode(serviceDiscCtx, c.chanName, ccName)
+	cd, err := c.discoverChaincode(serviceDiscCtx, chain)
 	if err != nil {
 		return nil, fmt.Errorf("chaincode discovery: %w", err)
 	}
 
+	endorserMSPs, err := c.addEndorsers(serviceDiscCtx, cd)
+	if err != nil {
+		return nil, err
+	}
+
+	cc = chaincode.NewCore(c.mspId, ccName, c.chanName, endorserMSPs, c.peerPool, c.orderer, c.identity,
+		chaincode.WithEndorsementLayouts(cd.EndorsementLayouts()),
+		chaincode.WithCommitNotifiers(c.commitNotifiers),
+		chaincode.WithQueryCache(c.queryCache),
+		chaincode.WithEndorserVerifier(c.endorserVerifier),
+		chaincode.WithEndorsementPolicyFailureHandler(func(context.Context) {
+			c.onEndorsementPolicyFailure(key)
+		}))
+	c.chaincodes[key] = cc
+	c.chains[key] = chain
+
+	return cc, nil
+}
+
+func (c *Channel) discoverChaincode(ctx context.Context, chain api.InvocationChain) (api.ChaincodeDiscoverer, error) {
+	if chain.Simple() {
+		return c.dp.Chaincode(ctx, c.chanName, chain[0].Name)
+	}
+
+	chainProvider, ok := c.dp.(api.InvocationChainDiscoveryProvider)
+	if !ok {
+		return nil, discovery.ErrInvocationChainNotSupported
+	}
+
+	return chainProvider.InvocationChain(ctx, c.chanName, chain)
+}
+
+// RefreshChaincodes re-queries discovery for chaincodes, already used on channel,
+// adds new endorsers to pool and updates chaincodes endorsing MSPs
+func (c *Channel) RefreshChaincodes(ctx context.Context) error {
+	if c.chanName == `` {
+		return nil
+	}
+
+	c.chaincodesMx.Lock()
+	keys := make([]string, 0, len(c.chains))
+	for key := range c.chains {
+		keys = append(keys, key)
+	}
+	c.chaincodesMx.Unlock()
+
+	// channel config MSPs could be changed too
+	c.verifierMx.Lock()
+	c.verifier = nil
+	c.verifierFetchAt = time.Time{}
+	c.verifierMx.Unlock()
+
+	mErr := new(clienterrors.MultiError)
+	for _, key := range keys {
+		if err := c.refreshChaincode(ctx, key); err != nil {
+			mErr.Add(err)
+		}
+	}
+
+	if len(mErr.Errors) > 0 {
+		return mErr
+	}
+	return nil
+}
+
+// refreshChaincode re-discovers chaincode invocation chain by key and updates chaincode endorsement
+func (c *Channel) refreshChaincode(ctx context.Context, key string) error {
+	c.chaincodesMx.Lock()
+	cc, ccOk := c.chaincodes[key]
+	chain, chainOk := c.chains[key]
+	c.chaincodesMx.Unlock()
+
+	if !ccOk || !chainOk {
+		return nil
+	}
+
+	cd, err := c.discoverChaincode(ctx, chain)
+	if err != nil {
+		return fmt.Errorf("chaincode=%s discovery: %w", key, err)
+	}
+
+	endorserMSPs, err := c.addEndorsers(ctx, cd)
+	if err != nil {
+		return fmt.Errorf("chaincode=%s: %w", key, err)
+	}
+
+	cc.SetEndorsement(endorserMSPs, cd.EndorsementLayouts())
+	return nil
+}
+
+// onEndorsementPolicyFailure drops cached chaincode discovery results and refreshes chaincode endorsers,
+// as endorsement policy could be changed. Refresh runs in background with own timeout, so it doesn't delay
+// failed invoke and isn't cancelled with invoke context. Concurrent failures of the same chaincode start one refresh
+func (c *Channel) onEndorsementPolicyFailure(key string) {
+	c.chaincodesMx.Lock()
+	if _, ok := c.refreshing[key]; ok {
+		c.chaincodesMx.Unlock()
+		return
+	}
+	c.refreshing[key] = struct{}{}
+	chain := c.chains[key]
+	c.chaincodesMx.Unlock()
+
+	go func() {
+		defer func() {
+			c.chaincodesMx.Lock()
+			delete(c.refreshing, key)
+			c.chaincodesMx.Unlock()
+		}()
+
+		if invalidator, ok := c.dp.(discovery.Invalidator); ok {
+			for _, call := range chain {
+				invalidator.InvalidateChaincode(c.chanName, call.Name)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultEndorsementPolicyRefreshTimeout)
+		defer cancel()
+
+		if err := c.refreshChaincode(ctx, key); err != nil {
+			c.log.Warn(`refresh chaincode after endorsement policy failure`,
+				zap.String(`chaincode`, key), zap.Error(err))
+		}
+	}()
+}
+
+// addEndorsers adds discovered endorsers of all chaincode endorsement layouts to pool, so invoke can fall back
+// to any layout, and returns endorsing MSPs of the first layout
+func (c *Channel) addEndorsers(ctx context.Context, cd api.ChaincodeDiscoverer) ([]string, error) {
 	var endorserMSPs []string
+	for _, endorser := range cd.Endorsers() {
+		endorserMSPs = append(endorserMSPs, endorser.MspID)
+	}
+
 	endorsers := cd.Endorsers()
-	errGr, _ := errgroup.WithContext(serviceDiscCtx)
+	if layoutsDiscoverer, ok := cd.(api.LayoutsEndorsersDiscoverer); ok {
+		endorsers = layoutsDiscoverer.LayoutsEndorsers()
+	}
+	errGr, _ := errgroup.WithContext(ctx)
 
 	for i := range endorsers {
-		endorserMSPs = append(endorserMSPs, endorsers[i].MspID)
-
 		for j := range endorsers[i].HostAddresses {
 			hostAddr := endorsers[i].HostAddresses[j]
 			// we can get empty address in local discovery and peers must be already in pool
@@ -75,30 +303,30 @@ func (c *Channel) Chaincode(serviceDiscCtx context.Context, ccName string) (api.
 				Host: hostAddr.Host,
 				Tls:  hostAddr.TlsConfig,
 			}
-			l := c.log
 
 			errGr.Go(func() error {
-				var p api.Peer
-				p, err = NewPeer(serviceDiscCtx, grpcCfg, c.identity, l)
-				if err != nil {
-					return fmt.Errorf("initialize endorsers for MSP: %s: %w", mspID, err)
-				}
-				if err = c.peerPool.Add(mspID, p, StrategyGRPC(grpc.DefaultGrpcCheckPeriod)); err != nil {
-					return fmt.Errorf("add endorser peer to pool: %s:%w", mspID, err)
-				}
-				return nil
+				return c.peerAdder(ctx, mspID, grpcCfg)
 			})
 		}
 	}
 
-	if err = errGr.Wait(); err != nil {
+	if err := errGr.Wait(); err != nil {
 		return nil, err
 	}
 
-	cc = chaincode.NewCore(c.mspId, ccName, c.chanName, endorserMSPs, c.peerPool, c.orderer, c.identity)
-	c.chaincodes[ccName] = cc
+	return endorserMSPs, nil
+}
 
-	return cc, nil
+// addPeer connects to peer and adds it to pool
+func (c *Channel) addPeer(ctx context.Context, mspID string, peerConfig config.ConnectionConfig) error {
+	p, err := NewPeer(ctx, peerConfig, c.identity, c.log)
+	if err != nil {
+		return fmt.Errorf("initialize endorsers for MSP: %s: %w", mspID, err)
+	}
+	if err = c.peerPool.Add(mspID, p, c.peerCheckStrategy(mspID)); err != nil {
+		return fmt.Errorf("add endorser peer to pool: %s:%w", mspID, err)
+	}
+	return nil
 }
 
 func NewChannel(
@@ -109,18 +337,209 @@ func NewChannel(
 	identity msp.SigningIdentity,
 	fabricV2 bool,
 	log *zap.Logger,
+	opts ...ChannelOpt,
 ) api.Channel {
-	return &Channel{
+	c := &Channel{
 		mspId:      mspId,
 		chanName:   chanName,
 		peerPool:   peerPool,
 		orderer:    orderer,
 		chaincodes: make(map[string]*chaincode.Core),
+		chains:     make(map[string]api.InvocationChain),
+		refreshing: make(map[string]struct{}),
 		dp:         dp,
 		identity:   identity,
 		fabricV2:   fabricV2,
 		log:        log,
+
+		peerCheckStrategy: DefaultPeerCheckStrategy,
+	}
+	c.peerAdder = c.addPeer
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// endorserVerifier returns verifier, built from channel config MSPs. Nil verifier is returned, if verification
+// is disabled or is optional and channel config is unavailable. Verifier is rebuilt, if endorser MSP is unknown
+// or its certificate isn't issued by MSP, i.e. after channel config update
+func (c *Channel) endorserVerifier(ctx context.Context) (chaincode.EndorserVerifier, error) {
+	if c.cryptoSuite == nil {
+		return nil, nil
+	}
+
+	verifier, err := c.channelVerifier(ctx, nil)
+	if err != nil {
+		if c.verifierOptional {
+			c.log.Warn(`endorsers signatures are not verified`, zap.Error(err))
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &refreshingVerifier{ctx: ctx, channel: c, verifier: verifier}, nil
+}
+
+// channelVerifier returns cached verifier or fetches channel config and builds verifier, if there is no cached one
+// or cached one is stale. Fetch failures are cached with backoff, so cscc isn't called on each invoke.
+// Channel config is fetched without verifier lock, concurrent invokes use cached verifier or wait for fetch result
+func (c *Channel) channelVerifier(ctx context.Context, stale chaincode.EndorserVerifier) (chaincode.EndorserVerifier, error) {
+	c.verifierMx.Lock()
+	for {
+		verifier, verifierErr, fetching := c.verifier, c.verifierErr, c.verifierFetching
+
+		fresh := verifier != nil && verifier != stale
+		backoff := time.Now().Before(c.verifierFetchAt) && (verifier != nil || verifierErr != nil)
+		// stale verifier is used, while channel config is fetched by concurrent invoke
+		if fresh || backoff || (fetching != nil && verifier != nil) {
+			c.verifierMx.Unlock()
+			if verifier != nil {
+				return verifier, nil
+			}
+			return nil, verifierErr
+		}
+
+		if fetching == nil {
+			break
+		}
+
+		c.verifierMx.Unlock()
+		select {
+		case <-fetching:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		c.verifierMx.Lock()
+	}
+
+	fetching := make(chan struct{})
+	c.verifierFetching = fetching
+	c.verifierMx.Unlock()
+
+	verifier, err := c.fetchVerifier(ctx)
+
+	c.verifierMx.Lock()
+	defer c.verifierMx.Unlock()
+
+	c.verifierFetching = nil
+	close(fetching)
+
+	if err != nil {
+		// caller's context is done, channel config isn't unavailable
+		if ctx.Err() != nil {
+			return nil, err
+		}
+
+		delay := DefaultEndorserVerifierRetryDelay << c.verifierFailures
+		if delay <= 0 || delay > DefaultEndorserVerifierMaxRetryDelay {
+			delay = DefaultEndorserVerifierMaxRetryDelay
+		} else {
+			c.verifierFailures++
+		}
+
+		c.verifierErr = fmt.Errorf(`%w: %s`, ErrEndorserVerifierUnavailable, err)
+		c.verifierFetchAt = time.Now().Add(delay)
+		if c.verifier != nil {
+			return c.verifier, nil
+		}
+		return nil, c.verifierErr
+	}
+
+	c.setVerifier(verifier)
+	return verifier, nil
+}
+
+// setVerifier caches verifier, must be called under verifier lock. Next rebuild is allowed after min delay
+func (c *Channel) setVerifier(verifier chaincode.EndorserVerifier) {
+	c.verifier = verifier
+	c.verifierErr = nil
+	c.verifierFailures = 0
+	c.verifierFetchAt = time.Now().Add(DefaultEndorserVerifierRetryDelay)
+}
+
+func (c *Channel) fetchVerifier(ctx context.Context) (chaincode.EndorserVerifier, error) {
+	peers := c.peerPool.GetMSPPeers(c.mspId)
+	if len(peers) == 0 {
+		return nil, fmt.Errorf(`fetch channel config: %w: %s`, ErrNoPeersForMSP, c.mspId)
+	}
+
+	channelConfig, err := cscc.NewCSCC(peers[0], block.FabricVersionIsV2(c.fabricV2)).
+		GetChannelConfig(ctx, &cscc.GetChannelConfigRequest{Channel: c.chanName})
+	if err != nil {
+		return nil, fmt.Errorf(`fetch channel config: %w`, err)
+	}
+
+	verifier, err := chaincode.NewMSPEndorserVerifierFromConfig(channelConfig, c.cryptoSuite)
+	if err != nil {
+		return nil, fmt.Errorf(`endorser verifier from channel config: %w`, err)
+	}
+
+	return verifier, nil
+}
+
+// configBlockObserver - orderer, which updates its state from channel config blocks, i.e. BFT consenters
+type configBlockObserver interface {
+	ObserveConfigBlock(ctx context.Context, configBlock *common.Block) error
+}
+
+// ObserveConfigBlock rebuilds endorser verifier from config block of channel, i.e. received by blocks observer,
+// and passes config block to channel orderer, if it observes config updates. Blocks of other types are skipped
+func (c *Channel) ObserveConfigBlock(ctx context.Context, configBlock *common.Block) error {
+	if !protoutil.IsConfigBlock(configBlock) {
+		return nil
+	}
+
+	if observer, ok := c.orderer.(configBlockObserver); ok {
+		if err := observer.ObserveConfigBlock(ctx, configBlock); err != nil {
+			return fmt.Errorf(`orderer: %w`, err)
+		}
+	}
+
+	if c.cryptoSuite == nil {
+		return nil
+	}
+
+	channelConfig, err := block.ParseConfigBlock(configBlock)
+	if err != nil {
+		return fmt.Errorf(`parse config block: %w`, err)
+	}
+
+	verifier, err := chaincode.NewMSPEndorserVerifierFromConfig(channelConfig, c.cryptoSuite)
+	if err != nil {
+		return fmt.Errorf(`endorser verifier from channel config: %w`, err)
 	}
+
+	c.verifierMx.Lock()
+	c.setVerifier(verifier)
+	c.verifierMx.Unlock()
+
+	return nil
+}
+
+// refreshingVerifier rebuilds channel verifier from actual channel config and verifies endorsement again,
+// if endorser MSP is unknown or endorser certificate isn't issued by MSP
+type refreshingVerifier struct {
+	ctx      context.Context
+	channel  *Channel
+	verifier chaincode.EndorserVerifier
+}
+
+func (v *refreshingVerifier) Verify(endorsement *fabricPeer.Endorsement, payload []byte) error {
+	err := v.verifier.Verify(endorsement, payload)
+	if !errors.Is(err, chaincode.ErrEndorserMSPUnknown) && !errors.Is(err, chaincode.ErrEndorserCertInvalid) {
+		return err
+	}
+
+	refreshed, refreshErr := v.channel.channelVerifier(v.ctx, v.verifier)
+	if refreshErr != nil || refreshed == v.verifier {
+		return err
+	}
+
+	v.verifier = refreshed
+	return refreshed.Verify(endorsement, payload)
 }
 
 func (c *Channel) Join(ctx context.Context) error {
@@ -0,0 +1,125 @@
+package client_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/api/config"
+	"github.com/s7techlab/hlf-sdk-go/client"
+	"github.com/s7techlab/hlf-sdk-go/client/chaincode"
+)
+
+type chaincodeDiscovererMock struct {
+	api.ChaincodeDiscoverer
+
+	endorsers []*api.HostEndpoint
+}
+
+func (d *chaincodeDiscovererMock) Endorsers() []*api.HostEndpoint {
+	return d.endorsers
+}
+
+// layoutsDiscovererMock - chaincode discoverer with endorsers of several layouts
+type layoutsDiscovererMock struct {
+	chaincodeDiscovererMock
+
+	layoutsEndorsers []*api.HostEndpoint
+}
+
+func (d *layoutsDiscovererMock) LayoutsEndorsers() []*api.HostEndpoint {
+	return d.layoutsEndorsers
+}
+
+func (d *chaincodeDiscovererMock) EndorsementLayouts() []api.EndorsementLayout {
+	return nil
+}
+
+type discoveryProviderMock struct {
+	api.DiscoveryProvider
+
+	mu               sync.Mutex
+	endorsers        []*api.HostEndpoint
+	layoutsEndorsers []*api.HostEndpoint
+}
+
+func (d *discoveryProviderMock) Chaincode(context.Context, string, string) (api.ChaincodeDiscoverer, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.layoutsEndorsers != nil {
+		return &layoutsDiscovererMock{
+			chaincodeDiscovererMock: chaincodeDiscovererMock{endorsers: d.endorsers},
+			layoutsEndorsers:        d.layoutsEndorsers,
+		}, nil
+	}
+	return &chaincodeDiscovererMock{endorsers: d.endorsers}, nil
+}
+
+func (d *discoveryProviderMock) setEndorsers(endorsers ...*api.HostEndpoint) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.endorsers = endorsers
+}
+
+func endorser(mspID, host string) *api.HostEndpoint {
+	return &api.HostEndpoint{MspID: mspID, HostAddresses: []*api.Endpoint{{Host: host}}}
+}
+
+func TestChannelRefreshChaincodes(t *testing.T) {
+	ctx := context.Background()
+	pool := client.NewPeerPool(ctx, zap.NewNop())
+	dp := &discoveryProviderMock{}
+	dp.setEndorsers(endorser(`org1`, `peer1`))
+
+	noCheck := func(ctx context.Context, peer api.Peer, alive chan bool) {}
+	peerAdder := func(_ context.Context, mspID string, peerConfig config.ConnectionConfig) error {
+		return pool.Add(mspID, &closablePeerMock{uri: peerConfig.Host}, noCheck)
+	}
+
+	ch := client.NewChannel(`org1`, `channel`, pool, nil, dp, nil, true, zap.NewNop(),
+		client.WithChannelPeerAdder(peerAdder))
+
+	cc, err := ch.Chaincode(ctx, `cc`)
+	require.NoError(t, err)
+	assert.Len(t, cc.(*chaincode.Core).GetPeers(), 1)
+
+	dp.setEndorsers(endorser(`org1`, `peer1`), endorser(`org2`, `peer2`))
+	require.NoError(t, ch.(*client.Channel).RefreshChaincodes(ctx))
+
+	peers := cc.(*chaincode.Core).GetPeers()
+	require.Len(t, peers, 2)
+	assert.Equal(t, `peer2`, peers[1].URI())
+}
+
+func TestChannelLayoutsEndorsers(t *testing.T) {
+	ctx := context.Background()
+	pool := client.NewPeerPool(ctx, zap.NewNop())
+	dp := &discoveryProviderMock{
+		endorsers:        []*api.HostEndpoint{endorser(`org1`, `peer1`)},
+		layoutsEndorsers: []*api.HostEndpoint{endorser(`org1`, `peer1`), endorser(`org2`, `peer2`)},
+	}
+
+	noCheck := func(ctx context.Context, peer api.Peer, alive chan bool) {}
+	peerAdder := func(_ context.Context, mspID string, peerConfig config.ConnectionConfig) error {
+		return pool.Add(mspID, &closablePeerMock{uri: peerConfig.Host}, noCheck)
+	}
+
+	ch := client.NewChannel(`org1`, `channel`, pool, nil, dp, nil, true, zap.NewNop(),
+		client.WithChannelPeerAdder(peerAdder))
+
+	cc, err := ch.Chaincode(ctx, `cc`)
+	require.NoError(t, err)
+
+	// endorsers of all layouts are in pool, chaincode is endorsed by the first layout MSPs
+	assert.Len(t, pool.GetPeers(), 2)
+	peers := cc.(*chaincode.Core).GetPeers()
+	require.Len(t, peers, 1)
+	assert.Equal(t, `peer1`, peers[0].URI())
+}
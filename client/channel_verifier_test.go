@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	fabricPeer "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/s7techlab/hlf-sdk-go/client/chaincode"
+	"github.com/s7techlab/hlf-sdk-go/crypto"
+)
+
+type suiteMock struct {
+	crypto.Suite
+}
+
+type endorserVerifierMock struct {
+	err   error
+	calls int
+}
+
+func (v *endorserVerifierMock) Verify(*fabricPeer.Endorsement, []byte) error {
+	v.calls++
+	return v.err
+}
+
+func newVerifierTestChannel(ctx context.Context, optional bool) *Channel {
+	return NewChannel(`org1`, `channel`, NewPeerPool(ctx, zap.NewNop()), nil, nil, nil, true, zap.NewNop(),
+		WithChannelEndorserVerification(suiteMock{}, optional)).(*Channel)
+}
+
+func TestEndorserVerifierUnavailable(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := newVerifierTestChannel(ctx, false)
+
+	_, err := c.endorserVerifier(ctx)
+	require.ErrorIs(t, err, ErrEndorserVerifierUnavailable)
+	assert.True(t, c.verifierFetchAt.After(time.Now()))
+
+	// channel config isn't fetched again until backoff delay passes
+	fetchAt := c.verifierFetchAt
+	_, err = c.endorserVerifier(ctx)
+	require.ErrorIs(t, err, ErrEndorserVerifierUnavailable)
+	assert.Equal(t, fetchAt, c.verifierFetchAt)
+	assert.Equal(t, 1, c.verifierFailures)
+}
+
+func TestEndorserVerifierOptional(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	verifier, err := newVerifierTestChannel(ctx, true).endorserVerifier(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, verifier)
+}
+
+func TestEndorserVerifierRefresh(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := newVerifierTestChannel(ctx, false)
+	stale := &endorserVerifierMock{err: chaincode.ErrEndorserMSPUnknown}
+	c.verifier = stale
+
+	verifier, err := c.endorserVerifier(ctx)
+	require.NoError(t, err)
+
+	// verifier is rebuilt, i.e. from config block, when endorser MSP is unknown to cached one
+	actual := &endorserVerifierMock{}
+	c.verifierMx.Lock()
+	c.setVerifier(actual)
+	c.verifierMx.Unlock()
+
+	require.NoError(t, verifier.Verify(&fabricPeer.Endorsement{}, nil))
+	assert.Equal(t, 1, stale.calls)
+	assert.Equal(t, 1, actual.calls)
+
+	// stale verifier error is returned, if channel config can't be fetched
+	c.verifier = stale
+	c.verifierFetchAt = time.Time{}
+	verifier, err = c.endorserVerifier(ctx)
+	require.NoError(t, err)
+	require.ErrorIs(t, verifier.Verify(&fabricPeer.Endorsement{}, nil), chaincode.ErrEndorserMSPUnknown)
+}
+
+func TestEndorserVerifierConcurrentFetch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := newVerifierTestChannel(ctx, false)
+	stale := &endorserVerifierMock{}
+	c.verifier = stale
+
+	// channel config is fetched by concurrent invoke
+	fetching := make(chan struct{})
+	c.verifierFetching = fetching
+
+	// stale verifier is used without waiting for fetch result
+	verifier, err := c.channelVerifier(ctx, stale)
+	require.NoError(t, err)
+	assert.Equal(t, stale, verifier)
+
+	// invoke without verifier waits for fetch result
+	c.verifier = nil
+	actual := &endorserVerifierMock{}
+	go func() {
+		c.verifierMx.Lock()
+		c.setVerifier(actual)
+		c.verifierFetching = nil
+		close(fetching)
+		c.verifierMx.Unlock()
+	}()
+
+	verifier, err = c.channelVerifier(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, actual, verifier)
+}
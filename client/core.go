@@ -4,21 +4,29 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"sync"
+	"time"
 
 	"github.com/hyperledger/fabric/msp"
 	"go.uber.org/zap"
 
 	"github.com/s7techlab/hlf-sdk-go/api"
 	"github.com/s7techlab/hlf-sdk-go/api/config"
+	"github.com/s7techlab/hlf-sdk-go/block"
+	"github.com/s7techlab/hlf-sdk-go/client/chaincode"
+	"github.com/s7techlab/hlf-sdk-go/client/deliver"
 	"github.com/s7techlab/hlf-sdk-go/client/discovery"
-	"github.com/s7techlab/hlf-sdk-go/client/grpc"
+	"github.com/s7techlab/hlf-sdk-go/client/gateway"
 	"github.com/s7techlab/hlf-sdk-go/crypto"
 )
 
 // implementation of api.Core interface
 var _ api.Client = (*Client)(nil)
 
+// DefaultChannelConfigTimeout - timeout of channel config fetching for detecting BFT orderer on the first request
+const DefaultChannelConfigTimeout = 10 * time.Second
+
 var (
 	ErrEmptyMSPConfig              = errors.New(`empty MSP config`)
 	ErrDiscoveryConnectionRequired = errors.New(`discovery connection required`)
@@ -31,27 +39,46 @@ type Client struct {
 
 	defaultSigner msp.SigningIdentity // default signer for requests
 
-	peerPool api.PeerPool
-	orderer  api.Orderer
+	peerPool          api.PeerPool
+	peerCheckStrategy PeerCheckStrategyProvider
+	orderer           api.Orderer
+	// gateway - invoker backed by Fabric Gateway service, Invoke and Query are delegated to it if set
+	gateway api.Invoker
 
 	discoveryProvider api.DiscoveryProvider
 	discoverySigner   msp.SigningIdentity // signer for discovery queries
+	// discoveredPeers - peers added to pool from discovery, msp id -> host.
+	// They are removed from pool on discovery refresh, when they leave network
+	discoveredPeers   map[string]map[string]struct{}
+	discoveredPeersMx sync.Mutex
 
 	channels  map[string]api.Channel
 	channelMx sync.Mutex
 
-	crypto   crypto.Suite
-	logger   *zap.Logger
-	fabricV2 bool
+	// commitNotifiers - shared block streams for tx waiters, created with channel if not set by option
+	commitNotifiers    api.CommitNotifiers
+	commitNotifiersSet bool
+
+	// queryCache - cache of chaincodes query responses, disabled if not set
+	queryCache *chaincode.QueryCache
+
+	crypto crypto.Suite
+	// endorserVerification - endorsers signatures are verified against channel config MSPs before ordering
+	endorserVerification bool
+	// endorserVerificationOptional - endorsers signatures aren't verified, if channel config is unavailable
+	endorserVerificationOptional bool
+	logger                       *zap.Logger
+	fabricV2                     bool
 }
 
 func New(ctx context.Context, opts ...Opt) (*Client, error) {
 	var err error
 
 	client := &Client{
-		ctx:      ctx,
-		config:   &config.Config{},
-		channels: make(map[string]api.Channel),
+		ctx:             ctx,
+		config:          &config.Config{},
+		channels:        make(map[string]api.Channel),
+		discoveredPeers: make(map[string]map[string]struct{}),
 	}
 
 	for _, opt := range opts {
@@ -84,75 +111,70 @@ func New(ctx context.Context, opts ...Opt) (*Client, error) {
 			}
 
 		case string(discovery.GossipServiceDiscoveryType):
-			if client.config.Discovery.Connection == nil {
-				return nil, ErrDiscoveryConnectionRequired
+			client.discoveryProvider, err = client.gossipDiscoveryProvider(client.ctx, mapper)
+			if err != nil {
+				return nil, err
 			}
 
-			if client.discoverySigner == nil {
-				return nil, ErrDiscoverySignerRequired
+			// discovery initialized, add local peers to the pool
+			if err = client.addLocalPeers(); err != nil {
+				return nil, err
 			}
 
-			client.logger.Info("gossip discovery provider", zap.Reflect(`connection`, client.config.Discovery.Connection))
+			client.initDiscoveryRefresh()
 
-			identitySigner := func(msg []byte) ([]byte, error) {
-				return client.discoverySigner.Sign(msg)
+		case string(discovery.HybridServiceDiscoveryType):
+			// discovery peer can be unreachable, so dial is limited with timeout
+			dialTimeout := PeerDefaultDialTimeout
+			if client.config.Discovery.Connection != nil && client.config.Discovery.Connection.Timeout.Duration > 0 {
+				dialTimeout = client.config.Discovery.Connection.Timeout.Duration
 			}
-
-			clientIdentity, err := client.discoverySigner.Serialize()
-			if err != nil {
-				return nil, fmt.Errorf(`serialize current defaultSigner: %w`, err)
+			gossipFactory := func() (api.DiscoveryProvider, error) {
+				dialCtx, cancel := context.WithTimeout(client.ctx, dialTimeout)
+				defer cancel()
+				return client.gossipDiscoveryProvider(dialCtx, mapper)
 			}
 
-			// add tls settings from mapper if they were provided
-			conn := mapper.MapConnection(client.config.Discovery.Connection.Host)
-			client.config.Discovery.Connection.Tls = conn.TlsConfig
-			client.config.Discovery.Connection.Host = conn.Host
-
-			client.discoveryProvider, err = discovery.NewGossipDiscoveryProvider(
-				client.ctx,
-				*client.config.Discovery.Connection,
-				client.logger,
-				identitySigner,
-				clientIdentity,
-				mapper,
-			)
-			if err != nil {
-				return nil, fmt.Errorf(`initialize discovery provider: %w`, err)
+			gossipProvider, err := gossipFactory()
+			if errors.Is(err, ErrDiscoveryConnectionRequired) || errors.Is(err, ErrDiscoverySignerRequired) {
+				return nil, err
 			}
 
-			// discovery initialized, add local peers to the pool
-			lDiscoverer, err := client.discoveryProvider.LocalPeers(client.ctx)
 			if err != nil {
-				return nil, fmt.Errorf(`fetch local peers from discovery provider connection=%s: %w`,
-					client.config.Discovery.Connection.Host, err)
+				client.logger.Warn(`gossip discovery unavailable, using local config`, zap.Error(err))
+				gossipProvider = nil
 			}
 
-			peers := lDiscoverer.Peers()
-
-			for _, lp := range peers {
-				mspID := lp.MspID
-
-				for _, lpAddresses := range lp.HostAddresses {
-					peerCfg := config.ConnectionConfig{
-						Host: lpAddresses.Host,
-						Tls:  lpAddresses.TlsConfig,
-					}
+			client.logger.Info("hybrid discovery provider", zap.Reflect(`options`, client.config.Discovery.Options))
 
-					p, err := NewPeer(client.ctx, peerCfg, client.defaultSigner, client.logger)
-					if err != nil {
-						return nil, fmt.Errorf(`initialize endorsers for MSP: %s: %w`, mspID, err)
+			client.discoveryProvider, err = discovery.NewHybridProvider(
+				gossipProvider, client.config.Discovery.Options, mapper, client.logger,
+				// gossip, unavailable at startup, is reconnected lazily, local peers are added when it's available
+				discovery.WithGossipFactory(func() (api.DiscoveryProvider, error) {
+					provider, err := gossipFactory()
+					if err == nil {
+						go client.addLocalPeersFrom(provider)
 					}
+					return provider, err
+				}))
+			if err != nil {
+				return nil, fmt.Errorf(`initialize discovery provider: %w`, err)
+			}
 
-					if err = client.peerPool.Add(mspID, p, StrategyGRPC(grpc.DefaultGrpcCheckPeriod)); err != nil {
-						return nil, fmt.Errorf(`add peer to pool: %w`, err)
-					}
+			if gossipProvider != nil {
+				if err = client.addLocalPeers(); err != nil {
+					client.logger.Warn(`add local peers from gossip discovery`, zap.Error(err))
 				}
 			}
+
+			client.initDiscoveryRefresh()
+
 		default:
-			return nil, fmt.Errorf("unknown discovery type=%v. available: %v, %v",
+			return nil, fmt.Errorf("unknown discovery type=%v. available: %v, %v, %v",
 				client.config.Discovery.Type,
 				discovery.LocalConfigServiceDiscoveryType,
 				discovery.GossipServiceDiscoveryType,
+				discovery.HybridServiceDiscoveryType,
 			)
 		}
 	}
@@ -160,21 +182,130 @@ func New(ctx context.Context, opts ...Opt) (*Client, error) {
 	if client.orderer == nil && client.config != nil {
 		client.logger.Info("initializing orderer")
 		if len(client.config.Orderers) > 0 {
-			ordConn, err := grpc.ConnectionFromConfigs(client.ctx, client.logger, client.config.Orderers...)
-			if err != nil {
-				return nil, fmt.Errorf(`initialize orderer connection: %w`, err)
-			}
-
-			client.orderer, err = NewOrdererFromGRPC(ordConn)
+			client.orderer, err = NewOrdererPoolFromConfigs(client.ctx, client.logger, client.config.Orderers,
+				client.ordererPoolOpts()...)
 			if err != nil {
 				return nil, fmt.Errorf(`initialize orderer: %w`, err)
 			}
 		}
 	}
 
+	if client.gateway == nil && client.config != nil && client.config.Gateway != nil {
+		client.logger.Info("initializing gateway", zap.String(`host`, client.config.Gateway.Host))
+		gw, err := gateway.NewFromConfig(client.ctx, *client.config.Gateway, client.defaultSigner, client.logger)
+		if err != nil {
+			return nil, fmt.Errorf(`initialize gateway: %w`, err)
+		}
+		client.gateway = gw
+
+		// gateway connection lives as long as client
+		go func() {
+			<-client.ctx.Done()
+			_ = gw.Close()
+		}()
+	}
+
 	return client, nil
 }
 
+// ordererPoolOpts returns options of orderer pool from config
+func (c *Client) ordererPoolOpts() []OrdererPoolOpt {
+	if c.config == nil || c.config.BroadcastStream == nil {
+		return nil
+	}
+
+	return []OrdererPoolOpt{WithOrdererOpts(WithBroadcastStream(c.config.BroadcastStream.MaxInFlight))}
+}
+
+// gossipDiscoveryProvider creates gossip discovery provider from config, ctx is used for dial to discovery peer
+func (c *Client) gossipDiscoveryProvider(ctx context.Context, mapper *discovery.EndpointsMapper) (api.DiscoveryProvider, error) {
+	if c.config.Discovery.Connection == nil {
+		return nil, ErrDiscoveryConnectionRequired
+	}
+
+	if c.discoverySigner == nil {
+		return nil, ErrDiscoverySignerRequired
+	}
+
+	c.logger.Info("gossip discovery provider", zap.Reflect(`connection`, c.config.Discovery.Connection))
+
+	identitySigner := func(msg []byte) ([]byte, error) {
+		return c.discoverySigner.Sign(msg)
+	}
+
+	clientIdentity, err := c.discoverySigner.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf(`serialize current defaultSigner: %w`, err)
+	}
+
+	// add tls settings from mapper if they were provided
+	conn := mapper.MapConnection(c.config.Discovery.Connection.Host)
+	c.config.Discovery.Connection.Tls = conn.TlsConfig
+	c.config.Discovery.Connection.Host = conn.Host
+
+	provider, err := discovery.NewGossipDiscoveryProvider(
+		ctx,
+		*c.config.Discovery.Connection,
+		c.logger,
+		identitySigner,
+		clientIdentity,
+		mapper,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(`initialize discovery provider: %w`, err)
+	}
+
+	return provider, nil
+}
+
+// addLocalPeers adds peers from discovery to the pool
+func (c *Client) addLocalPeers() error {
+	lDiscoverer, err := c.discoveryProvider.LocalPeers(c.ctx)
+	if err != nil {
+		return fmt.Errorf(`fetch local peers from discovery provider connection=%s: %w`,
+			c.config.Discovery.Connection.Host, err)
+	}
+
+	for _, lp := range lDiscoverer.Peers() {
+		for _, lpAddresses := range lp.HostAddresses {
+			peerCfg := config.ConnectionConfig{
+				Host: lpAddresses.Host,
+				Tls:  lpAddresses.TlsConfig,
+			}
+
+			if err = c.addDiscoveredPeer(c.ctx, lp.MspID, peerCfg); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// addLocalPeersFrom adds to pool local peers of gossip discovery provider, which became available after startup
+func (c *Client) addLocalPeersFrom(provider api.DiscoveryProvider) {
+	localPeers, err := provider.LocalPeers(c.ctx)
+	if err == nil {
+		err = c.reconcileLocalPeers(c.ctx, localPeers)
+	}
+
+	if err != nil {
+		c.logger.Warn(`add local peers from gossip discovery`, zap.Error(err))
+	}
+}
+
+// initDiscoveryRefresh enables discovery results caching and periodic refresh, if they are configured
+func (c *Client) initDiscoveryRefresh() {
+	if cacheConfig := c.config.Discovery.Cache; cacheConfig != nil {
+		c.discoveryProvider = discovery.NewCachingProvider(
+			c.discoveryProvider, discovery.CacheOptsFromConfig(*cacheConfig)...)
+	}
+
+	if refreshPeriod := c.config.Discovery.RefreshPeriod.Duration; refreshPeriod > 0 {
+		go c.refreshDiscovery(c.ctx, refreshPeriod)
+	}
+}
+
 func applyDefaults(c *Client) error {
 	var err error
 	if c.logger == nil {
@@ -197,7 +328,17 @@ func (c *Client) initPeerPool() error {
 		return ErrEmptyMSPConfig
 	}
 
-	c.peerPool = NewPeerPool(c.ctx, c.logger)
+	balancer, err := NewBalancer(c.config.Pool.Balancer)
+	if err != nil {
+		return fmt.Errorf(`peer pool balancer: %w`, err)
+	}
+
+	poolOpts := []PeerPoolOpt{WithBalancer(balancer)}
+	if c.config.Pool.CircuitBreaker != nil {
+		poolOpts = append(poolOpts, WithCircuitBreaker(*c.config.Pool.CircuitBreaker))
+	}
+
+	c.peerPool = NewPeerPool(c.ctx, c.logger, poolOpts...)
 	for _, mspConfig := range c.config.MSP {
 		for _, peerConfig := range mspConfig.Endorsers {
 
@@ -206,7 +347,7 @@ func (c *Client) initPeerPool() error {
 				return fmt.Errorf("initialize endorsers for MSP: %s: %w", mspConfig.Name, err)
 			}
 
-			if err = c.peerPool.Add(mspConfig.Name, p, StrategyGRPC(grpc.DefaultGrpcCheckPeriod)); err != nil {
+			if err = c.peerPool.Add(mspConfig.Name, p, c.peerCheckStrategyFor(mspConfig.Name)); err != nil {
 				return fmt.Errorf(`add peer to pool: %w`, err)
 			}
 		}
@@ -215,6 +356,30 @@ func (c *Client) initPeerPool() error {
 	return nil
 }
 
+// peerCheckStrategyFor returns check strategy for peer of MSP, added to pool
+func (c *Client) peerCheckStrategyFor(mspID string) api.PeerPoolCheckStrategy {
+	if c.peerCheckStrategy == nil {
+		return DefaultPeerCheckStrategy(mspID)
+	}
+	return c.peerCheckStrategy(mspID)
+}
+
+// ReplacePeer reconnects to MSP peer with new connection config (i.e. with renewed TLS certificate)
+// and replaces peer with the same uri in pool
+func (c *Client) ReplacePeer(mspID string, peerConfig config.ConnectionConfig) error {
+	p, err := NewPeer(c.ctx, peerConfig, c.defaultSigner, c.logger)
+	if err != nil {
+		return fmt.Errorf(`initialize peer for MSP: %s: %w`, mspID, err)
+	}
+
+	if err = c.peerPool.Replace(mspID, p, c.peerCheckStrategyFor(mspID)); err != nil {
+		_ = p.Close()
+		return fmt.Errorf(`replace peer in pool: %w`, err)
+	}
+
+	return nil
+}
+
 func (c *Client) CurrentIdentity() msp.SigningIdentity {
 	return c.defaultSigner
 }
@@ -244,54 +409,142 @@ func (c *Client) CurrentMspPeers() []api.Peer {
 func (c *Client) Channel(name string) api.Channel {
 	logger := c.logger.Named(`channel`).With(zap.String(`channel`, name))
 	c.channelMx.Lock()
-	defer c.channelMx.Unlock()
-
 	ch, ok := c.channels[name]
+	c.channelMx.Unlock()
 	if ok {
 		return ch
 	}
 
-	var ord api.Orderer
-
 	logger.Debug(`channel instance doesn't exist, initiating new`)
+	// channel orderers are discovered without lock, so other channels aren't blocked by discovery
+	ord, ordClose := c.channelOrderer(name, logger)
+
+	c.channelMx.Lock()
+	defer c.channelMx.Unlock()
+
+	// channel is initiated concurrently
+	if ch, ok = c.channels[name]; ok {
+		if ordClose != nil {
+			_ = ordClose.Close()
+		}
+		return ch
+	}
+
+	if !c.commitNotifiersSet {
+		c.commitNotifiers = deliver.NewCommitNotifiers(c.ctx, c.peerPool, c.defaultSigner)
+		c.commitNotifiersSet = true
+	}
+
+	opts := []ChannelOpt{
+		WithChannelPeerCheckStrategy(c.peerCheckStrategyFor),
+		WithChannelPeerAdder(c.addDiscoveredPeer),
+		WithChannelCommitNotifiers(c.commitNotifiers),
+		WithChannelQueryCache(c.queryCache),
+	}
+	if c.endorserVerification {
+		opts = append(opts, WithChannelEndorserVerification(c.crypto, c.endorserVerificationOptional))
+	}
+
+	ch = NewChannel(c.defaultSigner.GetMSPIdentifier(), name, c.peerPool, ord, c.discoveryProvider, c.defaultSigner, c.fabricV2, c.logger,
+		opts...)
+	c.channels[name] = ch
+	return ch
+}
+
+// channelOrderer returns orderer of channel: pool of discovered channel orderers or default orderer, which is
+// replaced with BFT orderer on the first request, if channel orderer is BFT. Closer is returned for orderer,
+// created for channel
+func (c *Client) channelOrderer(name string, logger *zap.Logger) (api.Orderer, io.Closer) {
+	var (
+		ord         = c.orderer
+		ordCloser   io.Closer
+		connections = c.ordererConnections()
+	)
+
+	if ordPool, discovered := c.channelOrderers(name, logger); ordPool != nil {
+		ord, ordCloser, connections = ordPool, ordPool, discovered
+	}
+
+	if ord == nil || len(connections) == 0 {
+		return ord, ordCloser
+	}
+
+	detecting := newDetectingOrderer(ord, ordCloser, func(ctx context.Context) (*BFTOrderer, error) {
+		return c.channelBFTOrderer(ctx, name, ord, connections[0])
+	}, logger)
+
+	return detecting, detecting
+}
+
+// channelBFTOrderer returns BFT orderer from consenters of channel config, fetched with orderer.
+// Nil is returned if channel orderer isn't BFT
+func (c *Client) channelBFTOrderer(
+	ctx context.Context, name string, ord api.Orderer, connection config.ConnectionConfig) (*BFTOrderer, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultChannelConfigTimeout)
+	defer cancel()
+
+	configBlock, err := ord.GetConfigBlock(ctx, c.defaultSigner, name)
+	if err != nil {
+		return nil, fmt.Errorf(`get channel config block: %w`, err)
+	}
+
+	channelConfig, err := block.ParseConfigBlock(configBlock)
+	if err != nil {
+		return nil, fmt.Errorf(`parse channel config block: %w`, err)
+	}
+
+	consenters, err := block.ParseOrdererConsenters(*channelConfig)
+	if err != nil || len(consenters) == 0 {
+		return nil, err
+	}
+
+	var opts []BFTOrdererOpt
+	if c.crypto != nil {
+		opts = append(opts, WithBFTCryptoSuite(c.crypto))
+	}
+
+	return NewBFTOrdererFromConfig(c.ctx, channelConfig, connection, c.logger, opts...)
+}
+
+// ordererConnections returns connections of orderers from config
+func (c *Client) ordererConnections() []config.ConnectionConfig {
+	if c.config == nil {
+		return nil
+	}
+	return c.config.Orderers
+}
+
+// channelOrderers returns pool of discovered channel orderers and their connections,
+// nil is returned if orderers aren't discovered, then default orderer is used
+func (c *Client) channelOrderers(name string, logger *zap.Logger) (*OrdererPool, []config.ConnectionConfig) {
 	discChannel, err := c.discoveryProvider.Channel(c.ctx, name)
 	if err != nil {
 		logger.Error(`Failed channel discovery. We'll use default orderer`, zap.Error(err))
-	} else {
-		// if custom orderers are enabled
-		if len(discChannel.Orderers()) > 0 {
-			// convert api.HostEndpoint-> grpc config.ConnectionConfig
-			var grpcConnCfgs []config.ConnectionConfig
-			orderers := discChannel.Orderers()
-
-			for _, orderer := range orderers {
-				if len(orderer.HostAddresses) > 0 {
-					for _, hostAddr := range orderer.HostAddresses {
-						grpcCfg := config.ConnectionConfig{
-							Host: hostAddr.Host,
-							Tls:  hostAddr.TlsConfig,
-						}
-						grpcConnCfgs = append(grpcConnCfgs, grpcCfg)
-					}
-				}
-			}
-			// we can have many orderers and here we establish connection with internal round-robin balancer
-			ordConn, err := grpc.ConnectionFromConfigs(c.ctx, c.logger, grpcConnCfgs...)
-			if err != nil {
-				logger.Error(`Failed to initialize custom GRPC connection for orderer`, zap.String(`channel`, name), zap.Error(err))
-			}
-			if ord, err = NewOrdererFromGRPC(ordConn); err != nil {
-				logger.Error(`Failed to construct orderer from GRPC connection`)
-			}
+		return nil, nil
+	}
+
+	// if custom orderers are enabled
+	if len(discChannel.Orderers()) == 0 {
+		return nil, nil
+	}
+
+	// convert api.HostEndpoint-> grpc config.ConnectionConfig
+	var grpcConnCfgs []config.ConnectionConfig
+	for _, orderer := range discChannel.Orderers() {
+		for _, hostAddr := range orderer.HostAddresses {
+			grpcConnCfgs = append(grpcConnCfgs, config.ConnectionConfig{
+				Host: hostAddr.Host,
+				Tls:  hostAddr.TlsConfig,
+			})
 		}
 	}
 
-	// using default orderer
-	if ord == nil {
-		ord = c.orderer
+	// we can have many orderers, pool fails over to the next one if orderer is unavailable
+	ordPool, err := NewOrdererPoolFromConfigs(c.ctx, c.logger, grpcConnCfgs, c.ordererPoolOpts()...)
+	if err != nil {
+		logger.Error(`Failed to initialize orderer pool`, zap.String(`channel`, name), zap.Error(err))
+		return nil, nil
 	}
 
-	ch = NewChannel(c.defaultSigner.GetMSPIdentifier(), name, c.peerPool, ord, c.discoveryProvider, c.defaultSigner, c.fabricV2, c.logger)
-	c.channels[name] = ch
-	return ch
+	return ordPool, grpcConnCfgs
 }
@@ -11,7 +11,7 @@ import (
 
 	"github.com/s7techlab/hlf-sdk-go/api"
 	"github.com/s7techlab/hlf-sdk-go/api/config"
-	"github.com/s7techlab/hlf-sdk-go/client/grpc"
+	"github.com/s7techlab/hlf-sdk-go/client/chaincode"
 	"github.com/s7techlab/hlf-sdk-go/crypto"
 )
 
@@ -48,6 +48,14 @@ func WithOrderer(orderer api.Orderer) Opt {
 	}
 }
 
+// WithGateway allows to send invokes and queries through Fabric Gateway service instead of peer pool and orderer
+func WithGateway(gateway api.Invoker) Opt {
+	return func(c *Client) error {
+		c.gateway = gateway
+		return nil
+	}
+}
+
 // WithConfigYaml allows passing path to YAML configuration file
 func WithConfigYaml(configPath string) Opt {
 	return func(c *Client) error {
@@ -97,7 +105,7 @@ func WithPeers(mspID string, peers []config.ConnectionConfig) Opt {
 			if err != nil {
 				return fmt.Errorf("create peer: %w", err)
 			}
-			err = c.peerPool.Add(mspID, pp, StrategyGRPC(grpc.DefaultGrpcCheckPeriod))
+			err = c.peerPool.Add(mspID, pp, c.peerCheckStrategyFor(mspID))
 			if err != nil {
 				return fmt.Errorf("add peer to pool: %w", err)
 			}
@@ -106,6 +114,34 @@ func WithPeers(mspID string, peers []config.ConnectionConfig) Opt {
 	}
 }
 
+// WithPeerCheckStrategy allows to set health check strategies for peers added to pool by Client.
+// Should be applied before WithPeers option
+func WithPeerCheckStrategy(provider PeerCheckStrategyProvider) Opt {
+	return func(c *Client) error {
+		c.peerCheckStrategy = provider
+		return nil
+	}
+}
+
+// WithCommitNotifiers allows to set shared commit notifiers, used by tx waiters.
+// By default, tx waiters use one block stream per channel and MSP, nil value enables subscription per transaction
+func WithCommitNotifiers(notifiers api.CommitNotifiers) Opt {
+	return func(c *Client) error {
+		c.commitNotifiers = notifiers
+		c.commitNotifiersSet = true
+		return nil
+	}
+}
+
+// WithQueryCache allows to cache chaincodes query responses of channels until new block is observed by cache,
+// i.e. with QueryCache.ObserveBlocks or QueryCache.WatchHeight
+func WithQueryCache(cache *chaincode.QueryCache) Opt {
+	return func(c *Client) error {
+		c.queryCache = cache
+		return nil
+	}
+}
+
 // WithCrypto allows to init Client crypto suite.
 func WithCrypto(crypto crypto.Suite) Opt {
 	return func(c *Client) error {
@@ -114,6 +150,25 @@ func WithCrypto(crypto crypto.Suite) Opt {
 	}
 }
 
+// WithEndorserVerification enables verification of endorsers signatures against channel config MSPs before
+// ordering. Channel config is fetched with cscc, invoke fails, if it can't be fetched
+func WithEndorserVerification() Opt {
+	return func(c *Client) error {
+		c.endorserVerification = true
+		return nil
+	}
+}
+
+// WithEndorserVerificationOptional enables verification of endorsers signatures, like WithEndorserVerification,
+// but skips it, if channel config can't be fetched
+func WithEndorserVerificationOptional() Opt {
+	return func(c *Client) error {
+		c.endorserVerification = true
+		c.endorserVerificationOptional = true
+		return nil
+	}
+}
+
 // WithFabricV2 toggles Client to use fabric version 2.
 func WithFabricV2(fabricV2 bool) Opt {
 	return func(c *Client) error {
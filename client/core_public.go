@@ -25,23 +25,23 @@ func (c *Client) Invoke(
 	transient map[string][]byte,
 	txWaiterType string,
 ) (*fabPeer.Response, string, error) {
-	var doOpts []api.DoOption
+	if c.gateway != nil {
+		return c.gateway.Invoke(ctx, channel, ccName, args, signer, transient, txWaiterType)
+	}
 
-	switch txWaiterType {
-	case "":
-		doOpts = append(doOpts, chaincode.WithTxWaiter(txwaiter.Self))
-	case api.TxWaiterSelfType:
-		doOpts = append(doOpts, chaincode.WithTxWaiter(txwaiter.Self))
-	case api.TxWaiterAllType:
-		doOpts = append(doOpts, chaincode.WithTxWaiter(txwaiter.All))
-	default:
-		return nil, "", fmt.Errorf("invalid tx waiter type. got %v, available: '%v', '%v'", txWaiterType, api.TxWaiterSelfType, api.TxWaiterAllType)
+	txWaiter, err := txwaiter.ByType(txWaiterType)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid tx waiter type: %w", err)
 	}
 
+	var doOpts []api.DoOption
 	if endorserMSPs := tx.EndorserMSPsFromContext(ctx); len(endorserMSPs) > 0 {
 		doOpts = append(doOpts, api.WithEndorsingMpsIDs(endorserMSPs))
 	}
 
+	// tx waiter is built after endorsing MSPs are set
+	doOpts = append(doOpts, chaincode.WithTxWaiter(chaincode.TxWaitBuilder(txWaiter)))
+
 	signer = tx.ChooseSigner(ctx, signer, c.CurrentIdentity())
 
 	ccAPI, err := c.Channel(channel).Chaincode(ctx, ccName)
@@ -69,6 +69,10 @@ func (c *Client) Query(
 	identity msp.SigningIdentity,
 	transient map[string][]byte,
 ) (*fabPeer.Response, error) {
+	if c.gateway != nil {
+		return c.gateway.Query(ctx, channel, chaincode, args, identity, transient)
+	}
+
 	if identity == nil {
 		identity = c.CurrentIdentity()
 	}
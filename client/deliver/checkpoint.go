@@ -0,0 +1,268 @@
+package deliver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	_ CheckpointStore = (*MemoryCheckpointStore)(nil)
+	_ CheckpointStore = (*FileCheckpointStore)(nil)
+	_ CheckpointStore = (*checkpointStoreFuncs)(nil)
+)
+
+// Checkpoint - position of the last processed item of subscription
+type Checkpoint struct {
+	// Block - number of the block of the last processed item
+	Block uint64 `json:"block"`
+	// TxIndex - index of the last processed transaction in block
+	TxIndex int `json:"tx_index"`
+}
+
+// After reports whether checkpoint position is later than other one
+func (c Checkpoint) After(other Checkpoint) bool {
+	return c.Block > other.Block || (c.Block == other.Block && c.TxIndex > other.TxIndex)
+}
+
+// CheckpointStore persists subscription checkpoints by key
+type CheckpointStore interface {
+	// Load returns saved checkpoint, nil checkpoint is returned if key has no checkpoint
+	Load(ctx context.Context, key string) (*Checkpoint, error)
+	Save(ctx context.Context, key string, checkpoint Checkpoint) error
+}
+
+// CheckpointKey returns checkpoint key of named subscription to channel
+func CheckpointKey(channel, name string) string {
+	return channel + `/` + name
+}
+
+// Checkpointer saves checkpoints of acknowledged positions of one subscription.
+// Delivered positions are tracked in delivery order, acknowledged position is saved only when all positions
+// delivered before it are acknowledged too, so out of order acknowledges neither lose unprocessed items
+// on restart nor move checkpoint back. Position, which wasn't marked as delivered, is saved if it's later
+// than the saved one, in this case acknowledges must be in order
+type Checkpointer struct {
+	store CheckpointStore
+	key   string
+
+	mu        sync.Mutex
+	saved     *Checkpoint
+	delivered []deliveredPosition
+}
+
+type deliveredPosition struct {
+	checkpoint Checkpoint
+	acked      bool
+}
+
+func NewCheckpointer(store CheckpointStore, key string) *Checkpointer {
+	return &Checkpointer{store: store, key: key}
+}
+
+// Load returns saved checkpoint, nil checkpoint is returned if there is no checkpoint
+func (c *Checkpointer) Load(ctx context.Context) (*Checkpoint, error) {
+	checkpoint, err := c.store.Load(ctx, c.key)
+	if err != nil {
+		return nil, fmt.Errorf(`load checkpoint: %w`, err)
+	}
+
+	c.mu.Lock()
+	c.saved = checkpoint
+	c.delivered = nil
+	c.mu.Unlock()
+
+	return checkpoint, nil
+}
+
+// Delivered marks position as delivered to subscriber and not acknowledged yet.
+// Position, which isn't later than the saved or the last delivered one, is ignored
+func (c *Checkpointer) Delivered(checkpoint Checkpoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.saved != nil && !checkpoint.After(*c.saved) {
+		return
+	}
+	if n := len(c.delivered); n > 0 && !checkpoint.After(c.delivered[n-1].checkpoint) {
+		return
+	}
+
+	c.delivered = append(c.delivered, deliveredPosition{checkpoint: checkpoint})
+}
+
+// Save acknowledges position and saves the latest position, all positions delivered before which are acknowledged
+func (c *Checkpointer) Save(ctx context.Context, checkpoint Checkpoint) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.saved != nil && !checkpoint.After(*c.saved) {
+		return nil
+	}
+
+	tracked := false
+	for i := range c.delivered {
+		if c.delivered[i].checkpoint == checkpoint {
+			c.delivered[i].acked = true
+			tracked = true
+			break
+		}
+	}
+
+	if !tracked {
+		return c.save(ctx, checkpoint)
+	}
+
+	acked := 0
+	for acked < len(c.delivered) && c.delivered[acked].acked {
+		acked++
+	}
+	if acked == 0 {
+		return nil
+	}
+
+	return c.save(ctx, c.delivered[acked-1].checkpoint)
+}
+
+func (c *Checkpointer) save(ctx context.Context, checkpoint Checkpoint) error {
+	if err := c.store.Save(ctx, c.key, checkpoint); err != nil {
+		return fmt.Errorf(`save checkpoint: %w`, err)
+	}
+
+	c.saved = &checkpoint
+	// positions delivered before saved one are not tracked anymore
+	for len(c.delivered) > 0 && !c.delivered[0].checkpoint.After(checkpoint) {
+		c.delivered = c.delivered[1:]
+	}
+	return nil
+}
+
+// MemoryCheckpointStore keeps checkpoints in memory, checkpoints survive resubscribing, but not restart
+type MemoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]Checkpoint
+}
+
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{
+		checkpoints: make(map[string]Checkpoint),
+	}
+}
+
+func (s *MemoryCheckpointStore) Load(_ context.Context, key string) (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	checkpoint, ok := s.checkpoints[key]
+	if !ok {
+		return nil, nil
+	}
+	return &checkpoint, nil
+}
+
+func (s *MemoryCheckpointStore) Save(_ context.Context, key string, checkpoint Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkpoints[key] = checkpoint
+	return nil
+}
+
+// FileCheckpointStore keeps checkpoints in directory, one JSON file per key.
+// File is replaced atomically, so checkpoint is never partially written
+type FileCheckpointStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileCheckpointStore creates checkpoints directory, if it doesn't exist
+func NewFileCheckpointStore(dir string) (*FileCheckpointStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf(`create checkpoints dir: %w`, err)
+	}
+
+	return &FileCheckpointStore{dir: dir}, nil
+}
+
+func (s *FileCheckpointStore) Load(_ context.Context, key string) (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(`read checkpoint: %w`, err)
+	}
+
+	checkpoint := new(Checkpoint)
+	if err = json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf(`unmarshal checkpoint: %w`, err)
+	}
+
+	return checkpoint, nil
+}
+
+func (s *FileCheckpointStore) Save(_ context.Context, key string, checkpoint Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf(`marshal checkpoint: %w`, err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, `.checkpoint-*`)
+	if err != nil {
+		return fmt.Errorf(`create checkpoint file: %w`, err)
+	}
+
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if cErr := tmp.Close(); err == nil {
+		err = cErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path(key))
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf(`write checkpoint: %w`, err)
+	}
+
+	return nil
+}
+
+func (s *FileCheckpointStore) path(key string) string {
+	return filepath.Join(s.dir, url.PathEscape(key)+`.json`)
+}
+
+type checkpointStoreFuncs struct {
+	load func(ctx context.Context, key string) (*Checkpoint, error)
+	save func(ctx context.Context, key string, checkpoint Checkpoint) error
+}
+
+// NewCheckpointStore creates checkpoint store from functions, i.e. to keep checkpoints in database
+// together with processing results
+func NewCheckpointStore(
+	load func(ctx context.Context, key string) (*Checkpoint, error),
+	save func(ctx context.Context, key string, checkpoint Checkpoint) error,
+) CheckpointStore {
+	return &checkpointStoreFuncs{load: load, save: save}
+}
+
+func (s *checkpointStoreFuncs) Load(ctx context.Context, key string) (*Checkpoint, error) {
+	return s.load(ctx, key)
+}
+
+func (s *checkpointStoreFuncs) Save(ctx context.Context, key string, checkpoint Checkpoint) error {
+	return s.save(ctx, key, checkpoint)
+}
@@ -0,0 +1,487 @@
+package deliver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/pkg/errors"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/block/txflags"
+)
+
+var (
+	_ api.CommitNotifier  = (*CommitNotifier)(nil)
+	_ api.CommitNotifiers = (*CommitNotifiers)(nil)
+)
+
+// DefaultCommitRetainBlocks - number of recent blocks, which transactions codes are kept for late waiters
+const DefaultCommitRetainBlocks = 100
+
+const (
+	DefaultCommitReconnectDelay    = 100 * time.Millisecond
+	DefaultCommitMaxReconnectDelay = 10 * time.Second
+)
+
+var ErrBlockStreamClosed = errors.New(`block stream closed`)
+
+// DeliverClientProvider returns deliver client, called on each (re)connect of channel block stream
+type DeliverClientProvider func() (api.DeliverClient, error)
+
+// LedgerHeightProvider returns channel ledger height, block stream is opened from it
+type LedgerHeightProvider func(ctx context.Context, channelName string) (uint64, error)
+
+// CommitNotifierOpt describes option which will be applied to CommitNotifier
+type CommitNotifierOpt func(n *CommitNotifier)
+
+// WithCommitRetainBlocks sets number of recent blocks, which transactions codes are kept
+// for waiters registered after transaction commit
+func WithCommitRetainBlocks(blocks int) CommitNotifierOpt {
+	return func(n *CommitNotifier) {
+		n.retainBlocks = blocks
+	}
+}
+
+// WithCommitLedgerHeight sets provider of ledger height, first block stream of channel is opened
+// from ledger height at waiter registration instead of the newest block
+func WithCommitLedgerHeight(provider LedgerHeightProvider) CommitNotifierOpt {
+	return func(n *CommitNotifier) {
+		n.ledgerHeight = provider
+	}
+}
+
+// WithCommitReconnectDelay sets initial and max delay between block stream reconnect attempts
+func WithCommitReconnectDelay(delay, maxDelay time.Duration) CommitNotifierOpt {
+	return func(n *CommitNotifier) {
+		n.reconnectDelay = delay
+		n.maxReconnectDelay = maxDelay
+	}
+}
+
+// CommitNotifier consumes one block stream per channel, indexes transactions validation codes
+// and resolves all pending waiters from it.
+// Block stream is opened on first registration from the ledger height (or the newest block) and is reopened
+// from the next block with backoff after failure, pending waiters are kept. Stream is stopped,
+// if it fails without pending waiters, and is opened again from the actual ledger height
+type CommitNotifier struct {
+	ctx               context.Context
+	deliver           DeliverClientProvider
+	ledgerHeight      LedgerHeightProvider
+	retainBlocks      int
+	reconnectDelay    time.Duration
+	maxReconnectDelay time.Duration
+
+	channels map[string]*channelCommits
+	mu       sync.Mutex
+}
+
+func NewCommitNotifier(ctx context.Context, deliver DeliverClientProvider, opts ...CommitNotifierOpt) *CommitNotifier {
+	n := &CommitNotifier{
+		ctx:               ctx,
+		deliver:           deliver,
+		retainBlocks:      DefaultCommitRetainBlocks,
+		reconnectDelay:    DefaultCommitReconnectDelay,
+		maxReconnectDelay: DefaultCommitMaxReconnectDelay,
+		channels:          make(map[string]*channelCommits),
+	}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	return n
+}
+
+type txCommit struct {
+	code peer.TxValidationCode
+	err  error
+}
+
+type channelCommits struct {
+	mu sync.Mutex
+	// running - block stream is consumed
+	running bool
+	// starting - closed, when block stream opening by one of registrations is finished
+	starting chan struct{}
+	// nextBlock - number of block, stream is reopened from, zero if stream position is unknown yet
+	nextBlock uint64
+	pending   map[string][]chan txCommit
+	committed map[string]peer.TxValidationCode
+	// blockTxs - tx ids of retained blocks in order of commit, used for eviction of old codes
+	blockTxs [][]string
+}
+
+// WaitTx - implementation of api.CommitNotifier interface
+func (n *CommitNotifier) WaitTx(ctx context.Context, channelName string, txID string) (peer.TxValidationCode, error) {
+	wait, err := n.Register(ctx, channelName, txID)
+	if err != nil {
+		return -1, err
+	}
+
+	return wait(ctx)
+}
+
+// Register - implementation of api.CommitNotifier interface, block stream is started on registration,
+// so transaction, registered before it's sent to orderer, can't be committed before stream position
+func (n *CommitNotifier) Register(ctx context.Context, channelName string, txID string) (api.TxCommitWait, error) {
+	ch := n.channel(channelName)
+
+	ch.mu.Lock()
+	if code, ok := ch.committed[txID]; ok {
+		ch.mu.Unlock()
+		return func(context.Context) (peer.TxValidationCode, error) {
+			return code, txError(txID, code)
+		}, nil
+	}
+
+	wait := make(chan txCommit, 1)
+	ch.pending[txID] = append(ch.pending[txID], wait)
+	ch.mu.Unlock()
+
+	if err := n.start(ctx, channelName, ch); err != nil {
+		ch.mu.Lock()
+		ch.removePending(txID, wait)
+		ch.mu.Unlock()
+		return nil, err
+	}
+
+	return func(ctx context.Context) (peer.TxValidationCode, error) {
+		select {
+		case commit := <-wait:
+			return commit.code, commit.err
+		case <-ctx.Done():
+			ch.mu.Lock()
+			ch.removePending(txID, wait)
+			ch.mu.Unlock()
+			return -1, ctx.Err()
+		}
+	}, nil
+}
+
+func (n *CommitNotifier) channel(channelName string) *channelCommits {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	ch, ok := n.channels[channelName]
+	if !ok {
+		ch = &channelCommits{
+			pending:   make(map[string][]chan txCommit),
+			committed: make(map[string]peer.TxValidationCode),
+		}
+		n.channels[channelName] = ch
+	}
+
+	return ch
+}
+
+// start opens channel block stream, if it isn't consumed yet. Stream is opened without channel lock,
+// so registrations of channel aren't blocked by slow peer, concurrent registrations wait for opening result
+func (n *CommitNotifier) start(ctx context.Context, channelName string, ch *channelCommits) error {
+	ch.mu.Lock()
+	for !ch.running {
+		if starting := ch.starting; starting != nil {
+			ch.mu.Unlock()
+			select {
+			case <-starting:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			ch.mu.Lock()
+			continue
+		}
+
+		starting := make(chan struct{})
+		ch.starting = starting
+		ch.mu.Unlock()
+
+		sub, fromBlock, err := n.open(ctx, channelName)
+
+		ch.mu.Lock()
+		ch.starting = nil
+		close(starting)
+		if err != nil {
+			ch.mu.Unlock()
+			return err
+		}
+
+		ch.running, ch.nextBlock = true, fromBlock
+		go n.consume(channelName, ch, sub)
+	}
+	ch.mu.Unlock()
+
+	return nil
+}
+
+// open opens block stream from ledger height, if ledger height provider is set, or from the newest block
+func (n *CommitNotifier) open(ctx context.Context, channelName string) (api.BlockSubscription, uint64, error) {
+	var fromBlock uint64
+	if n.ledgerHeight != nil {
+		height, err := n.ledgerHeight(ctx, channelName)
+		if err != nil {
+			return nil, 0, fmt.Errorf(`ledger height: %w`, err)
+		}
+		fromBlock = height
+	}
+
+	sub, err := n.subscribe(channelName, fromBlock)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return sub, fromBlock, nil
+}
+
+// subscribe opens block stream from block, from the newest block if it's zero
+func (n *CommitNotifier) subscribe(channelName string, fromBlock uint64) (api.BlockSubscription, error) {
+	deliver, err := n.deliver()
+	if err != nil {
+		return nil, fmt.Errorf(`deliver client: %w`, err)
+	}
+
+	seek := api.SeekNewest()
+	if fromBlock > 0 {
+		seek = api.SeekRange(fromBlock, math.MaxUint64)
+	}
+
+	sub, err := deliver.SubscribeBlock(n.ctx, channelName, seek)
+	if err != nil {
+		return nil, fmt.Errorf(`subscribe on blocks: %w`, err)
+	}
+
+	return sub, nil
+}
+
+func (n *CommitNotifier) consume(channelName string, ch *channelCommits, sub api.BlockSubscription) {
+	for {
+		n.consumeStream(ch, sub)
+		_ = sub.Close()
+
+		if sub = n.reconnect(channelName, ch); sub == nil {
+			return
+		}
+	}
+}
+
+// consumeStream handles stream blocks until stream is closed or notifier ctx is done
+func (n *CommitNotifier) consumeStream(ch *channelCommits, sub api.BlockSubscription) {
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+
+		case block, ok := <-sub.Blocks():
+			if !ok {
+				return
+			}
+
+			ch.handleBlock(block, n.retainBlocks)
+		}
+	}
+}
+
+// reconnect reopens stream from the next block with backoff, while there are pending waiters.
+// Nil subscription is returned, if stream is stopped
+func (n *CommitNotifier) reconnect(channelName string, ch *channelCommits) api.BlockSubscription {
+	delay := n.reconnectDelay
+	for {
+		if n.ctx.Err() != nil {
+			ch.stop(n.ctx.Err())
+			return nil
+		}
+
+		if ch.stopIfIdle() {
+			return nil
+		}
+
+		select {
+		case <-n.ctx.Done():
+			continue
+		case <-time.After(delay):
+		}
+
+		ch.mu.Lock()
+		fromBlock := ch.nextBlock
+		ch.mu.Unlock()
+
+		if sub, err := n.subscribe(channelName, fromBlock); err == nil {
+			return sub
+		}
+
+		if delay *= 2; delay > n.maxReconnectDelay {
+			delay = n.maxReconnectDelay
+		}
+	}
+}
+
+func (ch *channelCommits) handleBlock(block *common.Block, retainBlocks int) {
+	txs := blockTxCodes(block)
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.nextBlock = block.GetHeader().GetNumber() + 1
+
+	txIDs := make([]string, 0, len(txs))
+	for txID, code := range txs {
+		txIDs = append(txIDs, txID)
+		ch.committed[txID] = code
+
+		for _, wait := range ch.pending[txID] {
+			wait <- txCommit{code: code, err: txError(txID, code)}
+		}
+		delete(ch.pending, txID)
+	}
+
+	ch.blockTxs = append(ch.blockTxs, txIDs)
+	for len(ch.blockTxs) > retainBlocks {
+		for _, txID := range ch.blockTxs[0] {
+			delete(ch.committed, txID)
+		}
+		ch.blockTxs = ch.blockTxs[1:]
+	}
+}
+
+// stopIfIdle stops stream, if there are no pending waiters, stream will be reopened on next registration
+// from the actual ledger height, so blocks committed while stream is stopped aren't replayed
+func (ch *channelCommits) stopIfIdle() bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if len(ch.pending) > 0 {
+		return false
+	}
+
+	ch.running, ch.nextBlock = false, 0
+	return true
+}
+
+// stop fails pending waiters, i.e. if notifier is closed
+func (ch *channelCommits) stop(err error) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.running = false
+	for txID, waits := range ch.pending {
+		for _, wait := range waits {
+			wait <- txCommit{code: -1, err: fmt.Errorf(`%w: %s`, ErrBlockStreamClosed, err)}
+		}
+		delete(ch.pending, txID)
+	}
+}
+
+// removePending removes waiter, must be called under channel lock
+func (ch *channelCommits) removePending(txID string, wait chan txCommit) {
+	waits := ch.pending[txID]
+	for i := range waits {
+		if waits[i] == wait {
+			waits = append(waits[:i:i], waits[i+1:]...)
+			break
+		}
+	}
+
+	if len(waits) == 0 {
+		delete(ch.pending, txID)
+	} else {
+		ch.pending[txID] = waits
+	}
+}
+
+// blockTxCodes returns validation codes of block transactions, unparseable envelopes are skipped
+func blockTxCodes(block *common.Block) map[string]peer.TxValidationCode {
+	txFilter := txflags.ValidationFlags(
+		block.GetMetadata().GetMetadata()[common.BlockMetadataIndex_TRANSACTIONS_FILTER],
+	)
+
+	codes := make(map[string]peer.TxValidationCode)
+	for i, data := range block.GetData().GetData() {
+		env, err := protoutil.GetEnvelopeFromBlock(data)
+		if err != nil {
+			continue
+		}
+
+		payload, err := protoutil.UnmarshalPayload(env.Payload)
+		if err != nil {
+			continue
+		}
+
+		chHeader, err := protoutil.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+		if err != nil || chHeader.TxId == `` {
+			continue
+		}
+
+		// duplicate tx id is marked invalid, first occurrence is kept
+		if _, ok := codes[chHeader.TxId]; !ok {
+			codes[chHeader.TxId] = txFilter.Flag(i)
+		}
+	}
+
+	return codes
+}
+
+func txError(txID string, code peer.TxValidationCode) error {
+	if code == peer.TxValidationCode_VALID {
+		return nil
+	}
+	return api.InvalidTxError{TxId: txID, Code: code}
+}
+
+// CommitNotifiers - commit notifiers of MSPs, created on demand,
+// block streams of each MSP are opened on one of MSP peers from pool from its ledger height
+type CommitNotifiers struct {
+	ctx      context.Context
+	pool     api.PeerPool
+	identity msp.SigningIdentity
+	opts     []CommitNotifierOpt
+
+	notifiers map[string]*CommitNotifier
+	mu        sync.Mutex
+}
+
+func NewCommitNotifiers(
+	ctx context.Context, pool api.PeerPool, identity msp.SigningIdentity, opts ...CommitNotifierOpt) *CommitNotifiers {
+	return &CommitNotifiers{
+		ctx:       ctx,
+		pool:      pool,
+		identity:  identity,
+		opts:      opts,
+		notifiers: make(map[string]*CommitNotifier),
+	}
+}
+
+// CommitNotifier - implementation of api.CommitNotifiers interface
+func (n *CommitNotifiers) CommitNotifier(mspID string) (api.CommitNotifier, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	notifier, ok := n.notifiers[mspID]
+	if !ok {
+		opts := append([]CommitNotifierOpt{
+			WithCommitLedgerHeight(func(ctx context.Context, channelName string) (uint64, error) {
+				p, err := n.pool.FirstReadyPeer(mspID)
+				if err != nil {
+					return 0, err
+				}
+
+				chainInfo, err := p.GetChainInfo(ctx, channelName)
+				if err != nil {
+					return 0, err
+				}
+				return chainInfo.GetHeight(), nil
+			}),
+		}, n.opts...)
+
+		notifier = NewCommitNotifier(n.ctx, func() (api.DeliverClient, error) {
+			return n.pool.DeliverClient(mspID, n.identity)
+		}, opts...)
+		n.notifiers[mspID] = notifier
+	}
+
+	return notifier, nil
+}
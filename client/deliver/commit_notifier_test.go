@@ -0,0 +1,229 @@
+package deliver
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+)
+
+type blockStreamMock struct {
+	blocks chan *common.Block
+	errs   chan error
+}
+
+func (s *blockStreamMock) Blocks() <-chan *common.Block { return s.blocks }
+func (s *blockStreamMock) Errors() chan error           { return s.errs }
+func (s *blockStreamMock) Close() error                 { return nil }
+
+type blockDeliverMock struct {
+	api.DeliverClient
+
+	mu         sync.Mutex
+	subscribed int
+	seeks      []uint64
+	stream     *blockStreamMock
+}
+
+func (d *blockDeliverMock) SubscribeBlock(_ context.Context, _ string, seekOpt ...api.EventCCSeekOption) (api.BlockSubscription, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	from, _ := seekOpt[0]()
+
+	d.subscribed++
+	d.seeks = append(d.seeks, from.GetSpecified().GetNumber())
+	d.stream = &blockStreamMock{blocks: make(chan *common.Block), errs: make(chan error, 1)}
+	return d.stream, nil
+}
+
+func (d *blockDeliverMock) current() *blockStreamMock {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stream
+}
+
+func testBlock(t *testing.T, number uint64, txIDs []string, codes []peer.TxValidationCode) *common.Block {
+	block := protoutil.NewBlock(number, nil)
+	flags := make([]byte, len(txIDs))
+
+	for i, txID := range txIDs {
+		env := &common.Envelope{Payload: protoutil.MarshalOrPanic(&common.Payload{
+			Header: &common.Header{
+				ChannelHeader: protoutil.MarshalOrPanic(&common.ChannelHeader{TxId: txID}),
+			},
+		})}
+		block.Data.Data = append(block.Data.Data, protoutil.MarshalOrPanic(env))
+		flags[i] = byte(codes[i])
+	}
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = flags
+
+	require.NotNil(t, block)
+	return block
+}
+
+func waitTxAsync(n *CommitNotifier, txID string) <-chan error {
+	result := make(chan error, 1)
+	go func() {
+		_, err := n.WaitTx(context.Background(), `channel`, txID)
+		result <- err
+	}()
+	return result
+}
+
+func waitStream(t *testing.T, d *blockDeliverMock) *blockStreamMock {
+	require.Eventually(t, func() bool { return d.current() != nil }, time.Second, time.Millisecond)
+	return d.current()
+}
+
+func TestCommitNotifier(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	deliver := &blockDeliverMock{}
+	n := NewCommitNotifier(ctx, func() (api.DeliverClient, error) { return deliver, nil })
+
+	tx1, tx2 := waitTxAsync(n, `tx1`), waitTxAsync(n, `tx2`)
+	stream := waitStream(t, deliver)
+
+	stream.blocks <- testBlock(t, 10, []string{`tx1`, `tx2`, `tx3`},
+		[]peer.TxValidationCode{peer.TxValidationCode_VALID, peer.TxValidationCode_MVCC_READ_CONFLICT, peer.TxValidationCode_VALID})
+
+	assert.NoError(t, <-tx1)
+	tx2Err := <-tx2
+	assert.ErrorAs(t, tx2Err, &api.InvalidTxError{})
+	assert.ErrorIs(t, tx2Err, api.ErrMVCCConflict)
+
+	// tx3 was committed before waiter registration
+	code, err := n.WaitTx(ctx, `channel`, `tx3`)
+	assert.NoError(t, err)
+	assert.Equal(t, peer.TxValidationCode_VALID, code)
+
+	// all waiters use one stream
+	assert.Equal(t, 1, deliver.subscribed)
+}
+
+func TestCommitNotifierStreamFailure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	deliver := &blockDeliverMock{}
+	var height uint64 = 5
+	n := NewCommitNotifier(ctx, func() (api.DeliverClient, error) { return deliver, nil },
+		WithCommitRetainBlocks(1),
+		WithCommitReconnectDelay(time.Millisecond, time.Millisecond),
+		WithCommitLedgerHeight(func(context.Context, string) (uint64, error) { return atomic.LoadUint64(&height), nil }))
+
+	tx1 := waitTxAsync(n, `tx1`)
+	stream := waitStream(t, deliver)
+
+	stream.blocks <- testBlock(t, 5, []string{`tx0`}, []peer.TxValidationCode{peer.TxValidationCode_VALID})
+	stream.errs <- errors.New(`peer unavailable`)
+	close(stream.blocks)
+
+	// stream is reopened from the next block, pending waiter is kept
+	require.Eventually(t, func() bool { return deliver.current() != stream }, time.Second, time.Millisecond)
+	stream = deliver.current()
+	stream.blocks <- testBlock(t, 6, []string{`tx1`}, []peer.TxValidationCode{peer.TxValidationCode_VALID})
+	assert.NoError(t, <-tx1)
+
+	// stream without pending waiters is stopped after failure and reopened on next wait
+	close(stream.blocks)
+	ch := n.channel(`channel`)
+	require.Eventually(t, func() bool {
+		ch.mu.Lock()
+		defer ch.mu.Unlock()
+		return !ch.running
+	}, time.Second, time.Millisecond)
+
+	// blocks are committed while stream is stopped
+	atomic.StoreUint64(&height, 9)
+
+	tx2 := waitTxAsync(n, `tx2`)
+	require.Eventually(t, func() bool { return deliver.current() != stream }, time.Second, time.Millisecond)
+	deliver.current().blocks <- testBlock(t, 9, []string{`tx2`}, []peer.TxValidationCode{peer.TxValidationCode_VALID})
+	assert.NoError(t, <-tx2)
+
+	// stream is opened from ledger height, reopened after failure from the block next to the last received,
+	// and opened again from actual ledger height after stop, blocks committed while stopped aren't replayed
+	assert.Equal(t, []uint64{5, 6, 9}, deliver.seeks)
+
+	// tx0 block is evicted, only one block is retained
+	ch.mu.Lock()
+	_, ok := ch.committed[`tx0`]
+	ch.mu.Unlock()
+	assert.False(t, ok)
+}
+
+func TestCommitNotifierRegister(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	deliver := &blockDeliverMock{}
+	n := NewCommitNotifier(ctx, func() (api.DeliverClient, error) { return deliver, nil })
+
+	// transaction is registered before it's sent to orderer and committed before wait
+	wait, err := n.Register(ctx, `channel`, `tx1`)
+	require.NoError(t, err)
+
+	waitStream(t, deliver).blocks <- testBlock(t, 1, []string{`tx1`}, []peer.TxValidationCode{peer.TxValidationCode_VALID})
+
+	code, err := wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, peer.TxValidationCode_VALID, code)
+}
+
+func TestCommitNotifierClosed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	deliver := &blockDeliverMock{}
+	n := NewCommitNotifier(ctx, func() (api.DeliverClient, error) { return deliver, nil })
+
+	tx1 := waitTxAsync(n, `tx1`)
+	waitStream(t, deliver)
+	cancel()
+
+	assert.ErrorIs(t, <-tx1, ErrBlockStreamClosed)
+}
+
+func TestCommitNotifierStartWithoutChannelLock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	deliver := &blockDeliverMock{}
+	release := make(chan struct{})
+	n := NewCommitNotifier(ctx, func() (api.DeliverClient, error) { return deliver, nil },
+		WithCommitLedgerHeight(func(context.Context, string) (uint64, error) {
+			<-release
+			return 1, nil
+		}))
+
+	tx1 := waitTxAsync(n, `tx1`)
+	tx2 := waitTxAsync(n, `tx2`)
+
+	// waiters are registered, while ledger height of slow peer is requested
+	ch := n.channel(`channel`)
+	require.Eventually(t, func() bool {
+		ch.mu.Lock()
+		defer ch.mu.Unlock()
+		return len(ch.pending) == 2
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	waitStream(t, deliver).blocks <- testBlock(t, 1, []string{`tx1`, `tx2`},
+		[]peer.TxValidationCode{peer.TxValidationCode_VALID, peer.TxValidationCode_VALID})
+
+	assert.NoError(t, <-tx1)
+	assert.NoError(t, <-tx2)
+	assert.Equal(t, 1, deliver.subscribed)
+}
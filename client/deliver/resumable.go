@@ -0,0 +1,405 @@
+package deliver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/block"
+)
+
+const (
+	DefaultResumeRetryMax   = 10
+	DefaultResumeRetryDelay = time.Second
+)
+
+var (
+	ErrCheckpointStoreNotDefined = errors.New(`checkpoint store not defined`)
+
+	_ api.BlockSubscription = (*ResumableBlockSubscription)(nil)
+)
+
+type resumeOpts struct {
+	seek       api.EventCCSeekOption
+	retryMax   uint
+	retryDelay time.Duration
+	logger     *zap.Logger
+}
+
+// ResumeOpt describes option which will be applied to resumable subscription
+type ResumeOpt func(opts *resumeOpts)
+
+// WithResumeSeek sets blocks range of subscription. Range start is used only if there is no saved checkpoint,
+// default: api.SeekOldest
+func WithResumeSeek(seek api.EventCCSeekOption) ResumeOpt {
+	return func(opts *resumeOpts) {
+		opts.seek = seek
+	}
+}
+
+// WithResumeRetry sets max number of consecutive resubscribes after stream failure
+// and delay between them, default: DefaultResumeRetryMax, DefaultResumeRetryDelay
+func WithResumeRetry(max uint, delay time.Duration) ResumeOpt {
+	return func(opts *resumeOpts) {
+		opts.retryMax = max
+		opts.retryDelay = delay
+	}
+}
+
+// WithResumeLogger sets logger of resubscribes
+func WithResumeLogger(logger *zap.Logger) ResumeOpt {
+	return func(opts *resumeOpts) {
+		opts.logger = logger
+	}
+}
+
+// resumableSubscription resubscribes to channel blocks after stream failure from the block
+// next to the last handled one
+type resumableSubscription struct {
+	deliver api.DeliverClient
+	channel string
+	opts    *resumeOpts
+
+	errs   chan error
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newResumableSubscription(deliver api.DeliverClient, channel string, opts []ResumeOpt) *resumableSubscription {
+	options := &resumeOpts{
+		seek:       api.SeekOldest(),
+		retryMax:   DefaultResumeRetryMax,
+		retryDelay: DefaultResumeRetryDelay,
+		logger:     zap.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &resumableSubscription{
+		deliver: deliver,
+		channel: channel,
+		opts:    options,
+		errs:    make(chan error, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+func (s *resumableSubscription) Errors() chan error {
+	return s.errs
+}
+
+// Close stops subscription
+func (s *resumableSubscription) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// start runs subscription from block next, if resume is set, or from range start otherwise.
+// Handler error stops subscription, closeItems is called when subscription is finished
+func (s *resumableSubscription) start(
+	ctx context.Context, next uint64, resume bool, handle func(ctx context.Context, block *common.Block) error,
+	closeItems func()) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	go func() {
+		defer close(s.done)
+		defer closeItems()
+		defer s.cancel()
+
+		if err := s.run(ctx, next, resume, handle); err != nil {
+			s.errs <- err
+		}
+	}()
+}
+
+func (s *resumableSubscription) run(
+	ctx context.Context, next uint64, resume bool, handle func(ctx context.Context, block *common.Block) error) error {
+	_, seekTo := s.opts.seek()
+	var failures uint
+
+	for {
+		seek := s.opts.seek
+		if resume {
+			if to := seekTo.GetSpecified(); to != nil && next > to.Number {
+				return nil
+			}
+			seekFrom := block.NewSeekSpecified(next)
+			seek = func() (*orderer.SeekPosition, *orderer.SeekPosition) {
+				return seekFrom, seekTo
+			}
+		}
+
+		var blocksReceived bool
+		sub, err := s.deliver.SubscribeBlock(ctx, s.channel, seek)
+		if err == nil {
+			var handleErr error
+			blocksReceived, handleErr, err = s.receive(ctx, sub, &next, &resume, handle)
+			_ = sub.Close()
+
+			if handleErr != nil {
+				return handleErr
+			}
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// stream is finished after the last block of range
+		if to := seekTo.GetSpecified(); resume && to != nil && next > to.Number {
+			return nil
+		}
+
+		// stream, which delivered blocks, is not a consecutive failure
+		if blocksReceived {
+			failures = 0
+		}
+
+		if failures >= s.opts.retryMax {
+			return err
+		}
+
+		failures++
+		s.opts.logger.Warn(`block stream failed, resubscribe`,
+			zap.String(`channel`, s.channel), zap.Uint64(`from`, next), zap.Uint(`attempt`, failures), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(s.opts.retryDelay):
+		}
+	}
+}
+
+// receive handles stream blocks until stream is closed, next is updated with the number of block
+// next to the last handled one
+func (s *resumableSubscription) receive(
+	ctx context.Context, sub api.BlockSubscription, next *uint64, resume *bool,
+	handle func(ctx context.Context, block *common.Block) error) (blocksReceived bool, handleErr, err error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return blocksReceived, nil, ctx.Err()
+
+		case b, ok := <-sub.Blocks():
+			if !ok {
+				err = ErrBlockStreamClosed
+				select {
+				case streamErr, ok := <-sub.Errors():
+					if ok && streamErr != nil {
+						err = fmt.Errorf(`%w: %s`, ErrBlockStreamClosed, streamErr)
+					}
+				default:
+				}
+				return blocksReceived, nil, err
+			}
+
+			number := b.GetHeader().GetNumber()
+			// skip blocks, which are already handled
+			if *resume && number < *next {
+				continue
+			}
+
+			if handleErr = handle(ctx, b); handleErr != nil {
+				return blocksReceived, handleErr, nil
+			}
+
+			*next, *resume, blocksReceived = number+1, true, true
+		}
+	}
+}
+
+// ResumableBlockSubscription - blocks subscription, which is resumed after stream failure and restart
+// from the block next to the last acknowledged one.
+// Delivery is at-least-once: blocks, which are received, but not acknowledged before restart, are delivered again
+type ResumableBlockSubscription struct {
+	*resumableSubscription
+	checkpoints *Checkpointer
+	blocks      chan *common.Block
+}
+
+func (s *ResumableBlockSubscription) Blocks() <-chan *common.Block {
+	return s.blocks
+}
+
+// Ack saves checkpoint of processed block
+func (s *ResumableBlockSubscription) Ack(ctx context.Context, b *common.Block) error {
+	return s.checkpoints.Save(ctx, BlockCheckpoint(b))
+}
+
+// BlockCheckpoint returns checkpoint of entirely processed block
+func BlockCheckpoint(b *common.Block) Checkpoint {
+	return Checkpoint{
+		Block:   b.GetHeader().GetNumber(),
+		TxIndex: len(b.GetData().GetData()) - 1,
+	}
+}
+
+// ResumeBlocks subscribes to channel blocks from the block next to checkpoint of key
+// or from range start of WithResumeSeek, if there is no checkpoint
+func ResumeBlocks(
+	ctx context.Context, deliver api.DeliverClient, channel string, store CheckpointStore, key string, opts ...ResumeOpt) (
+	*ResumableBlockSubscription, error) {
+	if store == nil {
+		return nil, ErrCheckpointStoreNotDefined
+	}
+
+	checkpoints := NewCheckpointer(store, key)
+	checkpoint, err := checkpoints.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &ResumableBlockSubscription{
+		resumableSubscription: newResumableSubscription(deliver, channel, opts),
+		checkpoints:           checkpoints,
+		blocks:                make(chan *common.Block),
+	}
+
+	var next uint64
+	if checkpoint != nil {
+		next = checkpoint.Block + 1
+	}
+
+	sub.start(ctx, next, checkpoint != nil, func(ctx context.Context, b *common.Block) error {
+		sub.checkpoints.Delivered(BlockCheckpoint(b))
+		select {
+		case sub.blocks <- b:
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}, func() { close(sub.blocks) })
+
+	return sub, nil
+}
+
+// ResumableEvent - chaincode event with position of its transaction
+type ResumableEvent struct {
+	Event       *peer.ChaincodeEvent
+	Block       uint64
+	TxIndex     int
+	TxTimestamp *timestamp.Timestamp
+}
+
+// Checkpoint returns checkpoint of event transaction
+func (e *ResumableEvent) Checkpoint() Checkpoint {
+	return Checkpoint{Block: e.Block, TxIndex: e.TxIndex}
+}
+
+// ResumableEventSubscription - chaincode events subscription, which is resumed after stream failure and restart
+// from the transaction next to the last acknowledged one.
+// Delivery is at-least-once: events, which are received, but not acknowledged before restart, are delivered again.
+// Checkpoint granularity is transaction, so acknowledge of event marks all events of its transaction processed
+type ResumableEventSubscription struct {
+	*resumableSubscription
+	checkpoints *Checkpointer
+	chaincode   string
+	events      chan *ResumableEvent
+}
+
+func (s *ResumableEventSubscription) Events() <-chan *ResumableEvent {
+	return s.events
+}
+
+// Ack saves checkpoint of processed event
+func (s *ResumableEventSubscription) Ack(ctx context.Context, event *ResumableEvent) error {
+	return s.checkpoints.Save(ctx, event.Checkpoint())
+}
+
+// ResumeCC subscribes to chaincode events from the transaction next to checkpoint of key
+// or from range start of WithResumeSeek, if there is no checkpoint
+func ResumeCC(
+	ctx context.Context, deliver api.DeliverClient, channel, chaincode string, store CheckpointStore, key string,
+	opts ...ResumeOpt) (*ResumableEventSubscription, error) {
+	if store == nil {
+		return nil, ErrCheckpointStoreNotDefined
+	}
+
+	checkpoints := NewCheckpointer(store, key)
+	checkpoint, err := checkpoints.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &ResumableEventSubscription{
+		resumableSubscription: newResumableSubscription(deliver, channel, opts),
+		checkpoints:           checkpoints,
+		chaincode:             chaincode,
+		events:                make(chan *ResumableEvent),
+	}
+
+	// checkpoint block is delivered again, its events up to checkpoint transaction are skipped
+	var next uint64
+	if checkpoint != nil {
+		next = checkpoint.Block
+	}
+
+	sub.start(ctx, next, checkpoint != nil, func(ctx context.Context, b *common.Block) error {
+		return sub.handleBlock(ctx, b, checkpoint)
+	}, func() { close(sub.events) })
+
+	return sub, nil
+}
+
+func (s *ResumableEventSubscription) handleBlock(ctx context.Context, b *common.Block, from *Checkpoint) error {
+	parsedBlock, err := block.ParseBlock(b)
+	if err != nil {
+		return fmt.Errorf(`parse block=%d: %w`, b.GetHeader().GetNumber(), err)
+	}
+
+	for i, envelope := range parsedBlock.GetData().GetEnvelopes() {
+		event := &ResumableEvent{
+			Block:       b.GetHeader().GetNumber(),
+			TxIndex:     i,
+			TxTimestamp: envelope.GetPayload().GetHeader().GetChannelHeader().GetTimestamp(),
+		}
+
+		if envelope.ValidationCode != peer.TxValidationCode_VALID || envelope.GetPayload().GetTransaction() == nil ||
+			(from != nil && !event.Checkpoint().After(*from)) {
+			continue
+		}
+
+		for _, ev := range envelope.Payload.Transaction.Events() {
+			if ev.GetChaincodeId() != s.chaincode {
+				continue
+			}
+
+			txEvent := *event
+			txEvent.Event = ev
+
+			s.checkpoints.Delivered(txEvent.Checkpoint())
+			select {
+			case s.events <- &txEvent:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// SubscribeBlockResumable subscribes to channel blocks, which are resumed from checkpoint, see ResumeBlocks
+func (d *Deliver) SubscribeBlockResumable(
+	ctx context.Context, channelName string, store CheckpointStore, key string, opts ...ResumeOpt) (
+	*ResumableBlockSubscription, error) {
+	return ResumeBlocks(ctx, d, channelName, store, key, opts...)
+}
+
+// SubscribeCCResumable subscribes to chaincode events, which are resumed from checkpoint, see ResumeCC
+func (d *Deliver) SubscribeCCResumable(
+	ctx context.Context, channelName string, ccName string, store CheckpointStore, key string, opts ...ResumeOpt) (
+	*ResumableEventSubscription, error) {
+	return ResumeCC(ctx, d, channelName, ccName, store, key, opts...)
+}
@@ -0,0 +1,329 @@
+package deliver
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+)
+
+// ledgerDeliverMock delivers blocks of ledger from seek start, stream of subscription with index i
+// fails after failAfter[i] blocks
+type ledgerDeliverMock struct {
+	api.DeliverClient
+
+	ledger    []*common.Block
+	failAfter map[int]int
+
+	mu    sync.Mutex
+	seeks []uint64
+}
+
+func (d *ledgerDeliverMock) SubscribeBlock(
+	ctx context.Context, _ string, seekOpt ...api.EventCCSeekOption) (api.BlockSubscription, error) {
+	from, to := seekOpt[0]()
+
+	d.mu.Lock()
+	subscription := len(d.seeks)
+	d.seeks = append(d.seeks, from.GetSpecified().GetNumber())
+	failAfter, fails := d.failAfter[subscription]
+	d.mu.Unlock()
+
+	stream := &blockStreamMock{blocks: make(chan *common.Block), errs: make(chan error, 1)}
+	go func() {
+		defer close(stream.blocks)
+
+		sent := 0
+		for number := from.GetSpecified().GetNumber(); ; number++ {
+			if fails && sent == failAfter {
+				stream.errs <- errors.New(`peer unavailable`)
+				return
+			}
+
+			if number > to.GetSpecified().GetNumber() {
+				return
+			}
+
+			// peer waits for new blocks
+			if number >= uint64(len(d.ledger)) {
+				<-ctx.Done()
+				return
+			}
+
+			select {
+			case stream.blocks <- d.ledger[number]:
+				sent++
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return stream, nil
+}
+
+func (d *ledgerDeliverMock) subscribedFrom() []uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]uint64(nil), d.seeks...)
+}
+
+func testLedger(t *testing.T, height int) []*common.Block {
+	var ledger []*common.Block
+	for i := 0; i < height; i++ {
+		ledger = append(ledger, testBlock(t, uint64(i), []string{`tx`}, []peer.TxValidationCode{peer.TxValidationCode_VALID}))
+	}
+	return ledger
+}
+
+// testEventBlock returns block, each transaction of which emits event of chaincode, invalid transactions are marked
+func testEventBlock(t *testing.T, number uint64, events []string, invalid ...int) *common.Block {
+	block := protoutil.NewBlock(number, nil)
+	flags := make([]byte, len(events))
+
+	sigHeader := protoutil.MarshalOrPanic(&common.SignatureHeader{
+		Creator: protoutil.MarshalOrPanic(&msp.SerializedIdentity{Mspid: `Org1MSP`}),
+	})
+
+	for i, eventName := range events {
+		action := &peer.ChaincodeActionPayload{
+			ChaincodeProposalPayload: protoutil.MarshalOrPanic(&peer.ChaincodeProposalPayload{
+				Input: protoutil.MarshalOrPanic(&peer.ChaincodeInvocationSpec{}),
+			}),
+			Action: &peer.ChaincodeEndorsedAction{
+				ProposalResponsePayload: protoutil.MarshalOrPanic(&peer.ProposalResponsePayload{
+					Extension: protoutil.MarshalOrPanic(&peer.ChaincodeAction{
+						Events: protoutil.MarshalOrPanic(&peer.ChaincodeEvent{ChaincodeId: `cc`, EventName: eventName}),
+					}),
+				}),
+			},
+		}
+
+		env := &common.Envelope{Payload: protoutil.MarshalOrPanic(&common.Payload{
+			Header: &common.Header{
+				ChannelHeader: protoutil.MarshalOrPanic(&common.ChannelHeader{
+					Type: int32(common.HeaderType_ENDORSER_TRANSACTION),
+					TxId: eventName,
+				}),
+				SignatureHeader: sigHeader,
+			},
+			Data: protoutil.MarshalOrPanic(&peer.Transaction{
+				Actions: []*peer.TransactionAction{{Header: sigHeader, Payload: protoutil.MarshalOrPanic(action)}},
+			}),
+		})}
+
+		block.Data.Data = append(block.Data.Data, protoutil.MarshalOrPanic(env))
+		flags[i] = byte(peer.TxValidationCode_VALID)
+	}
+
+	for _, i := range invalid {
+		flags[i] = byte(peer.TxValidationCode_MVCC_READ_CONFLICT)
+	}
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = flags
+
+	require.NotNil(t, block)
+	return block
+}
+
+func receiveResumableBlocks(t *testing.T, sub *ResumableBlockSubscription, count int) []uint64 {
+	var numbers []uint64
+	for i := 0; i < count; i++ {
+		select {
+		case b, ok := <-sub.Blocks():
+			require.True(t, ok, `blocks channel closed`)
+			numbers = append(numbers, b.Header.Number)
+		case <-time.After(time.Second):
+			require.FailNow(t, `block is not received`)
+		}
+	}
+	return numbers
+}
+
+func TestResumeBlocksRestart(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCheckpointStore()
+	deliver := &ledgerDeliverMock{ledger: testLedger(t, 5)}
+
+	sub, err := ResumeBlocks(ctx, deliver, `channel`, store, `blocks`, WithResumeSeek(api.SeekRange(0, 4)))
+	require.NoError(t, err)
+
+	var blocks []*common.Block
+	for i := 0; i < 3; i++ {
+		blocks = append(blocks, <-sub.Blocks())
+	}
+	// block 2 is received, but not processed before restart
+	require.NoError(t, sub.Ack(ctx, blocks[0]))
+	require.NoError(t, sub.Ack(ctx, blocks[1]))
+	require.NoError(t, sub.Close())
+
+	checkpoint, err := store.Load(ctx, `blocks`)
+	require.NoError(t, err)
+	assert.Equal(t, &Checkpoint{Block: 1, TxIndex: 0}, checkpoint)
+
+	// at-least-once: not acknowledged block 2 is delivered again
+	sub, err = ResumeBlocks(ctx, deliver, `channel`, store, `blocks`, WithResumeSeek(api.SeekRange(0, 4)))
+	require.NoError(t, err)
+
+	assert.Equal(t, []uint64{2, 3, 4}, receiveResumableBlocks(t, sub, 3))
+
+	// range is delivered
+	_, ok := <-sub.Blocks()
+	assert.False(t, ok)
+	assert.Empty(t, sub.Errors())
+	assert.Equal(t, []uint64{0, 2}, deliver.subscribedFrom())
+}
+
+func TestResumeBlocksStreamFailure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	deliver := &ledgerDeliverMock{ledger: testLedger(t, 5), failAfter: map[int]int{0: 2, 1: 0}}
+
+	sub, err := ResumeBlocks(ctx, deliver, `channel`, NewMemoryCheckpointStore(), `blocks`,
+		WithResumeRetry(2, time.Millisecond))
+	require.NoError(t, err)
+	defer func() { _ = sub.Close() }()
+
+	// stream is reopened from the block next to the last received one, blocks aren't duplicated
+	assert.Equal(t, []uint64{0, 1, 2, 3, 4}, receiveResumableBlocks(t, sub, 5))
+	assert.Equal(t, []uint64{0, 2, 2}, deliver.subscribedFrom())
+}
+
+func TestResumeBlocksRetryExceeded(t *testing.T) {
+	ctx := context.Background()
+	deliver := &ledgerDeliverMock{ledger: testLedger(t, 5), failAfter: map[int]int{0: 0, 1: 0, 2: 0}}
+
+	sub, err := ResumeBlocks(ctx, deliver, `channel`, NewMemoryCheckpointStore(), `blocks`,
+		WithResumeRetry(2, time.Millisecond))
+	require.NoError(t, err)
+
+	_, ok := <-sub.Blocks()
+	assert.False(t, ok)
+	assert.ErrorIs(t, <-sub.Errors(), ErrBlockStreamClosed)
+	assert.Len(t, deliver.subscribedFrom(), 3)
+}
+
+func TestResumeCCRestart(t *testing.T) {
+	ctx := context.Background()
+
+	var saved []Checkpoint
+	memory := NewMemoryCheckpointStore()
+	store := NewCheckpointStore(memory.Load, func(ctx context.Context, key string, checkpoint Checkpoint) error {
+		saved = append(saved, checkpoint)
+		return memory.Save(ctx, key, checkpoint)
+	})
+
+	deliver := &ledgerDeliverMock{ledger: []*common.Block{
+		testEventBlock(t, 0, nil),
+		testEventBlock(t, 1, []string{`e1`, `e2`, `e3`}, 1),
+		testEventBlock(t, 2, []string{`e4`}),
+	}}
+
+	sub, err := ResumeCC(ctx, deliver, `channel`, `cc`, store, `events`, WithResumeSeek(api.SeekRange(0, 2)))
+	require.NoError(t, err)
+
+	e1 := <-sub.Events()
+	assert.Equal(t, `e1`, e1.Event.EventName)
+	assert.Equal(t, Checkpoint{Block: 1, TxIndex: 0}, e1.Checkpoint())
+	require.NoError(t, sub.Ack(ctx, e1))
+
+	// event of invalid transaction is skipped
+	e3 := <-sub.Events()
+	assert.Equal(t, `e3`, e3.Event.EventName)
+	require.NoError(t, sub.Close())
+
+	// acknowledge of earlier event doesn't move checkpoint back
+	require.NoError(t, sub.Ack(ctx, &ResumableEvent{Block: 0, TxIndex: 5}))
+	assert.Equal(t, []Checkpoint{{Block: 1, TxIndex: 0}}, saved)
+
+	// subscription is resumed from checkpoint transaction block, not acknowledged e3 is delivered again
+	sub, err = ResumeCC(ctx, deliver, `channel`, `cc`, store, `events`, WithResumeSeek(api.SeekRange(0, 2)))
+	require.NoError(t, err)
+
+	var names []string
+	for event := range sub.Events() {
+		names = append(names, event.Event.EventName)
+		require.NoError(t, sub.Ack(ctx, event))
+	}
+
+	assert.Equal(t, []string{`e3`, `e4`}, names)
+	assert.Equal(t, []uint64{0, 1}, deliver.subscribedFrom())
+
+	checkpoint, err := store.Load(ctx, `events`)
+	require.NoError(t, err)
+	assert.Equal(t, &Checkpoint{Block: 2, TxIndex: 0}, checkpoint)
+}
+
+func TestResumeNoCheckpointStore(t *testing.T) {
+	_, err := ResumeBlocks(context.Background(), &ledgerDeliverMock{}, `channel`, nil, `blocks`)
+	assert.ErrorIs(t, err, ErrCheckpointStoreNotDefined)
+}
+
+func TestFileCheckpointStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	store, err := NewFileCheckpointStore(dir)
+	require.NoError(t, err)
+
+	key := CheckpointKey(`channel`, `events`)
+	checkpoint, err := store.Load(ctx, key)
+	require.NoError(t, err)
+	assert.Nil(t, checkpoint)
+
+	require.NoError(t, store.Save(ctx, key, Checkpoint{Block: 10, TxIndex: 2}))
+	require.NoError(t, store.Save(ctx, key, Checkpoint{Block: 11, TxIndex: 0}))
+
+	// checkpoint survives restart
+	store, err = NewFileCheckpointStore(dir)
+	require.NoError(t, err)
+
+	checkpoint, err = store.Load(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, &Checkpoint{Block: 11, TxIndex: 0}, checkpoint)
+
+	checkpoint, err = store.Load(ctx, CheckpointKey(`channel`, `blocks`))
+	require.NoError(t, err)
+	assert.Nil(t, checkpoint)
+}
+
+func TestCheckpointerOutOfOrderAck(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCheckpointStore()
+	checkpoints := NewCheckpointer(store, `blocks`)
+
+	for block := uint64(1); block <= 3; block++ {
+		checkpoints.Delivered(Checkpoint{Block: block})
+	}
+
+	load := func() *Checkpoint {
+		checkpoint, err := store.Load(ctx, `blocks`)
+		require.NoError(t, err)
+		return checkpoint
+	}
+
+	// block 1 isn't acknowledged yet, checkpoint isn't moved
+	require.NoError(t, checkpoints.Save(ctx, Checkpoint{Block: 2}))
+	assert.Nil(t, load())
+
+	// all blocks up to 2 are acknowledged
+	require.NoError(t, checkpoints.Save(ctx, Checkpoint{Block: 1}))
+	assert.Equal(t, &Checkpoint{Block: 2}, load())
+
+	require.NoError(t, checkpoints.Save(ctx, Checkpoint{Block: 3}))
+	assert.Equal(t, &Checkpoint{Block: 3}, load())
+
+	// acknowledge of earlier block doesn't move checkpoint back
+	require.NoError(t, checkpoints.Save(ctx, Checkpoint{Block: 1}))
+	assert.Equal(t, &Checkpoint{Block: 3}, load())
+}
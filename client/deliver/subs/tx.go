@@ -6,6 +6,7 @@ import (
 	"github.com/hyperledger/fabric/protoutil"
 	"github.com/pkg/errors"
 
+	"github.com/s7techlab/hlf-sdk-go/api"
 	"github.com/s7techlab/hlf-sdk-go/block/txflags"
 )
 
@@ -91,8 +92,7 @@ func (ts *TxSubscription) Handler(block *common.Block) bool {
 				ts.result <- &result{code: txFilter.Flag(i), err: nil}
 				return true
 			} else {
-				err = errors.Errorf("TxId validation code failed: %s", peer.TxValidationCode_name[int32(txFilter.Flag(i))])
-				ts.result <- &result{code: txFilter.Flag(i), err: err}
+				ts.result <- &result{code: txFilter.Flag(i), err: api.InvalidTxError{TxId: ts.txId, Code: txFilter.Flag(i)}}
 				return true
 			}
 		}
@@ -0,0 +1,315 @@
+package discovery
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/api/config"
+)
+
+// implementation of api.DiscoveryProvider interface
+var (
+	_ api.DiscoveryProvider                = (*CachingProvider)(nil)
+	_ api.InvocationChainDiscoveryProvider = (*CachingProvider)(nil)
+)
+
+const (
+	DefaultCacheTTL = time.Minute
+	// DefaultCacheFetchTimeout - timeout of discovery request, shared by concurrent lookups of the same key
+	DefaultCacheFetchTimeout = 30 * time.Second
+)
+
+const (
+	cacheKeyLocalPeers = `local`
+	cacheKeyChannel    = `channel`
+	cacheKeyChaincode  = `chaincode`
+	cacheKeySeparator  = `/`
+)
+
+// Invalidator - discovery provider with cached results, which can be dropped
+// i.e. after endorsement policy failure
+type Invalidator interface {
+	InvalidateChaincode(channelName, ccName string)
+	InvalidateChannel(channelName string)
+	InvalidateAll()
+}
+
+// CachingProvider caches discovery provider results until TTL expires.
+// Concurrent lookups of the same key are de-duplicated, errors are not cached.
+// Shared discovery request isn't canceled with context of lookup, which started it
+type CachingProvider struct {
+	provider api.DiscoveryProvider
+	now      func() time.Time
+
+	ttl          time.Duration
+	fetchTimeout time.Duration
+	channelTTL   map[string]time.Duration
+	chaincodeTTL map[string]time.Duration
+
+	entries map[string]cacheEntry
+	// generations - key invalidations count, result of request started before invalidation isn't cached
+	generations map[string]uint64
+	mu          sync.Mutex
+	group       singleflight.Group
+}
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// CacheOpt describes option which will be applied to CachingProvider
+type CacheOpt func(p *CachingProvider)
+
+// WithCacheTTL sets default TTL of cached results
+func WithCacheTTL(ttl time.Duration) CacheOpt {
+	return func(p *CachingProvider) {
+		p.ttl = ttl
+	}
+}
+
+// WithCacheFetchTimeout sets timeout of discovery request, shared by concurrent lookups of the same key
+func WithCacheFetchTimeout(timeout time.Duration) CacheOpt {
+	return func(p *CachingProvider) {
+		p.fetchTimeout = timeout
+	}
+}
+
+// WithChannelCacheTTL sets TTL of channel and its chaincodes discovery results
+func WithChannelCacheTTL(channelName string, ttl time.Duration) CacheOpt {
+	return func(p *CachingProvider) {
+		p.channelTTL[channelName] = ttl
+	}
+}
+
+// WithChaincodeCacheTTL sets TTL of chaincode discovery results
+func WithChaincodeCacheTTL(channelName, ccName string, ttl time.Duration) CacheOpt {
+	return func(p *CachingProvider) {
+		p.chaincodeTTL[chaincodeCacheKey(channelName, ccName)] = ttl
+	}
+}
+
+// CacheOptsFromConfig returns cache options from discovery cache config
+func CacheOptsFromConfig(c config.DiscoveryCacheConfig) []CacheOpt {
+	var opts []CacheOpt
+	if c.TTL.Duration > 0 {
+		opts = append(opts, WithCacheTTL(c.TTL.Duration))
+	}
+
+	for _, ch := range c.Channels {
+		if ch.TTL.Duration > 0 {
+			opts = append(opts, WithChannelCacheTTL(ch.Name, ch.TTL.Duration))
+		}
+
+		for _, cc := range ch.Chaincodes {
+			if cc.TTL.Duration > 0 {
+				opts = append(opts, WithChaincodeCacheTTL(ch.Name, cc.Name, cc.TTL.Duration))
+			}
+		}
+	}
+
+	return opts
+}
+
+func NewCachingProvider(provider api.DiscoveryProvider, opts ...CacheOpt) *CachingProvider {
+	p := &CachingProvider{
+		provider:     provider,
+		now:          time.Now,
+		ttl:          DefaultCacheTTL,
+		fetchTimeout: DefaultCacheFetchTimeout,
+		channelTTL:   make(map[string]time.Duration),
+		chaincodeTTL: make(map[string]time.Duration),
+		entries:      make(map[string]cacheEntry),
+		generations:  make(map[string]uint64),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+func (p *CachingProvider) Chaincode(ctx context.Context, channelName string, ccName string) (api.ChaincodeDiscoverer, error) {
+	key := chaincodeCacheKey(channelName, ccName)
+
+	ttl, ok := p.chaincodeTTL[key]
+	if !ok {
+		ttl = p.channelCacheTTL(channelName)
+	}
+
+	value, err := p.get(ctx, key, ttl, func(ctx context.Context) (interface{}, error) {
+		return p.provider.Chaincode(ctx, channelName, ccName)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(api.ChaincodeDiscoverer), nil
+}
+
+// InvocationChain caches invocation chain discovery result with TTL of the first chaincode in chain
+func (p *CachingProvider) InvocationChain(
+	ctx context.Context, channelName string, chain api.InvocationChain) (api.ChaincodeDiscoverer, error) {
+	if len(chain) == 0 {
+		return nil, ErrEmptyInvocationChain
+	}
+
+	if chain.Simple() {
+		return p.Chaincode(ctx, channelName, chain[0].Name)
+	}
+
+	chainProvider, ok := p.provider.(api.InvocationChainDiscoveryProvider)
+	if !ok {
+		return nil, ErrInvocationChainNotSupported
+	}
+
+	ttl, ok := p.chaincodeTTL[chaincodeCacheKey(channelName, chain[0].Name)]
+	if !ok {
+		ttl = p.channelCacheTTL(channelName)
+	}
+
+	// chain key contains chaincode names, so chain results are dropped on chaincode invalidation
+	value, err := p.get(ctx, chaincodeCacheKey(channelName, chain.Key()), ttl, func(ctx context.Context) (interface{}, error) {
+		return chainProvider.InvocationChain(ctx, channelName, chain)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(api.ChaincodeDiscoverer), nil
+}
+
+func (p *CachingProvider) Channel(ctx context.Context, channelName string) (api.ChannelDiscoverer, error) {
+	value, err := p.get(ctx, channelCacheKey(channelName), p.channelCacheTTL(channelName),
+		func(ctx context.Context) (interface{}, error) {
+			return p.provider.Channel(ctx, channelName)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(api.ChannelDiscoverer), nil
+}
+
+func (p *CachingProvider) LocalPeers(ctx context.Context) (api.LocalPeersDiscoverer, error) {
+	value, err := p.get(ctx, cacheKeyLocalPeers, p.ttl, func(ctx context.Context) (interface{}, error) {
+		return p.provider.LocalPeers(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(api.LocalPeersDiscoverer), nil
+}
+
+// InvalidateChaincode drops cached chaincode discovery result and results of invocation chains with chaincode
+func (p *CachingProvider) InvalidateChaincode(channelName, ccName string) {
+	chaincodesPrefix := chaincodeCacheKey(channelName, ``)
+	p.invalidate(func(key string) bool {
+		if !strings.HasPrefix(key, chaincodesPrefix) {
+			return false
+		}
+
+		for _, call := range strings.Split(strings.TrimPrefix(key, chaincodesPrefix), `,`) {
+			if call == ccName || strings.HasPrefix(call, ccName+`(`) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// InvalidateChannel drops cached channel and its chaincodes discovery results
+func (p *CachingProvider) InvalidateChannel(channelName string) {
+	chaincodesPrefix := chaincodeCacheKey(channelName, ``)
+	p.invalidate(func(key string) bool {
+		return key == channelCacheKey(channelName) || strings.HasPrefix(key, chaincodesPrefix)
+	})
+}
+
+// InvalidateAll drops all cached discovery results
+func (p *CachingProvider) InvalidateAll() {
+	p.invalidate(func(string) bool {
+		return true
+	})
+}
+
+func (p *CachingProvider) invalidate(match func(key string) bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key := range p.entries {
+		if match(key) {
+			delete(p.entries, key)
+		}
+	}
+
+	// requests in flight are not cached and not shared with lookups after invalidation
+	for key := range p.generations {
+		if match(key) {
+			p.generations[key]++
+		}
+	}
+}
+
+func (p *CachingProvider) get(ctx context.Context, key string, ttl time.Duration,
+	fetch func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	p.mu.Lock()
+	entry, ok := p.entries[key]
+	generation, tracked := p.generations[key]
+	if !tracked {
+		p.generations[key] = 0
+	}
+	p.mu.Unlock()
+
+	if ok && p.now().Before(entry.expires) {
+		return entry.value, nil
+	}
+
+	result := p.group.DoChan(key+`#`+strconv.FormatUint(generation, 10), func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), p.fetchTimeout)
+		defer cancel()
+
+		value, err := fetch(fetchCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		p.mu.Lock()
+		if p.generations[key] == generation {
+			p.entries[key] = cacheEntry{value: value, expires: p.now().Add(ttl)}
+		}
+		p.mu.Unlock()
+
+		return value, nil
+	})
+
+	select {
+	case res := <-result:
+		return res.Val, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p *CachingProvider) channelCacheTTL(channelName string) time.Duration {
+	if ttl, ok := p.channelTTL[channelName]; ok {
+		return ttl
+	}
+	return p.ttl
+}
+
+func channelCacheKey(channelName string) string {
+	return cacheKeyChannel + cacheKeySeparator + channelName
+}
+
+func chaincodeCacheKey(channelName, ccName string) string {
+	return cacheKeyChaincode + cacheKeySeparator + channelName + cacheKeySeparator + ccName
+}
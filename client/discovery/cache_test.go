@@ -0,0 +1,160 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+)
+
+type countingProviderMock struct {
+	api.DiscoveryProvider
+
+	calls   int64
+	err     error
+	release chan struct{}
+}
+
+func (p *countingProviderMock) Chaincode(_ context.Context, channelName, ccName string) (api.ChaincodeDiscoverer, error) {
+	atomic.AddInt64(&p.calls, 1)
+	if p.release != nil {
+		<-p.release
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &chaincodeDTO{channelName: channelName, chaincodeName: ccName}, nil
+}
+
+func (p *countingProviderMock) Calls() int64 {
+	return atomic.LoadInt64(&p.calls)
+}
+
+func TestCachingProviderTTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	provider := &countingProviderMock{}
+
+	cache := NewCachingProvider(provider,
+		WithCacheTTL(time.Minute),
+		WithChaincodeCacheTTL(`channel`, `short`, time.Second))
+	cache.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		_, err := cache.Chaincode(ctx, `channel`, `cc`)
+		require.NoError(t, err)
+		_, err = cache.Chaincode(ctx, `channel`, `short`)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 2, provider.Calls())
+
+	now = now.Add(2 * time.Second)
+	_, _ = cache.Chaincode(ctx, `channel`, `cc`)
+	_, _ = cache.Chaincode(ctx, `channel`, `short`)
+	assert.EqualValues(t, 3, provider.Calls())
+
+	cache.InvalidateChannel(`channel`)
+	_, _ = cache.Chaincode(ctx, `channel`, `cc`)
+	assert.EqualValues(t, 4, provider.Calls())
+
+	cache.InvalidateChaincode(`channel`, `cc`)
+	_, _ = cache.Chaincode(ctx, `channel`, `cc`)
+	assert.EqualValues(t, 5, provider.Calls())
+}
+
+func TestCachingProviderErrorsAreNotCached(t *testing.T) {
+	ctx := context.Background()
+	provider := &countingProviderMock{err: errors.New(`discovery failed`)}
+	cache := NewCachingProvider(provider)
+
+	_, err := cache.Chaincode(ctx, `channel`, `cc`)
+	assert.Error(t, err)
+
+	provider.err = nil
+	_, err = cache.Chaincode(ctx, `channel`, `cc`)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, provider.Calls())
+}
+
+func TestCachingProviderSingleFlight(t *testing.T) {
+	ctx := context.Background()
+	provider := &countingProviderMock{release: make(chan struct{})}
+	cache := NewCachingProvider(provider)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.Chaincode(ctx, `channel`, `cc`)
+			assert.NoError(t, err)
+		}()
+	}
+
+	assert.Eventually(t, func() bool { return provider.Calls() == 1 }, time.Second, 10*time.Millisecond)
+	// let concurrent lookups join the first one
+	time.Sleep(50 * time.Millisecond)
+	close(provider.release)
+	wg.Wait()
+
+	assert.EqualValues(t, 1, provider.Calls())
+}
+
+func TestCachingProviderFetchIsNotCanceledByCaller(t *testing.T) {
+	provider := &countingProviderMock{release: make(chan struct{})}
+	cache := NewCachingProvider(provider)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() {
+		_, err := cache.Chaincode(ctx, `channel`, `cc`)
+		errs <- err
+	}()
+	assert.Eventually(t, func() bool { return provider.Calls() == 1 }, time.Second, 10*time.Millisecond)
+
+	waiterErrs := make(chan error)
+	go func() {
+		_, err := cache.Chaincode(context.Background(), `channel`, `cc`)
+		waiterErrs <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// caller, which started request, cancels lookup, other waiter receives request result
+	cancel()
+	assert.ErrorIs(t, <-errs, context.Canceled)
+
+	close(provider.release)
+	assert.NoError(t, <-waiterErrs)
+	assert.EqualValues(t, 1, provider.Calls())
+}
+
+func TestCachingProviderInvalidateDuringFetch(t *testing.T) {
+	ctx := context.Background()
+	provider := &countingProviderMock{release: make(chan struct{})}
+	cache := NewCachingProvider(provider)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := cache.Chaincode(ctx, `channel`, `cc`)
+		assert.NoError(t, err)
+	}()
+	assert.Eventually(t, func() bool { return provider.Calls() == 1 }, time.Second, 10*time.Millisecond)
+
+	// result of request, started before invalidation, isn't cached
+	cache.InvalidateChaincode(`channel`, `cc`)
+	close(provider.release)
+	<-done
+
+	_, err := cache.Chaincode(ctx, `channel`, `cc`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, provider.Calls())
+}
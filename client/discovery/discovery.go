@@ -5,10 +5,13 @@ import (
 )
 
 var (
-	ErrNoChannels      = errors.New(`channels not found`)
-	ErrChannelNotFound = errors.New(`channel not found`)
-	ErrNoChaincodes    = errors.New(`no chaincodes on channel`)
-	ErrUnknownProvider = errors.New(`unknown discovery provider (forgotten import?)`)
+	ErrNoChannels                  = errors.New(`channels not found`)
+	ErrChannelNotFound             = errors.New(`channel not found`)
+	ErrNoChaincodes                = errors.New(`no chaincodes on channel`)
+	ErrUnknownProvider             = errors.New(`unknown discovery provider (forgotten import?)`)
+	ErrGossipUnavailable           = errors.New(`gossip discovery unavailable`)
+	ErrEmptyInvocationChain        = errors.New(`empty invocation chain`)
+	ErrInvocationChainNotSupported = errors.New(`invocation chain discovery not supported by provider`)
 )
 
 // ServiceDiscoveryType - what types of discovery we support
@@ -17,4 +20,6 @@ type ServiceDiscoveryType string
 const (
 	LocalConfigServiceDiscoveryType ServiceDiscoveryType = "local"
 	GossipServiceDiscoveryType      ServiceDiscoveryType = "gossip"
+	// HybridServiceDiscoveryType - gossip discovery with fallback to local config
+	HybridServiceDiscoveryType ServiceDiscoveryType = "hybrid"
 )
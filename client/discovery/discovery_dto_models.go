@@ -7,15 +7,21 @@ import (
 )
 
 // implementation of api.ChaincodeDiscoverer interface
-var _ api.ChaincodeDiscoverer = (*chaincodeDTO)(nil)
+var (
+	_ api.ChaincodeDiscoverer        = (*chaincodeDTO)(nil)
+	_ api.LayoutsEndorsersDiscoverer = (*chaincodeDTO)(nil)
+)
 
 // chaincodeDTO - chaincode data storage
 type chaincodeDTO struct {
 	lock sync.RWMutex
 	// key - MSPID, value host addresses
-	endorsers        map[string][]string
+	endorsers map[string][]string
+	// layoutsEndorsers - endorsers of all endorsement layouts, if they differ from endorsers
+	layoutsEndorsers map[string][]string
 	orderers         map[string][]string
 	peers            map[string][]string
+	layouts          []api.EndorsementLayout
 	chaincodeName    string
 	chaincodeVersion string
 	channelName      string
@@ -28,6 +34,7 @@ func newChaincodeDTO(ccName, ccVer, chanName string) *chaincodeDTO {
 		chaincodeVersion: ccVer,
 		channelName:      chanName,
 		endorsers:        make(map[string][]string),
+		layoutsEndorsers: make(map[string][]string),
 		orderers:         make(map[string][]string),
 		peers:            make(map[string][]string),
 	}
@@ -39,12 +46,28 @@ func (d *chaincodeDTO) Endorsers() []*api.HostEndpoint {
 	return mapToArray(d.endorsers)
 }
 
+// LayoutsEndorsers returns endorsers of all endorsement layouts
+func (d *chaincodeDTO) LayoutsEndorsers() []*api.HostEndpoint {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	if len(d.layoutsEndorsers) == 0 {
+		return mapToArray(d.endorsers)
+	}
+	return mapToArray(d.layoutsEndorsers)
+}
+
 func (d *chaincodeDTO) Orderers() []*api.HostEndpoint {
 	d.lock.RLock()
 	defer d.lock.RUnlock()
 	return mapToArray(d.orderers)
 }
 
+func (d *chaincodeDTO) EndorsementLayouts() []api.EndorsementLayout {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.layouts
+}
+
 func (d *chaincodeDTO) ChaincodeName() string {
 	return d.chaincodeName
 }
@@ -64,6 +87,18 @@ func (d *chaincodeDTO) addEndpointToEndorsers(mspID, hostAddr string) {
 	d.endorsers[mspID] = append(d.endorsers[mspID], hostAddr)
 }
 
+func (d *chaincodeDTO) addEndpointToLayoutsEndorsers(mspID, hostAddr string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.layoutsEndorsers[mspID] = append(d.layoutsEndorsers[mspID], hostAddr)
+}
+
+func (d *chaincodeDTO) setEndorsementLayouts(layouts []api.EndorsementLayout) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.layouts = layouts
+}
+
 func (d *chaincodeDTO) addEndpointToOrderers(mspID, hostAddr string) {
 	d.lock.Lock()
 	defer d.lock.Unlock()
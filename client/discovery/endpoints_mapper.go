@@ -101,10 +101,22 @@ func (d *chaincodeDiscovererTLSDecorator) Endorsers() []*api.HostEndpoint {
 	return addTLConfigs(d.target.Endorsers(), d.tlsMapper)
 }
 
+// LayoutsEndorsers returns endorsers of all endorsement layouts, if target exposes them
+func (d *chaincodeDiscovererTLSDecorator) LayoutsEndorsers() []*api.HostEndpoint {
+	if target, ok := d.target.(api.LayoutsEndorsersDiscoverer); ok {
+		return addTLConfigs(target.LayoutsEndorsers(), d.tlsMapper)
+	}
+	return d.Endorsers()
+}
+
 func (d *chaincodeDiscovererTLSDecorator) Orderers() []*api.HostEndpoint {
 	return addTLConfigs(d.target.Orderers(), d.tlsMapper)
 }
 
+func (d *chaincodeDiscovererTLSDecorator) EndorsementLayouts() []api.EndorsementLayout {
+	return d.target.EndorsementLayouts()
+}
+
 func (d *chaincodeDiscovererTLSDecorator) ChaincodeVersion() string {
 	return d.target.ChaincodeVersion()
 }
@@ -14,7 +14,10 @@ import (
 )
 
 // implementation of api.DiscoveryProvider interface
-var _ api.DiscoveryProvider = (*GossipDiscoveryProvider)(nil)
+var (
+	_ api.DiscoveryProvider                = (*GossipDiscoveryProvider)(nil)
+	_ api.InvocationChainDiscoveryProvider = (*GossipDiscoveryProvider)(nil)
+)
 
 type GossipDiscoveryProvider struct {
 	sd        *gossipServiceDiscovery
@@ -58,6 +61,9 @@ func newFabricDiscoveryClient(
 		return nil, err
 	}
 
+	// capture raw discovery responses for parsing endorsement layouts
+	dialOpts.Dial = append(dialOpts.Dial, grpc.WithChainUnaryInterceptor(captureResponseInterceptor))
+
 	conn, err := grpc.DialContext(ctx, c.Host, dialOpts.Dial...)
 	if err != nil {
 		return nil, fmt.Errorf(`grpc dial to host=%s: %w`, c.Host, err)
@@ -83,6 +89,16 @@ func (d *GossipDiscoveryProvider) Chaincode(ctx context.Context, channelName str
 	return newChaincodeDiscovererTLSDecorator(ccDTO, d.tlsMapper), nil
 }
 
+func (d *GossipDiscoveryProvider) InvocationChain(
+	ctx context.Context, channelName string, chain api.InvocationChain) (api.ChaincodeDiscoverer, error) {
+	ccDTO, err := d.sd.DiscoverInvocationChain(ctx, channelName, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	return newChaincodeDiscovererTLSDecorator(ccDTO, d.tlsMapper), nil
+}
+
 func (d *GossipDiscoveryProvider) Channel(ctx context.Context, channelName string) (api.ChannelDiscoverer, error) {
 	chanDTO, err := d.sd.DiscoverChannel(ctx, channelName)
 	if err != nil {
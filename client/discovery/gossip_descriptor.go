@@ -0,0 +1,190 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/discovery"
+	"github.com/hyperledger/fabric-protos-go/gossip"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"google.golang.org/grpc"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/client/policy"
+)
+
+// fabric discovery client doesn't expose endorsement layouts of response,
+// so raw response is captured by grpc interceptor into holder, passed with request context
+type responseHolder struct {
+	response *discovery.Response
+}
+
+type responseHolderKey struct{}
+
+func withResponseHolder(ctx context.Context) (context.Context, *responseHolder) {
+	holder := &responseHolder{}
+	return context.WithValue(ctx, responseHolderKey{}, holder), holder
+}
+
+func captureResponseInterceptor(
+	ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if err != nil {
+		return err
+	}
+
+	if holder, ok := ctx.Value(responseHolderKey{}).(*responseHolder); ok {
+		if response, ok := reply.(*discovery.Response); ok {
+			holder.response = response
+		}
+	}
+
+	return nil
+}
+
+// endorsementDescriptor returns descriptor of the first chaincode query result in response
+func endorsementDescriptor(response *discovery.Response) (*discovery.EndorsementDescriptor, error) {
+	for _, result := range response.GetResults() {
+		if e := result.GetError(); e != nil {
+			return nil, fmt.Errorf(`discovery error: %s`, e.Content)
+		}
+
+		if ccResult := result.GetCcQueryRes(); ccResult != nil && len(ccResult.Content) > 0 {
+			return ccResult.Content[0], nil
+		}
+	}
+
+	return nil, ErrNoChaincodes
+}
+
+// descriptorEndorsers - endorsers and layouts, parsed from discovery endorsement descriptor
+type descriptorEndorsers struct {
+	// endorsers - endorsers of the first (minimal) layout, msp id -> peers endpoints
+	endorsers map[string][]string
+	// groupEndorsers - endorsers of all groups, msp id -> peers endpoints
+	groupEndorsers map[string][]string
+	layouts        []api.EndorsementLayout
+	ccVersion      string
+}
+
+// parseEndorsementDescriptor returns endorsers of the first layout, endorsers of all groups and layouts,
+// converted to MSP IDs. Layouts, which require several endorsements from one MSP, are skipped,
+// as only one peer of each MSP is used for endorsement
+func parseEndorsementDescriptor(desc *discovery.EndorsementDescriptor) (*descriptorEndorsers, error) {
+	parsed := &descriptorEndorsers{
+		endorsers:      make(map[string][]string),
+		groupEndorsers: make(map[string][]string),
+	}
+	groupMSP := make(map[string]string, len(desc.EndorsersByGroups))
+
+	for group, peers := range desc.EndorsersByGroups {
+		for _, p := range peers.GetPeers() {
+			mspID, err := peerMSPID(p)
+			if err != nil {
+				return nil, fmt.Errorf(`group=%s: %w`, group, err)
+			}
+			groupMSP[group] = mspID
+
+			endpoint, err := peerEndpoint(p)
+			if err != nil {
+				return nil, fmt.Errorf(`group=%s: %w`, group, err)
+			}
+			parsed.groupEndorsers[mspID] = append(parsed.groupEndorsers[mspID], endpoint)
+
+			if parsed.ccVersion == `` {
+				parsed.ccVersion = peerChaincodeVersion(p, desc.Chaincode)
+			}
+		}
+	}
+
+	seen := make(map[string]struct{})
+	for _, layout := range desc.Layouts {
+		var (
+			mspIDs   []string
+			skip     bool
+			usedMSPs = make(map[string]struct{})
+		)
+
+		for group, quantity := range layout.QuantitiesByGroup {
+			mspID, ok := groupMSP[group]
+			if _, used := usedMSPs[mspID]; !ok || used || quantity > 1 {
+				skip = true
+				break
+			}
+
+			usedMSPs[mspID] = struct{}{}
+			mspIDs = append(mspIDs, mspID)
+		}
+
+		if skip {
+			continue
+		}
+
+		sort.Strings(mspIDs)
+		key := strings.Join(mspIDs, `,`)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		parsed.layouts = append(parsed.layouts, mspIDs)
+	}
+	parsed.layouts = policy.SortLayouts(parsed.layouts)
+
+	// without supported layouts endorsements are collected from all groups
+	if len(parsed.layouts) == 0 {
+		parsed.endorsers = parsed.groupEndorsers
+		return parsed, nil
+	}
+
+	for _, mspID := range parsed.layouts[0] {
+		parsed.endorsers[mspID] = parsed.groupEndorsers[mspID]
+	}
+
+	return parsed, nil
+}
+
+func peerMSPID(p *discovery.Peer) (string, error) {
+	var identity msp.SerializedIdentity
+	if err := proto.Unmarshal(p.Identity, &identity); err != nil {
+		return ``, fmt.Errorf(`unmarshal peer identity: %w`, err)
+	}
+	return identity.Mspid, nil
+}
+
+func peerEndpoint(p *discovery.Peer) (string, error) {
+	msg, err := gossipMessage(p.MembershipInfo)
+	if err != nil {
+		return ``, fmt.Errorf(`peer membership info: %w`, err)
+	}
+	return msg.GetAliveMsg().GetMembership().GetEndpoint(), nil
+}
+
+func peerChaincodeVersion(p *discovery.Peer, ccName string) string {
+	msg, err := gossipMessage(p.StateInfo)
+	if err != nil {
+		return ``
+	}
+
+	for _, cc := range msg.GetStateInfo().GetProperties().GetChaincodes() {
+		if cc.Name == ccName {
+			return cc.Version
+		}
+	}
+	return ``
+}
+
+func gossipMessage(env *gossip.Envelope) (*gossip.GossipMessage, error) {
+	msg := &gossip.GossipMessage{}
+	if env == nil {
+		return msg, nil
+	}
+
+	if err := proto.Unmarshal(env.Payload, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
@@ -0,0 +1,85 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/discovery"
+	"github.com/hyperledger/fabric-protos-go/gossip"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+)
+
+func discoveryPeer(t *testing.T, mspID, endpoint string) *discovery.Peer {
+	identity, err := proto.Marshal(&msp.SerializedIdentity{Mspid: mspID})
+	require.NoError(t, err)
+
+	membership, err := proto.Marshal(&gossip.GossipMessage{
+		Content: &gossip.GossipMessage_AliveMsg{
+			AliveMsg: &gossip.AliveMessage{Membership: &gossip.Member{Endpoint: endpoint}},
+		},
+	})
+	require.NoError(t, err)
+
+	stateInfo, err := proto.Marshal(&gossip.GossipMessage{
+		Content: &gossip.GossipMessage_StateInfo{
+			StateInfo: &gossip.StateInfo{Properties: &gossip.Properties{
+				Chaincodes: []*gossip.Chaincode{{Name: `cc`, Version: `1.0`}},
+			}},
+		},
+	})
+	require.NoError(t, err)
+
+	return &discovery.Peer{
+		Identity:       identity,
+		MembershipInfo: &gossip.Envelope{Payload: membership},
+		StateInfo:      &gossip.Envelope{Payload: stateInfo},
+	}
+}
+
+func TestParseEndorsementDescriptor(t *testing.T) {
+	desc := &discovery.EndorsementDescriptor{
+		Chaincode: `cc`,
+		EndorsersByGroups: map[string]*discovery.Peers{
+			`G0`: {Peers: []*discovery.Peer{discoveryPeer(t, `Org1MSP`, `peer0.org1:7051`)}},
+			`G1`: {Peers: []*discovery.Peer{discoveryPeer(t, `Org2MSP`, `peer0.org2:7051`)}},
+			`G2`: {Peers: []*discovery.Peer{
+				discoveryPeer(t, `Org3MSP`, `peer0.org3:7051`),
+				discoveryPeer(t, `Org3MSP`, `peer1.org3:7051`),
+			}},
+		},
+		Layouts: []*discovery.Layout{
+			{QuantitiesByGroup: map[string]uint32{`G1`: 1, `G0`: 1}},
+			{QuantitiesByGroup: map[string]uint32{`G2`: 1}},
+			// two endorsements from one MSP are not supported
+			{QuantitiesByGroup: map[string]uint32{`G2`: 2}},
+		},
+	}
+
+	parsed, err := parseEndorsementDescriptor(desc)
+	require.NoError(t, err)
+
+	assert.Equal(t, []api.EndorsementLayout{{`Org3MSP`}, {`Org1MSP`, `Org2MSP`}}, parsed.layouts)
+	// endorsers of the first layout
+	assert.Equal(t, map[string][]string{
+		`Org3MSP`: {`peer0.org3:7051`, `peer1.org3:7051`},
+	}, parsed.endorsers)
+	assert.Equal(t, map[string][]string{
+		`Org1MSP`: {`peer0.org1:7051`},
+		`Org2MSP`: {`peer0.org2:7051`},
+		`Org3MSP`: {`peer0.org3:7051`, `peer1.org3:7051`},
+	}, parsed.groupEndorsers)
+	assert.Equal(t, `1.0`, parsed.ccVersion)
+}
+
+func TestEndorsementDescriptorError(t *testing.T) {
+	_, err := endorsementDescriptor(&discovery.Response{
+		Results: []*discovery.QueryResult{{
+			Result: &discovery.QueryResult_Error{Error: &discovery.Error{Content: `access denied`}},
+		}},
+	})
+	assert.EqualError(t, err, `discovery error: access denied`)
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/hyperledger/fabric-protos-go/discovery"
 	"github.com/hyperledger/fabric-protos-go/peer"
 	discClient "github.com/hyperledger/fabric/discovery/client"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
 )
 
 // gossipServiceDiscovery - fetches info about all available peers, endorsers and orderers for channel & chaincode
@@ -26,35 +28,39 @@ func newGossipServiceDiscovery(client *discClient.Client, clientIdentity []byte)
 
 // DiscoverChaincode - find available peers, endorsers and orderers for channel & chaincode
 func (s *gossipServiceDiscovery) DiscoverChaincode(ctx context.Context, ccName, chanName string) (*chaincodeDTO, error) {
+	return s.DiscoverInvocationChain(ctx, chanName, api.InvocationChain{{Name: ccName}})
+}
+
+// DiscoverInvocationChain - find available peers, orderers and endorsers for channel & chaincodes invocation chain,
+// endorsers satisfy endorsement policies of all chaincodes and collections in chain
+func (s *gossipServiceDiscovery) DiscoverInvocationChain(
+	ctx context.Context, chanName string, chain api.InvocationChain) (*chaincodeDTO, error) {
+	if len(chain) == 0 {
+		return nil, ErrEmptyInvocationChain
+	}
+
+	ccCalls := make(discClient.InvocationChain, 0, len(chain))
+	for _, call := range chain {
+		ccCalls = append(ccCalls, &peer.ChaincodeCall{Name: call.Name, CollectionNames: call.Collections})
+	}
+
 	// TODO err handling/diversification
 	req, err := discClient.
 		NewRequest().
 		OfChannel(chanName).
 		AddPeersQuery().
 		AddConfigQuery().
-		AddEndorsersQuery(&peer.ChaincodeInterest{
-			Chaincodes: []*peer.ChaincodeCall{
-				{Name: ccName},
-			},
-		})
+		AddEndorsersQuery(&peer.ChaincodeInterest{Chaincodes: ccCalls})
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, holder := withResponseHolder(ctx)
 	res, err := s.client.Send(ctx, req, s.getAuthInfo())
 	if err != nil {
 		return nil, err
 	}
 
-	chanEndorsers, err := res.ForChannel(chanName).Endorsers(discClient.InvocationChain{
-		&peer.ChaincodeCall{
-			Name: ccName,
-		},
-	}, discClient.NoFilter)
-	if err != nil {
-		return nil, err
-	}
-
 	chanPeers, err := res.ForChannel(chanName).Peers()
 	if err != nil {
 		return nil, err
@@ -64,17 +70,49 @@ func (s *gossipServiceDiscovery) DiscoverChaincode(ctx context.Context, ccName,
 		return nil, err
 	}
 
-	// get chaincode version
-	var ccVersion string
-	if len(chanEndorsers) != 0 {
-		ccCodes := chanEndorsers[0].StateInfoMessage.GossipMessage.GetStateInfo().Properties.Chaincodes
-		if len(ccCodes) != 0 {
-			ccVersion = ccCodes[0].Version
+	// fabric discovery client returns endorsers of one random layout,
+	// so endorsers of all layouts are taken from raw response
+	var endorsers *descriptorEndorsers
+	if holder.response != nil {
+		desc, err := endorsementDescriptor(holder.response)
+		if err != nil {
+			return nil, err
+		}
+
+		if endorsers, err = parseEndorsementDescriptor(desc); err != nil {
+			return nil, fmt.Errorf(`parse endorsement descriptor: %w`, err)
+		}
+	} else {
+		chanEndorsers, err := res.ForChannel(chanName).Endorsers(ccCalls, discClient.NoFilter)
+		if err != nil {
+			return nil, err
+		}
+		endorsers = endorsersFromPeers(chanEndorsers, chain[0].Name)
+	}
+
+	dc := newChaincodeDTO(chain[0].Name, endorsers.ccVersion, chanName)
+	dc.setEndorsementLayouts(endorsers.layouts)
+	return s.parseDiscoverChaincodeResponse(dc, endorsers, chanPeers, chanCfg), nil
+}
+
+// endorsersFromPeers returns endorsers, selected by fabric discovery client, without layouts
+func endorsersFromPeers(peers discClient.Endorsers, ccName string) *descriptorEndorsers {
+	endorsers := &descriptorEndorsers{endorsers: make(map[string][]string)}
+
+	for i := range peers {
+		hostAddr := peers[i].AliveMessage.GetAliveMsg().Membership.Endpoint
+		endorsers.endorsers[peers[i].MSPID] = append(endorsers.endorsers[peers[i].MSPID], hostAddr)
+
+		if endorsers.ccVersion == `` && peers[i].StateInfoMessage != nil {
+			for _, cc := range peers[i].StateInfoMessage.GetStateInfo().GetProperties().GetChaincodes() {
+				if cc.Name == ccName {
+					endorsers.ccVersion = cc.Version
+				}
+			}
 		}
 	}
 
-	dc := newChaincodeDTO(ccName, ccVersion, chanName)
-	return s.parseDiscoverChaincodeResponse(dc, chanEndorsers, chanPeers, chanCfg), nil
+	return endorsers
 }
 
 // DiscoverChannel - returns orderers for provided channel
@@ -121,13 +159,20 @@ func (s *gossipServiceDiscovery) LocalDiscovery(ctx context.Context) (*localPeer
 
 func (s *gossipServiceDiscovery) parseDiscoverChaincodeResponse(
 	dc *chaincodeDTO,
-	endorsers discClient.Endorsers,
+	endorsers *descriptorEndorsers,
 	peers []*discClient.Peer,
 	cfg *discovery.ConfigResult,
 ) *chaincodeDTO {
-	for i := range endorsers {
-		hostAddr := endorsers[i].AliveMessage.GetAliveMsg().Membership.Endpoint
-		dc.addEndpointToEndorsers(endorsers[i].MSPID, hostAddr)
+	for mspID, hostAddrs := range endorsers.endorsers {
+		for _, hostAddr := range hostAddrs {
+			dc.addEndpointToEndorsers(mspID, hostAddr)
+		}
+	}
+
+	for mspID, hostAddrs := range endorsers.groupEndorsers {
+		for _, hostAddr := range hostAddrs {
+			dc.addEndpointToLayoutsEndorsers(mspID, hostAddr)
+		}
 	}
 
 	for i := range peers {
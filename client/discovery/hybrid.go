@@ -0,0 +1,218 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/api/config"
+)
+
+// implementation of api.DiscoveryProvider interface
+var (
+	_ api.DiscoveryProvider                = (*HybridProvider)(nil)
+	_ api.InvocationChainDiscoveryProvider = (*HybridProvider)(nil)
+)
+
+const (
+	DefaultGossipRetryDelay    = 5 * time.Second
+	DefaultGossipMaxRetryDelay = 5 * time.Minute
+)
+
+// GossipFactory creates gossip discovery provider, i.e. connects to discovery peer
+type GossipFactory func() (api.DiscoveryProvider, error)
+
+// HybridProvider uses gossip discovery and falls back to local config if gossip discovery fails.
+// Chaincodes, pinned in local config, are always discovered from local config
+type HybridProvider struct {
+	local  *LocalConfigProvider
+	logger *zap.Logger
+
+	// gossipFactory - if set and gossip is unavailable, gossip provider creation is retried in background
+	gossipFactory GossipFactory
+	retryDelay    time.Duration
+	maxRetryDelay time.Duration
+	now           func() time.Time
+
+	gossipMx    sync.Mutex
+	gossip      api.DiscoveryProvider
+	connecting  bool
+	nextAttempt time.Time
+	attempts    int
+}
+
+// HybridOpt describes option which will be applied to HybridProvider
+type HybridOpt func(d *HybridProvider)
+
+// WithGossipFactory sets gossip provider factory. If gossip provider is not available, its creation is retried
+// lazily on discovery requests with exponential backoff, requests are served from local config meanwhile
+func WithGossipFactory(factory GossipFactory) HybridOpt {
+	return func(d *HybridProvider) {
+		d.gossipFactory = factory
+	}
+}
+
+// WithGossipRetryDelay sets initial and max delay between gossip provider creation attempts
+func WithGossipRetryDelay(delay, maxDelay time.Duration) HybridOpt {
+	return func(d *HybridProvider) {
+		d.retryDelay = delay
+		d.maxRetryDelay = maxDelay
+	}
+}
+
+// NewHybridProvider creates hybrid discovery provider, gossip can be nil if discovery peer is unreachable,
+// in this case local config is used until gossip provider is created with factory
+func NewHybridProvider(
+	gossip api.DiscoveryProvider,
+	localOptions config.DiscoveryConfigOpts,
+	tlsMapper connectionMapper,
+	log *zap.Logger,
+	opts ...HybridOpt,
+) (*HybridProvider, error) {
+	local, err := newLocalConfigProvider(localOptions, tlsMapper)
+	if err != nil {
+		return nil, fmt.Errorf(`local discovery provider: %w`, err)
+	}
+
+	d := &HybridProvider{
+		gossip:        gossip,
+		local:         local,
+		logger:        log.Named(`hybrid-discovery`),
+		retryDelay:    DefaultGossipRetryDelay,
+		maxRetryDelay: DefaultGossipMaxRetryDelay,
+		now:           time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d, nil
+}
+
+// gossipProvider returns gossip provider, if it's available. Otherwise, provider creation is started
+// in background, if factory is set and backoff delay is passed, and nil is returned
+func (d *HybridProvider) gossipProvider() api.DiscoveryProvider {
+	d.gossipMx.Lock()
+	defer d.gossipMx.Unlock()
+
+	if d.gossip != nil || d.gossipFactory == nil || d.connecting || d.now().Before(d.nextAttempt) {
+		return d.gossip
+	}
+
+	d.connecting = true
+	go d.connectGossip()
+
+	return nil
+}
+
+func (d *HybridProvider) connectGossip() {
+	gossip, err := d.gossipFactory()
+
+	d.gossipMx.Lock()
+	defer d.gossipMx.Unlock()
+
+	d.connecting = false
+	if err != nil {
+		delay := d.retryDelay << d.attempts
+		if delay <= 0 || delay > d.maxRetryDelay {
+			delay = d.maxRetryDelay
+		} else {
+			d.attempts++
+		}
+		d.nextAttempt = d.now().Add(delay)
+
+		d.logger.Warn(`gossip discovery unavailable, using local config`,
+			zap.Duration(`retry_after`, delay), zap.Error(err))
+		return
+	}
+
+	d.logger.Info(`gossip discovery available`)
+	d.gossip = gossip
+	d.attempts = 0
+}
+
+func (d *HybridProvider) Chaincode(ctx context.Context, channelName string, ccName string) (api.ChaincodeDiscoverer, error) {
+	gossip := d.gossipProvider()
+	if gossip == nil || d.local.pinned(channelName, ccName) {
+		return d.local.Chaincode(ctx, channelName, ccName)
+	}
+
+	cd, err := gossip.Chaincode(ctx, channelName, ccName)
+	if err == nil {
+		return cd, nil
+	}
+
+	d.logger.Warn(`gossip chaincode discovery failed, fallback to local config`,
+		zap.String(`channel`, channelName), zap.String(`chaincode`, ccName), zap.Error(err))
+
+	cd, localErr := d.local.Chaincode(ctx, channelName, ccName)
+	if localErr != nil {
+		return nil, fmt.Errorf(`gossip discovery: %s, local discovery: %w`, err, localErr)
+	}
+
+	return cd, nil
+}
+
+func (d *HybridProvider) InvocationChain(
+	ctx context.Context, channelName string, chain api.InvocationChain) (api.ChaincodeDiscoverer, error) {
+	if len(chain) == 0 {
+		return nil, ErrEmptyInvocationChain
+	}
+
+	gossip, ok := d.gossipProvider().(api.InvocationChainDiscoveryProvider)
+	if !ok || d.local.pinned(channelName, chain[0].Name) {
+		return d.local.InvocationChain(ctx, channelName, chain)
+	}
+
+	cd, err := gossip.InvocationChain(ctx, channelName, chain)
+	if err == nil {
+		return cd, nil
+	}
+
+	d.logger.Warn(`gossip invocation chain discovery failed, fallback to local config`,
+		zap.String(`channel`, channelName), zap.String(`chain`, chain.Key()), zap.Error(err))
+
+	cd, localErr := d.local.InvocationChain(ctx, channelName, chain)
+	if localErr != nil {
+		return nil, fmt.Errorf(`gossip discovery: %s, local discovery: %w`, err, localErr)
+	}
+
+	return cd, nil
+}
+
+func (d *HybridProvider) Channel(ctx context.Context, channelName string) (api.ChannelDiscoverer, error) {
+	gossip := d.gossipProvider()
+	if gossip == nil {
+		return d.local.Channel(ctx, channelName)
+	}
+
+	cd, err := gossip.Channel(ctx, channelName)
+	if err == nil {
+		return cd, nil
+	}
+
+	d.logger.Warn(`gossip channel discovery failed, fallback to local config`,
+		zap.String(`channel`, channelName), zap.Error(err))
+
+	cd, localErr := d.local.Channel(ctx, channelName)
+	if localErr != nil {
+		return nil, fmt.Errorf(`gossip discovery: %s, local discovery: %w`, err, localErr)
+	}
+
+	return cd, nil
+}
+
+// LocalPeers are discovered only with gossip, local config contains peers in MSP section
+func (d *HybridProvider) LocalPeers(ctx context.Context) (api.LocalPeersDiscoverer, error) {
+	gossip := d.gossipProvider()
+	if gossip == nil {
+		return nil, ErrGossipUnavailable
+	}
+
+	return gossip.LocalPeers(ctx)
+}
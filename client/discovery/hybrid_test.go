@@ -0,0 +1,165 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/api/config"
+)
+
+type gossipProviderMock struct {
+	api.DiscoveryProvider
+
+	err error
+}
+
+func (p *gossipProviderMock) Chaincode(_ context.Context, channelName, ccName string) (api.ChaincodeDiscoverer, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	cd := newChaincodeDTO(ccName, ``, channelName)
+	cd.addEndpointToEndorsers(`GossipMSP`, `peer.gossip:7051`)
+	return cd, nil
+}
+
+func hybridLocalOptions() config.DiscoveryConfigOpts {
+	return config.DiscoveryConfigOpts{
+		`channels`: []interface{}{
+			map[string]interface{}{
+				`name`: `channel`,
+				`chaincodes`: []interface{}{
+					map[string]interface{}{`name`: `cc`, `policy`: `AND('Org1MSP.member')`},
+					map[string]interface{}{`name`: `pinned`, `policy`: `AND('Org2MSP.member')`, `pinned`: true},
+				},
+			},
+		},
+	}
+}
+
+func endorserMSPs(cd api.ChaincodeDiscoverer) []string {
+	var mspIDs []string
+	for _, e := range cd.Endorsers() {
+		mspIDs = append(mspIDs, e.MspID)
+	}
+	return mspIDs
+}
+
+func TestHybridProvider(t *testing.T) {
+	ctx := context.Background()
+	gossip := &gossipProviderMock{}
+
+	hybrid, err := NewHybridProvider(gossip, hybridLocalOptions(), NewEndpointsMapper(nil), zap.NewNop())
+	require.NoError(t, err)
+
+	cd, err := hybrid.Chaincode(ctx, `channel`, `cc`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`GossipMSP`}, endorserMSPs(cd))
+
+	cd, err = hybrid.Chaincode(ctx, `channel`, `pinned`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`Org2MSP`}, endorserMSPs(cd))
+
+	// discovery peer is unreachable
+	gossip.err = errors.New(`connection refused`)
+
+	cd, err = hybrid.Chaincode(ctx, `channel`, `cc`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`Org1MSP`}, endorserMSPs(cd))
+
+	_, err = hybrid.Chaincode(ctx, `channel`, `unknown`)
+	assert.ErrorIs(t, err, ErrNoChaincodes)
+}
+
+func TestHybridProviderWithoutGossip(t *testing.T) {
+	hybrid, err := NewHybridProvider(nil, hybridLocalOptions(), NewEndpointsMapper(nil), zap.NewNop())
+	require.NoError(t, err)
+
+	cd, err := hybrid.Chaincode(context.Background(), `channel`, `cc`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`Org1MSP`}, endorserMSPs(cd))
+
+	_, err = hybrid.LocalPeers(context.Background())
+	assert.ErrorIs(t, err, ErrGossipUnavailable)
+}
+
+func TestLocalConfigProviderInvocationChain(t *testing.T) {
+	local, err := newLocalConfigProvider(hybridLocalOptions(), NewEndpointsMapper(nil))
+	require.NoError(t, err)
+
+	cd, err := local.InvocationChain(context.Background(), `channel`, api.InvocationChain{
+		{Name: `cc`}, {Name: `pinned`, Collections: []string{`private`}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `cc`, cd.ChaincodeName())
+	assert.Equal(t, []api.EndorsementLayout{{`Org1MSP`, `Org2MSP`}}, cd.EndorsementLayouts())
+	assert.ElementsMatch(t, []string{`Org1MSP`, `Org2MSP`}, endorserMSPs(cd))
+}
+
+func TestHybridProviderGossipRetry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+
+	var (
+		mu       sync.Mutex
+		attempts int
+		dialErr  = errors.New(`connection refused`)
+	)
+	factory := func() (api.DiscoveryProvider, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempts++
+		if dialErr != nil {
+			return nil, dialErr
+		}
+		return &gossipProviderMock{}, nil
+	}
+
+	hybrid, err := NewHybridProvider(nil, hybridLocalOptions(), NewEndpointsMapper(nil), zap.NewNop(),
+		WithGossipFactory(factory), WithGossipRetryDelay(time.Second, 4*time.Second))
+	require.NoError(t, err)
+	hybrid.now = func() time.Time { return now }
+
+	discover := func() []string {
+		cd, err := hybrid.Chaincode(ctx, `channel`, `cc`)
+		require.NoError(t, err)
+		return endorserMSPs(cd)
+	}
+	waitConnecting := func() {
+		require.Eventually(t, func() bool {
+			hybrid.gossipMx.Lock()
+			defer hybrid.gossipMx.Unlock()
+			return !hybrid.connecting
+		}, time.Second, time.Millisecond)
+	}
+
+	// gossip is unavailable, local config is used, creation is retried in background
+	assert.Equal(t, []string{`Org1MSP`}, discover())
+	waitConnecting()
+
+	// backoff delay isn't passed
+	assert.Equal(t, []string{`Org1MSP`}, discover())
+	waitConnecting()
+	assert.Equal(t, 1, attempts)
+
+	mu.Lock()
+	dialErr = nil
+	mu.Unlock()
+
+	now = now.Add(time.Second)
+	assert.Equal(t, []string{`Org1MSP`}, discover())
+	waitConnecting()
+
+	assert.Equal(t, []string{`GossipMSP`}, discover())
+	assert.Equal(t, 2, attempts)
+}
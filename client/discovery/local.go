@@ -12,10 +12,14 @@ import (
 
 	"github.com/s7techlab/hlf-sdk-go/api"
 	"github.com/s7techlab/hlf-sdk-go/api/config"
+	"github.com/s7techlab/hlf-sdk-go/client/policy"
 )
 
 // implementation of api.DiscoveryProvider interface
-var _ api.DiscoveryProvider = (*LocalConfigProvider)(nil)
+var (
+	_ api.DiscoveryProvider                = (*LocalConfigProvider)(nil)
+	_ api.InvocationChainDiscoveryProvider = (*LocalConfigProvider)(nil)
+)
 
 type LocalConfigProvider struct {
 	tlsMapper connectionMapper
@@ -26,6 +30,10 @@ type opts struct {
 }
 
 func NewLocalConfigProvider(options config.DiscoveryConfigOpts, tlsMapper connectionMapper) (api.DiscoveryProvider, error) {
+	return newLocalConfigProvider(options, tlsMapper)
+}
+
+func newLocalConfigProvider(options config.DiscoveryConfigOpts, tlsMapper connectionMapper) (*LocalConfigProvider, error) {
 	var opts opts
 	if err := mapstructure.Decode(options, &opts); err != nil {
 		return nil, fmt.Errorf(`decode params: %w`, err)
@@ -34,6 +42,23 @@ func NewLocalConfigProvider(options config.DiscoveryConfigOpts, tlsMapper connec
 	return &LocalConfigProvider{channels: opts.Channels, tlsMapper: tlsMapper}, nil
 }
 
+// pinned reports whether chaincode endorsers must be always taken from local config
+func (d *LocalConfigProvider) pinned(channelName, ccName string) bool {
+	for _, ch := range d.channels {
+		if ch.Name != channelName {
+			continue
+		}
+
+		for _, cc := range ch.Chaincodes {
+			if cc.Name == ccName {
+				return cc.Pinned
+			}
+		}
+	}
+
+	return false
+}
+
 func (d *LocalConfigProvider) Chaincode(_ context.Context, channelName, ccName string) (api.ChaincodeDiscoverer, error) {
 	var channelFoundFlag bool
 
@@ -57,6 +82,12 @@ func (d *LocalConfigProvider) Chaincode(_ context.Context, channelName, ccName s
 						return nil, err
 					}
 
+					layouts, err := policy.LayoutsFromString(cc.Policy)
+					if err != nil {
+						return nil, fmt.Errorf(`endorsement layouts from policy: %w`, err)
+					}
+					ccDTO.setEndorsementLayouts(layouts)
+
 					for i := range msps {
 						mspID := msps[i]
 						hostAddr := "" // no addr in channel config, peer must be already in pool
@@ -75,6 +106,82 @@ func (d *LocalConfigProvider) Chaincode(_ context.Context, channelName, ccName s
 	return nil, ErrChannelNotFound
 }
 
+// InvocationChain returns endorsers, satisfying policies of all chaincodes in chain.
+// Collections policies are not present in local config, so they are not taken into account
+func (d *LocalConfigProvider) InvocationChain(
+	_ context.Context, channelName string, chain api.InvocationChain) (api.ChaincodeDiscoverer, error) {
+	if len(chain) == 0 {
+		return nil, ErrEmptyInvocationChain
+	}
+
+	var (
+		ccDTO      *chaincodeDTO
+		layoutSets [][]api.EndorsementLayout
+	)
+
+	for _, call := range chain {
+		ch, cc, err := d.chaincode(channelName, call.Name)
+		if err != nil {
+			return nil, fmt.Errorf(`chaincode=%s: %w`, call.Name, err)
+		}
+
+		layouts, err := policy.LayoutsFromString(cc.Policy)
+		if err != nil {
+			return nil, fmt.Errorf(`endorsement layouts from policy: %w`, err)
+		}
+		layoutSets = append(layoutSets, layouts)
+
+		if ccDTO == nil {
+			ccDTO = newChaincodeDTO(cc.Name, cc.Version, channelName)
+			for i := range ch.Orderers {
+				mspID := "" // TODO we have no MSPID from local cfg
+				ccDTO.addEndpointToOrderers(mspID, ch.Orderers[i].Host)
+			}
+		}
+	}
+
+	layouts := policy.MergeLayouts(layoutSets...)
+	ccDTO.setEndorsementLayouts(layouts)
+
+	// endorsers are MSPs of the first (minimal) layout, no addr in channel config, peer must be already in pool
+	if len(layouts) > 0 {
+		for _, mspID := range layouts[0] {
+			ccDTO.addEndpointToEndorsers(mspID, "")
+		}
+	}
+
+	layoutsEndorsers := make(map[string]struct{})
+	for _, layout := range layouts {
+		for _, mspID := range layout {
+			if _, ok := layoutsEndorsers[mspID]; ok {
+				continue
+			}
+			layoutsEndorsers[mspID] = struct{}{}
+			ccDTO.addEndpointToLayoutsEndorsers(mspID, "")
+		}
+	}
+
+	return newChaincodeDiscovererTLSDecorator(ccDTO, d.tlsMapper), nil
+}
+
+func (d *LocalConfigProvider) chaincode(channelName, ccName string) (*config.DiscoveryChannel, *config.DiscoveryChaincode, error) {
+	for i := range d.channels {
+		ch := &d.channels[i]
+		if ch.Name != channelName {
+			continue
+		}
+
+		for j := range ch.Chaincodes {
+			if ch.Chaincodes[j].Name == ccName {
+				return ch, &ch.Chaincodes[j], nil
+			}
+		}
+		return nil, nil, ErrNoChaincodes
+	}
+
+	return nil, nil, ErrChannelNotFound
+}
+
 func (d *LocalConfigProvider) Channel(_ context.Context, channelName string) (api.ChannelDiscoverer, error) {
 	var channelFoundFlag bool
 
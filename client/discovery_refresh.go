@@ -0,0 +1,169 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/api/config"
+	"github.com/s7techlab/hlf-sdk-go/client/discovery"
+	clienterrors "github.com/s7techlab/hlf-sdk-go/client/errors"
+)
+
+// refreshDiscovery periodically re-queries discovery provider until ctx is done
+func (c *Client) refreshDiscovery(ctx context.Context, period time.Duration) {
+	t := time.NewTicker(period)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := c.RefreshDiscovery(ctx); err != nil {
+				c.logger.Warn(`discovery refresh`, zap.Error(err))
+			}
+		}
+	}
+}
+
+// RefreshDiscovery re-queries discovery provider:
+// - adds to pool new local peers and removes from pool discovered peers, which left network
+// - adds to pool new endorsers and updates endorsing MSPs of chaincodes, used on channels
+// Cached discovery results are dropped before refresh, so refresh always gets actual network state
+func (c *Client) RefreshDiscovery(ctx context.Context) error {
+	mErr := new(clienterrors.MultiError)
+
+	if invalidator, ok := c.discoveryProvider.(discovery.Invalidator); ok {
+		invalidator.InvalidateAll()
+	}
+
+	localPeers, err := c.discoveryProvider.LocalPeers(ctx)
+	if err != nil {
+		mErr.Add(fmt.Errorf(`fetch local peers from discovery provider: %w`, err))
+	} else if err = c.reconcileLocalPeers(ctx, localPeers); err != nil {
+		mErr.Add(err)
+	}
+
+	c.channelMx.Lock()
+	channels := make([]api.Channel, 0, len(c.channels))
+	for _, ch := range c.channels {
+		channels = append(channels, ch)
+	}
+	c.channelMx.Unlock()
+
+	for _, ch := range channels {
+		refresher, ok := ch.(interface {
+			RefreshChaincodes(ctx context.Context) error
+		})
+		if !ok {
+			continue
+		}
+
+		if err = refresher.RefreshChaincodes(ctx); err != nil {
+			mErr.Add(err)
+		}
+	}
+
+	if len(mErr.Errors) > 0 {
+		return mErr
+	}
+	return nil
+}
+
+// reconcileLocalPeers makes discovered peers in pool match local peers discovery response.
+// Peers, added to pool from local peers discovery or as chaincode endorsers, are removed, when they leave network.
+// Peers, added to pool from config or with options, are not removed
+func (c *Client) reconcileLocalPeers(ctx context.Context, localPeers api.LocalPeersDiscoverer) error {
+	mErr := new(clienterrors.MultiError)
+	// msp id -> host
+	actual := make(map[string]map[string]struct{})
+
+	for _, lp := range localPeers.Peers() {
+		if _, ok := actual[lp.MspID]; !ok {
+			actual[lp.MspID] = make(map[string]struct{})
+		}
+
+		for _, lpAddresses := range lp.HostAddresses {
+			actual[lp.MspID][lpAddresses.Host] = struct{}{}
+
+			peerCfg := config.ConnectionConfig{
+				Host: lpAddresses.Host,
+				Tls:  lpAddresses.TlsConfig,
+			}
+
+			if err := c.addDiscoveredPeer(ctx, lp.MspID, peerCfg); err != nil {
+				mErr.Add(err)
+			}
+		}
+	}
+
+	c.discoveredPeersMx.Lock()
+	defer c.discoveredPeersMx.Unlock()
+
+	for mspID, hosts := range c.discoveredPeers {
+		for host := range hosts {
+			if _, ok := actual[mspID][host]; ok {
+				continue
+			}
+
+			c.logger.Info(`peer left network, remove from pool`,
+				zap.String(`msp_id`, mspID), zap.String(`host`, host))
+
+			if err := c.peerPool.Remove(mspID, host); err != nil {
+				mErr.Add(fmt.Errorf(`remove peer from pool: %w`, err))
+			}
+			delete(hosts, host)
+		}
+	}
+
+	if len(mErr.Errors) > 0 {
+		return mErr
+	}
+	return nil
+}
+
+// addDiscoveredPeer connects to discovered peer and adds it to pool, if pool doesn't contain it yet.
+// Peer is dialed without holding discovered peers lock, so slow peer doesn't block refresh and other channels
+func (c *Client) addDiscoveredPeer(ctx context.Context, mspID string, peerConfig config.ConnectionConfig) error {
+	if c.isInPool(mspID, peerConfig.Host) {
+		return nil
+	}
+
+	p, err := NewPeer(ctx, peerConfig, c.defaultSigner, c.logger)
+	if err != nil {
+		return fmt.Errorf(`initialize endorsers for MSP: %s: %w`, mspID, err)
+	}
+
+	c.discoveredPeersMx.Lock()
+	defer c.discoveredPeersMx.Unlock()
+
+	// peer is added concurrently while dialing
+	if c.isInPool(mspID, peerConfig.Host) {
+		_ = p.Close()
+		return nil
+	}
+
+	if err = c.peerPool.Add(mspID, p, c.peerCheckStrategyFor(mspID)); err != nil {
+		_ = p.Close()
+		return fmt.Errorf(`add peer to pool: %w`, err)
+	}
+
+	if _, ok := c.discoveredPeers[mspID]; !ok {
+		c.discoveredPeers[mspID] = make(map[string]struct{})
+	}
+	c.discoveredPeers[mspID][peerConfig.Host] = struct{}{}
+	return nil
+}
+
+func (c *Client) isInPool(mspID, host string) bool {
+	for _, p := range c.peerPool.GetMSPPeers(mspID) {
+		if p.URI() == host {
+			return true
+		}
+	}
+	return false
+}
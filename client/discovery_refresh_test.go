@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+)
+
+type localPeersMock []*api.HostEndpoint
+
+func (l localPeersMock) Peers() []*api.HostEndpoint {
+	return l
+}
+
+func idleCheckStrategy(ctx context.Context, _ api.Peer, _ chan bool) {
+	<-ctx.Done()
+}
+
+func TestReconcileLocalPeersRemovesLeftPeers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := &Client{
+		peerPool:        NewPeerPool(ctx, zap.NewNop()),
+		discoveredPeers: make(map[string]map[string]struct{}),
+		logger:          zap.NewNop(),
+	}
+
+	for _, host := range []string{`config:7051`, `local:7051`, `endorser:7051`} {
+		require.NoError(t, c.peerPool.Add(`org1`, &uriPeerMock{uri: host}, idleCheckStrategy))
+	}
+	// local peer and endorser are added from discovery
+	c.discoveredPeers[`org1`] = map[string]struct{}{`local:7051`: {}, `endorser:7051`: {}}
+
+	// endorser left network
+	require.NoError(t, c.reconcileLocalPeers(ctx, localPeersMock{{
+		MspID:         `org1`,
+		HostAddresses: []*api.Endpoint{{Host: `local:7051`}},
+	}}))
+
+	var uris []string
+	for _, p := range c.peerPool.GetMSPPeers(`org1`) {
+		uris = append(uris, p.URI())
+	}
+	// peer from config is kept
+	assert.ElementsMatch(t, []string{`config:7051`, `local:7051`}, uris)
+	assert.Equal(t, map[string]struct{}{`local:7051`: {}}, c.discoveredPeers[`org1`])
+}
@@ -11,4 +11,25 @@ const (
 	ErrNoPeersForMSP = errors.Error(`no peers for MSP`)
 	ErrMSPNotFound   = errors.Error(`MSP not found`)
 	ErrPeerNotReady  = errors.Error(`peer not ready`)
+	ErrPeerNotFound  = errors.Error(`peer not found`)
+	ErrPoolClosed    = errors.Error(`peer pool closed`)
+
+	ErrNoOrderers          = errors.Error(`no orderers`)
+	ErrBFTQuorumNotReached = errors.Error(`BFT quorum not reached`)
+	ErrBFTBlockSignatures  = errors.Error(`block isn't signed by BFT quorum`)
+	// ErrBFTConsentersNotConnected - consenters without connection, i.e. with invalid connection config,
+	// are left out, and connected ones aren't enough to reach f+1 acknowledges
+	ErrBFTConsentersNotConnected = errors.Error(`not enough connected BFT consenters`)
+
+	ErrSignerNotDefined = errors.Error(`signer is not defined`)
+
+	ErrEndorserVerifierUnavailable = errors.Error(`endorser verifier unavailable`)
+
+	ErrBroadcastStreamClosed             = errors.Error(`broadcast stream closed`)
+	ErrBroadcastStreamUnexpectedResponse = errors.Error(`broadcast stream unexpected response`)
+	// ErrBroadcastEnvelopeNotProcessed - orderer closed stream after rejecting previous envelope,
+	// envelope wasn't processed and can be sent again
+	ErrBroadcastEnvelopeNotProcessed = errors.Error(`broadcast envelope not processed`)
+	// ErrBroadcastEnvelopeNotSent - broadcast stream wasn't opened, envelope didn't reach orderer
+	ErrBroadcastEnvelopeNotSent = errors.Error(`broadcast envelope not sent`)
 )
@@ -19,3 +19,8 @@ func (e *MultiError) Error() string {
 func (e *MultiError) Add(err error) {
 	e.Errors = append(e.Errors, err)
 }
+
+// Unwrap allows to match occurred errors with errors.Is and errors.As
+func (e *MultiError) Unwrap() []error {
+	return e.Errors
+}
@@ -0,0 +1,309 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/gateway"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protoutil"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/api/config"
+	"github.com/s7techlab/hlf-sdk-go/client/chaincode/txwaiter"
+	grpcclient "github.com/s7techlab/hlf-sdk-go/client/grpc"
+	"github.com/s7techlab/hlf-sdk-go/client/tx"
+)
+
+// DefaultDialTimeout - timeout of dial to gateway peer, if not set in connection config
+const DefaultDialTimeout = 5 * time.Second
+
+var (
+	ErrEmptyArgs = errors.New(`chaincode args are empty`)
+	// ErrUnsupportedTxWaiterType - gateway tracks commit only on gateway peer,
+	// so tx waiters of several organizations are not supported
+	ErrUnsupportedTxWaiterType = errors.New(`tx waiter type is not supported by gateway`)
+)
+
+var _ api.Invoker = (*Gateway)(nil)
+
+// Gateway - api.Invoker implementation backed by Fabric Gateway service of peer (fabric v2.4+).
+// Gateway peer plans endorsement, collects endorsements, sends transaction to orderer and tracks its commit
+type Gateway struct {
+	client   gateway.GatewayClient
+	identity msp.SigningIdentity
+	// conn - connection, dialed by gateway, closed with Close
+	conn *grpc.ClientConn
+}
+
+// New creates gateway invoker from existing GRPC connection to peer,
+// identity is used if identity is not passed to Invoke or Query
+func New(conn *grpc.ClientConn, identity msp.SigningIdentity) *Gateway {
+	return &Gateway{
+		client:   gateway.NewGatewayClient(conn),
+		identity: identity,
+	}
+}
+
+// NewFromConfig dials to gateway peer and creates gateway invoker
+func NewFromConfig(
+	ctx context.Context, c config.ConnectionConfig, identity msp.SigningIdentity, logger *zap.Logger) (*Gateway, error) {
+	opts, err := grpcclient.OptionsFromConfig(c, logger)
+	if err != nil {
+		return nil, fmt.Errorf(`gateway grpc options from config: %w`, err)
+	}
+
+	dialTimeout := c.Timeout.Duration
+	if dialTimeout == 0 {
+		dialTimeout = DefaultDialTimeout
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+
+	logger.Debug(`dial to gateway`, zap.String(`host`, c.Host))
+	conn, err := grpc.DialContext(dialCtx, c.Host, opts.Dial...)
+	if err != nil {
+		return nil, fmt.Errorf(`grpc dial to gateway endpoint=%s: %w`, c.Host, err)
+	}
+
+	gw := New(conn, identity)
+	gw.conn = conn
+
+	return gw, nil
+}
+
+// Close closes connection to gateway peer, if it is dialed by NewFromConfig.
+// Connection passed to New is closed by its owner
+func (g *Gateway) Close() error {
+	if g.conn == nil {
+		return nil
+	}
+	return g.conn.Close()
+}
+
+func (g *Gateway) CurrentIdentity() msp.SigningIdentity {
+	return g.identity
+}
+
+// Query evaluates transaction on peer, chosen by gateway.
+// Endorser MSPs from context are used as target organizations
+func (g *Gateway) Query(
+	ctx context.Context,
+	channel string,
+	chaincode string,
+	args [][]byte,
+	identity msp.SigningIdentity,
+	transient map[string][]byte,
+) (*peer.Response, error) {
+	proposal, txID, err := g.proposal(ctx, channel, chaincode, args, identity, transient)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := g.client.Evaluate(ctx, &gateway.EvaluateRequest{
+		TransactionId:       txID,
+		ChannelId:           channel,
+		ProposedTransaction: proposal,
+		TargetOrganizations: tx.EndorserMSPsFromContext(ctx),
+	})
+	if err != nil {
+		return nil, gatewayError(`evaluate`, err)
+	}
+
+	return res.Result, nil
+}
+
+// Invoke endorses transaction with gateway, sends it to orderer and waits for its commit on gateway peer.
+// Endorser MSPs from context are used as endorsing organizations, otherwise gateway plans endorsement itself.
+// Commit is not awaited with 'none' tx waiter type, 'self' and 'any' types wait for commit on gateway peer,
+// other types are not supported
+func (g *Gateway) Invoke(
+	ctx context.Context,
+	channel string,
+	chaincode string,
+	args [][]byte,
+	identity msp.SigningIdentity,
+	transient map[string][]byte,
+	txWaiterType string,
+) (*peer.Response, string, error) {
+	if err := checkTxWaiterType(txWaiterType); err != nil {
+		return nil, ``, err
+	}
+
+	identity = tx.ChooseSigner(ctx, identity, g.identity)
+
+	proposal, txID, err := g.proposal(ctx, channel, chaincode, args, identity, transient)
+	if err != nil {
+		return nil, ``, err
+	}
+
+	endorsed, err := g.client.Endorse(ctx, &gateway.EndorseRequest{
+		TransactionId:          txID,
+		ChannelId:              channel,
+		ProposedTransaction:    proposal,
+		EndorsingOrganizations: tx.EndorserMSPsFromContext(ctx),
+	})
+	if err != nil {
+		return nil, txID, gatewayError(`endorse`, err)
+	}
+
+	envelope := endorsed.PreparedTransaction
+	result, err := transactionResult(envelope.GetPayload())
+	if err != nil {
+		return nil, txID, err
+	}
+
+	if envelope.Signature, err = identity.Sign(envelope.Payload); err != nil {
+		return nil, txID, fmt.Errorf(`sign transaction: %w`, err)
+	}
+
+	if _, err = g.client.Submit(ctx, &gateway.SubmitRequest{
+		TransactionId:       txID,
+		ChannelId:           channel,
+		PreparedTransaction: envelope,
+	}); err != nil {
+		return nil, txID, gatewayError(`submit`, err)
+	}
+
+	if txWaiterType == api.TxWaiterNoneType {
+		return result, txID, nil
+	}
+
+	if _, err = g.CommitStatus(ctx, channel, txID, identity); err != nil {
+		return nil, txID, err
+	}
+
+	return result, txID, nil
+}
+
+// CommitStatus waits for transaction commit on gateway peer and returns transaction validation code.
+// api.InvalidTxError is returned if transaction is invalid
+func (g *Gateway) CommitStatus(
+	ctx context.Context, channel, txID string, identity msp.SigningIdentity) (peer.TxValidationCode, error) {
+	identity = tx.ChooseSigner(ctx, identity, g.identity)
+
+	creator, err := identity.Serialize()
+	if err != nil {
+		return -1, fmt.Errorf(`serialize identity: %w`, err)
+	}
+
+	request, err := proto.Marshal(&gateway.CommitStatusRequest{
+		TransactionId: txID,
+		ChannelId:     channel,
+		Identity:      creator,
+	})
+	if err != nil {
+		return -1, fmt.Errorf(`marshal commit status request: %w`, err)
+	}
+
+	signature, err := identity.Sign(request)
+	if err != nil {
+		return -1, fmt.Errorf(`sign commit status request: %w`, err)
+	}
+
+	res, err := g.client.CommitStatus(ctx, &gateway.SignedCommitStatusRequest{
+		Request:   request,
+		Signature: signature,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return -1, api.TxCommitTimeoutError{TxId: txID, Err: ctx.Err()}
+		}
+		return -1, gatewayError(`commit status`, err)
+	}
+
+	if res.Result != peer.TxValidationCode_VALID {
+		return res.Result, api.InvalidTxError{TxId: txID, Code: res.Result}
+	}
+
+	return res.Result, nil
+}
+
+func (g *Gateway) proposal(
+	ctx context.Context,
+	channel string,
+	chaincode string,
+	args [][]byte,
+	identity msp.SigningIdentity,
+	transient map[string][]byte,
+) (*peer.SignedProposal, string, error) {
+	if len(args) == 0 {
+		return nil, ``, ErrEmptyArgs
+	}
+
+	proposal, txID, err := tx.Endorsement{
+		Channel:      channel,
+		Chaincode:    chaincode,
+		Args:         args,
+		Signer:       tx.ChooseSigner(ctx, identity, g.identity),
+		TransientMap: transient,
+	}.SignedProposal()
+	if err != nil {
+		return nil, ``, fmt.Errorf(`create proposal: %w`, err)
+	}
+
+	return proposal, txID, nil
+}
+
+// checkTxWaiterType checks tx waiter type can be satisfied by commit status of gateway peer
+func checkTxWaiterType(txWaiterType string) error {
+	if _, err := txwaiter.ByType(txWaiterType); err != nil {
+		return fmt.Errorf(`invalid tx waiter type: %w`, err)
+	}
+
+	switch txWaiterType {
+	case ``, api.TxWaiterSelfType, api.TxWaiterAnyType, api.TxWaiterNoneType:
+		return nil
+	}
+
+	return fmt.Errorf(`%s: %w`, txWaiterType, ErrUnsupportedTxWaiterType)
+}
+
+// transactionResult returns chaincode response from prepared transaction payload
+func transactionResult(payloadBytes []byte) (*peer.Response, error) {
+	payload, err := protoutil.UnmarshalPayload(payloadBytes)
+	if err != nil {
+		return nil, fmt.Errorf(`unmarshal prepared transaction payload: %w`, err)
+	}
+
+	transaction, err := protoutil.UnmarshalTransaction(payload.Data)
+	if err != nil {
+		return nil, fmt.Errorf(`unmarshal prepared transaction: %w`, err)
+	}
+
+	if len(transaction.Actions) == 0 {
+		return nil, errors.New(`prepared transaction has no actions`)
+	}
+
+	_, action, err := protoutil.GetPayloads(transaction.Actions[0])
+	if err != nil {
+		return nil, fmt.Errorf(`prepared transaction action: %w`, err)
+	}
+
+	return action.Response, nil
+}
+
+// gatewayError adds details of failed peers or orderers, returned by gateway, to error
+func gatewayError(op string, err error) error {
+	var details []string
+	for _, detail := range status.Convert(err).Details() {
+		if d, ok := detail.(*gateway.ErrorDetail); ok {
+			details = append(details, fmt.Sprintf(`%s(%s): %s`, d.MspId, d.Address, d.Message))
+		}
+	}
+
+	if len(details) == 0 {
+		return fmt.Errorf(`gateway %s: %w`, op, err)
+	}
+
+	return fmt.Errorf(`gateway %s: %w, details: %s`, op, err, strings.Join(details, `; `))
+}
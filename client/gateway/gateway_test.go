@@ -0,0 +1,284 @@
+package gateway_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	fabricGateway "github.com/hyperledger/fabric-protos-go/gateway"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/client/gateway"
+	"github.com/s7techlab/hlf-sdk-go/client/tx"
+	"github.com/s7techlab/hlf-sdk-go/identity"
+)
+
+// gatewayServer - local stand-in for Fabric Gateway service of peer
+type gatewayServer struct {
+	fabricGateway.UnimplementedGatewayServer
+
+	signer *identity.SigningIdentity
+
+	mu                     sync.Mutex
+	commitCode             peer.TxValidationCode
+	endorsingOrganizations []string
+	submitted              map[string]bool
+	commitStatusRequests   []*fabricGateway.CommitStatusRequest
+}
+
+func (s *gatewayServer) Evaluate(_ context.Context, req *fabricGateway.EvaluateRequest) (
+	*fabricGateway.EvaluateResponse, error) {
+	input, err := proposalInput(req.ProposedTransaction)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fabricGateway.EvaluateResponse{Result: &peer.Response{Status: 200, Payload: input.Args[1]}}, nil
+}
+
+func (s *gatewayServer) Endorse(_ context.Context, req *fabricGateway.EndorseRequest) (
+	*fabricGateway.EndorseResponse, error) {
+	if err := s.signer.Verify(req.ProposedTransaction.ProposalBytes, req.ProposedTransaction.Signature); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	input, err := proposalInput(req.ProposedTransaction)
+	if err != nil {
+		return nil, err
+	}
+
+	if string(input.Args[0]) == `fail` {
+		st, _ := status.New(codes.Aborted, `failed to endorse transaction`).WithDetails(&fabricGateway.ErrorDetail{
+			Address: `peer0.org1:7051`,
+			MspId:   `Org1MSP`,
+			Message: `chaincode response 500, fail`,
+		})
+		return nil, st.Err()
+	}
+
+	s.mu.Lock()
+	s.endorsingOrganizations = req.EndorsingOrganizations
+	s.mu.Unlock()
+
+	chaincodeAction, err := proto.Marshal(&peer.ChaincodeAction{
+		Response: &peer.Response{Status: 200, Payload: input.Args[1]},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	responsePayload, err := proto.Marshal(&peer.ProposalResponsePayload{Extension: chaincodeAction})
+	if err != nil {
+		return nil, err
+	}
+
+	prepared, err := tx.NewUnsignedTransaction(req.ProposedTransaction, []*peer.ProposalResponse{{
+		Response:    &peer.Response{Status: 200},
+		Payload:     responsePayload,
+		Endorsement: &peer.Endorsement{Endorser: []byte(`endorser`), Signature: []byte(`signature`)},
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	return &fabricGateway.EndorseResponse{
+		PreparedTransaction: &common.Envelope{Payload: prepared.PayloadBytes},
+	}, nil
+}
+
+func (s *gatewayServer) Submit(_ context.Context, req *fabricGateway.SubmitRequest) (
+	*fabricGateway.SubmitResponse, error) {
+	envelope := req.PreparedTransaction
+	if err := s.signer.Verify(envelope.Payload, envelope.Signature); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	s.mu.Lock()
+	s.submitted[req.TransactionId] = true
+	s.mu.Unlock()
+
+	return &fabricGateway.SubmitResponse{}, nil
+}
+
+func (s *gatewayServer) CommitStatus(ctx context.Context, req *fabricGateway.SignedCommitStatusRequest) (
+	*fabricGateway.CommitStatusResponse, error) {
+	if err := s.signer.Verify(req.Request, req.Signature); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	request := new(fabricGateway.CommitStatusRequest)
+	if err := proto.Unmarshal(req.Request, request); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.commitStatusRequests = append(s.commitStatusRequests, request)
+	submitted, code := s.submitted[request.TransactionId], s.commitCode
+	s.mu.Unlock()
+
+	if !submitted {
+		// transaction commit is awaited until context is done
+		<-ctx.Done()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	return &fabricGateway.CommitStatusResponse{Result: code, BlockNumber: 1}, nil
+}
+
+func proposalInput(signedProposal *peer.SignedProposal) (*peer.ChaincodeInput, error) {
+	proposal, err := protoutil.UnmarshalProposal(signedProposal.ProposalBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := protoutil.UnmarshalChaincodeProposalPayload(proposal.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := protoutil.UnmarshalChaincodeInvocationSpec(payload.Input)
+	if err != nil {
+		return nil, err
+	}
+
+	return spec.ChaincodeSpec.Input, nil
+}
+
+func newSigner(t *testing.T) *identity.SigningIdentity {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: `user1.org1`},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return identity.NewSigning(`Org1MSP`, cert, key)
+}
+
+func newGateway(t *testing.T, server *gatewayServer) *gateway.Gateway {
+	listener := bufconn.Listen(1024 * 1024)
+
+	grpcServer := grpc.NewServer()
+	fabricGateway.RegisterGatewayServer(grpcServer, server)
+	go func() { _ = grpcServer.Serve(listener) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), `bufconn`,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return gateway.New(conn, server.signer)
+}
+
+func TestGateway(t *testing.T) {
+	ctx := context.Background()
+	server := &gatewayServer{
+		signer:    newSigner(t),
+		submitted: make(map[string]bool),
+	}
+	gw := newGateway(t, server)
+
+	t.Run(`query`, func(t *testing.T) {
+		res, err := gw.Query(ctx, `channel`, `chaincode`, tx.StringArgsBytes(`get`, `value`), nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(`value`), res.Payload)
+	})
+
+	t.Run(`invoke waits for commit status`, func(t *testing.T) {
+		res, txID, err := gw.Invoke(tx.ContextWithEndorserMSPs(ctx, []string{`Org1MSP`}),
+			`channel`, `chaincode`, tx.StringArgsBytes(`put`, `value`), nil, nil, ``)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(`value`), res.Payload)
+		assert.NotEmpty(t, txID)
+
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		assert.Equal(t, []string{`Org1MSP`}, server.endorsingOrganizations)
+		assert.True(t, server.submitted[txID])
+		require.Len(t, server.commitStatusRequests, 1)
+		assert.Equal(t, txID, server.commitStatusRequests[0].TransactionId)
+	})
+
+	t.Run(`invoke with none tx waiter doesn't wait for commit`, func(t *testing.T) {
+		server.mu.Lock()
+		server.commitStatusRequests = nil
+		server.mu.Unlock()
+
+		_, _, err := gw.Invoke(ctx, `channel`, `chaincode`, tx.StringArgsBytes(`put`, `value`), nil, nil,
+			api.TxWaiterNoneType)
+		require.NoError(t, err)
+
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		assert.Empty(t, server.commitStatusRequests)
+	})
+
+	t.Run(`invalid tx`, func(t *testing.T) {
+		server.mu.Lock()
+		server.commitCode = peer.TxValidationCode_MVCC_READ_CONFLICT
+		server.mu.Unlock()
+
+		_, _, err := gw.Invoke(ctx, `channel`, `chaincode`, tx.StringArgsBytes(`put`, `value`), nil, nil, ``)
+		assert.ErrorIs(t, err, api.ErrMVCCConflict)
+	})
+
+	t.Run(`endorsement error details`, func(t *testing.T) {
+		_, _, err := gw.Invoke(ctx, `channel`, `chaincode`, tx.StringArgsBytes(`fail`, `value`), nil, nil, ``)
+		require.Error(t, err)
+		assert.Equal(t, codes.Aborted, status.Code(err))
+		assert.Contains(t, err.Error(), `Org1MSP(peer0.org1:7051): chaincode response 500, fail`)
+	})
+
+	t.Run(`commit status timeout`, func(t *testing.T) {
+		timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+
+		_, err := gw.CommitStatus(timeoutCtx, `channel`, `unknown`, nil)
+		var timeoutErr api.TxCommitTimeoutError
+		assert.ErrorAs(t, err, &timeoutErr)
+	})
+
+	t.Run(`invalid tx waiter type`, func(t *testing.T) {
+		_, _, err := gw.Invoke(ctx, `channel`, `chaincode`, tx.StringArgsBytes(`put`, `value`), nil, nil, `unknown`)
+		assert.Error(t, err)
+	})
+
+	t.Run(`tx waiter types of several organizations are not supported`, func(t *testing.T) {
+		for _, txWaiterType := range []string{api.TxWaiterAllType, `quorum:2`, `msp:Org1MSP,Org2MSP`} {
+			_, _, err := gw.Invoke(ctx, `channel`, `chaincode`, tx.StringArgsBytes(`put`, `value`), nil, nil,
+				txWaiterType)
+			assert.ErrorIs(t, err, gateway.ErrUnsupportedTxWaiterType, txWaiterType)
+		}
+	})
+}
@@ -49,8 +49,20 @@ type Opts struct {
 	Dial        []grpc.DialOption
 }
 
-// OptionsFromConfig - adds tracing, TLS certs and connection limits
+// OptionsFromConfig - adds tracing, TLS certs and connection limits, dial blocks until connection is ready
 func OptionsFromConfig(c config.ConnectionConfig, logger *zap.Logger) (*Opts, error) {
+	opts, err := NonBlockingOptionsFromConfig(c, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Dial = append(opts.Dial, grpc.WithBlock())
+	return opts, nil
+}
+
+// NonBlockingOptionsFromConfig - options of OptionsFromConfig, dial returns without waiting for connection,
+// connection is established in background and re-established after failures
+func NonBlockingOptionsFromConfig(c config.ConnectionConfig, logger *zap.Logger) (*Opts, error) {
 
 	// TODO: move to config or variable options
 
@@ -156,7 +168,6 @@ func OptionsFromConfig(c config.ConnectionConfig, logger *zap.Logger) (*Opts, er
 			grpc.MaxCallRecvMsgSize(maxRecvMsgSize),
 			grpc.MaxCallSendMsgSize(maxSendMsgSize),
 		),
-		grpc.WithBlock(),
 	)
 
 	fields := []zap.Field{
@@ -23,27 +23,65 @@ const (
 	OrdererDefaultDialTimeout = 5 * time.Second
 )
 
-type ErrUnexpectedStatus struct {
-	status  common.Status
-	message string
-}
-
-func (e *ErrUnexpectedStatus) Error() string {
-	return fmt.Sprintf("unexpected status: %s. message: %v", e.status.String(), e.message)
-}
+// ErrUnexpectedStatus - orderer responded with non-success status
+//
+// Deprecated: use api.OrdererStatusError
+type ErrUnexpectedStatus = api.OrdererStatusError
 
 type Orderer struct {
 	uri             string
 	conn            *grpc.ClientConn
 	broadcastClient fabricOrderer.AtomicBroadcastClient
+	// stream - if set, broadcasts are sent through persistent stream
+	stream            *broadcastStream
+	streamMaxInFlight int
+	logger            *zap.Logger
 }
 
-func NewOrderer(dialCtx context.Context, c config.ConnectionConfig, logger *zap.Logger) (*Orderer, error) {
-	opts, err := grpcclient.OptionsFromConfig(c, logger)
+// OrdererOpt describes option which will be applied to Orderer
+type OrdererOpt func(o *Orderer)
+
+// WithBroadcastStream sends broadcasts through one long-lived stream instead of stream per broadcast,
+// maxInFlight limits number of envelopes waiting for response, default is DefaultBroadcastStreamMaxInFlight
+func WithBroadcastStream(maxInFlight int) OrdererOpt {
+	return func(o *Orderer) {
+		o.streamMaxInFlight = maxInFlight
+		if o.streamMaxInFlight <= 0 {
+			o.streamMaxInFlight = DefaultBroadcastStreamMaxInFlight
+		}
+	}
+}
+
+// WithOrdererLogger sets orderer logger
+func WithOrdererLogger(logger *zap.Logger) OrdererOpt {
+	return func(o *Orderer) {
+		o.logger = logger
+	}
+}
+
+func NewOrderer(dialCtx context.Context, c config.ConnectionConfig, logger *zap.Logger, opts ...OrdererOpt) (*Orderer, error) {
+	grpcOpts, err := grpcclient.OptionsFromConfig(c, logger)
 	if err != nil {
 		return nil, fmt.Errorf(`get orderer GRPC options: %w`, err)
 	}
 
+	return newOrderer(dialCtx, c, grpcOpts, logger, opts...)
+}
+
+// NewOrdererNonBlocking creates orderer without waiting for connection, connection is established in background,
+// so orderer, which is down, becomes available as soon as it is up
+func NewOrdererNonBlocking(dialCtx context.Context, c config.ConnectionConfig, logger *zap.Logger, opts ...OrdererOpt) (*Orderer, error) {
+	grpcOpts, err := grpcclient.NonBlockingOptionsFromConfig(c, logger)
+	if err != nil {
+		return nil, fmt.Errorf(`get orderer GRPC options: %w`, err)
+	}
+
+	return newOrderer(dialCtx, c, grpcOpts, logger, opts...)
+}
+
+func newOrderer(
+	dialCtx context.Context, c config.ConnectionConfig, grpcOpts *grpcclient.Opts, logger *zap.Logger, opts ...OrdererOpt) (*Orderer, error) {
+
 	// Dial shoould always has timeout
 	ctxDeadline, exists := dialCtx.Deadline()
 	if !exists {
@@ -60,29 +98,55 @@ func NewOrderer(dialCtx context.Context, c config.ConnectionConfig, logger *zap.
 	}
 
 	logger.Debug(`dial to orderer`, zap.String(`host`, c.Host), zap.Time(`context deadline`, ctxDeadline))
-	conn, err := grpc.DialContext(dialCtx, c.Host, opts.Dial...)
+	conn, err := grpc.DialContext(dialCtx, c.Host, grpcOpts.Dial...)
 	if err != nil {
 		return nil, fmt.Errorf(`dial to orderer=: %w`, err)
 	}
 
-	return NewOrdererFromGRPC(conn)
+	return NewOrdererFromGRPC(conn, append([]OrdererOpt{WithOrdererLogger(logger)}, opts...)...)
 }
 
 // NewOrdererFromGRPC allows initializing orderer from existing GRPC connection
-func NewOrdererFromGRPC(conn *grpc.ClientConn) (*Orderer, error) {
+func NewOrdererFromGRPC(conn *grpc.ClientConn, opts ...OrdererOpt) (*Orderer, error) {
 	orderer := &Orderer{
 		uri:             conn.Target(),
 		conn:            conn,
 		broadcastClient: fabricOrderer.NewAtomicBroadcastClient(conn),
+		logger:          zap.NewNop(),
+	}
+
+	for _, opt := range opts {
+		opt(orderer)
+	}
+
+	if orderer.streamMaxInFlight > 0 {
+		orderer.stream = newBroadcastStream(orderer.broadcastClient, orderer.streamMaxInFlight, orderer.logger)
 	}
 
 	return orderer, nil
 }
 
+// URI returns orderer connection target
+func (o *Orderer) URI() string {
+	return o.uri
+}
+
+// Close closes orderer connection
+func (o *Orderer) Close() error {
+	if o.stream != nil {
+		_ = o.stream.Close()
+	}
+	return o.conn.Close()
+}
+
 func (o *Orderer) Broadcast(ctx context.Context, envelope *common.Envelope) (resp *fabricOrderer.BroadcastResponse, err error) {
+	if o.stream != nil {
+		return o.stream.Broadcast(ctx, envelope)
+	}
+
 	cli, err := o.broadcastClient.Broadcast(ctx)
 	if err != nil {
-		err = fmt.Errorf(`initialize broadcast client: %w`, err)
+		err = fmt.Errorf(`%w: initialize broadcast client: %w`, ErrBroadcastEnvelopeNotSent, err)
 		return
 	}
 
@@ -107,9 +171,9 @@ func (o *Orderer) Broadcast(ctx context.Context, envelope *common.Envelope) (res
 		return
 	} else {
 		if resp.Status != common.Status_SUCCESS {
-			err = &ErrUnexpectedStatus{
-				status:  resp.Status,
-				message: resp.Info,
+			err = &api.OrdererStatusError{
+				Status: resp.Status,
+				Info:   resp.Info,
 			}
 			return
 		}
@@ -118,50 +182,56 @@ func (o *Orderer) Broadcast(ctx context.Context, envelope *common.Envelope) (res
 	return
 }
 
-func (o *Orderer) Deliver(ctx context.Context, envelope *common.Envelope) (block *common.Block, err error) {
-	cli, deliverErr := o.broadcastClient.Deliver(ctx)
-	if deliverErr != nil {
-		return nil, fmt.Errorf(`initialize deliver client: %w`, err)
+// Deliver returns the first block of seek envelope range
+func (o *Orderer) Deliver(ctx context.Context, envelope *common.Envelope) (*common.Block, error) {
+	// stream is closed after the first block
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cli, err := o.deliverStream(ctx, envelope)
+	if err != nil {
+		return nil, err
 	}
 
-	waitc := make(chan struct{})
+	for {
+		resp, err := cli.Recv()
+		if err == io.EOF {
+			return nil, nil
+		}
 
-	go func() {
-		defer close(waitc)
-		for {
-			resp, errR := cli.Recv()
-			if errR == io.EOF {
-				return
-			}
+		if err != nil {
+			return nil, fmt.Errorf(`receive response: %w`, err)
+		}
 
-			if errR != nil {
-				err = fmt.Errorf(`receive response: %w`, errR)
-				return
+		switch respType := resp.Type.(type) {
+		case *fabricOrderer.DeliverResponse_Status:
+			if respType.Status != common.Status_SUCCESS {
+				return nil, &api.OrdererStatusError{Status: respType.Status}
 			}
+			return nil, nil
 
-			switch respType := resp.Type.(type) {
-			case *fabricOrderer.DeliverResponse_Status:
-				if respType.Status != common.Status_SUCCESS {
-					err = &ErrUnexpectedStatus{status: respType.Status}
-				} else {
-					err = nil
-					return
-				}
-			case *fabricOrderer.DeliverResponse_Block:
-				block = respType.Block
-				return
-			}
+		case *fabricOrderer.DeliverResponse_Block:
+			return respType.Block, nil
 		}
-	}()
+	}
+}
+
+// deliverStream opens deliver stream and sends seek envelope to it
+func (o *Orderer) deliverStream(ctx context.Context, envelope *common.Envelope) (fabricOrderer.AtomicBroadcast_DeliverClient, error) {
+	cli, err := o.broadcastClient.Deliver(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(`initialize deliver client: %w`, err)
+	}
 
 	if err = cli.Send(envelope); err != nil {
-		err = fmt.Errorf(`send envelope: %w`, err)
-		return
+		return nil, fmt.Errorf(`send envelope: %w`, err)
 	}
 
-	err = cli.CloseSend()
-	<-waitc
-	return
+	if err = cli.CloseSend(); err != nil {
+		return nil, fmt.Errorf(`close send: %w`, err)
+	}
+
+	return cli, nil
 }
 
 // GetConfigBlock returns config block by channel name
@@ -0,0 +1,477 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	mspproto "github.com/hyperledger/fabric-protos-go/msp"
+	fabricOrderer "github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protoutil"
+	"go.uber.org/zap"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/api/config"
+	"github.com/s7techlab/hlf-sdk-go/block"
+	clienterrors "github.com/s7techlab/hlf-sdk-go/client/errors"
+	"github.com/s7techlab/hlf-sdk-go/crypto"
+)
+
+var _ api.Orderer = (*BFTOrderer)(nil)
+
+// BFTConsenter - consenter of BFT orderer with connection to it
+type BFTConsenter struct {
+	Consenter *common.Consenter
+	Orderer   api.Orderer
+}
+
+// BFTOrderer broadcasts envelopes to consenters of BFT (Fabric v3) orderer and succeeds,
+// when f+1 consenters acknowledged envelope, so at least one honest consenter received it.
+// Delivered blocks are verified to be signed by quorum of consenters
+type BFTOrderer struct {
+	logger       *zap.Logger
+	broadcastAll bool
+	verify       bool
+	suite        crypto.Suite
+	// connection - template of consenters connections, if set, consenters are dialed on channel config update
+	connection *config.ConnectionConfig
+
+	mu         sync.RWMutex
+	consenters []BFTConsenter
+}
+
+// BFTOrdererOpt describes option which will be applied to BFTOrderer
+type BFTOrdererOpt func(o *BFTOrderer)
+
+// WithBFTBroadcastAll sends envelope to all consenters instead of f+1,
+// broadcast returns as soon as f+1 consenters acknowledged envelope
+func WithBFTBroadcastAll() BFTOrdererOpt {
+	return func(o *BFTOrderer) {
+		o.broadcastAll = true
+	}
+}
+
+// WithoutBFTBlockVerification disables verification of delivered blocks signatures
+func WithoutBFTBlockVerification() BFTOrdererOpt {
+	return func(o *BFTOrderer) {
+		o.verify = false
+	}
+}
+
+// WithBFTCryptoSuite sets crypto suite for block signatures verification, default is crypto.DefaultSuite
+func WithBFTCryptoSuite(suite crypto.Suite) BFTOrdererOpt {
+	return func(o *BFTOrderer) {
+		o.suite = suite
+	}
+}
+
+// NewBFTOrderer creates orderer from all channel consenters, consenters without orderer are used
+// only for blocks verification, at least one consenter must have orderer
+func NewBFTOrderer(consenters []BFTConsenter, log *zap.Logger, opts ...BFTOrdererOpt) (*BFTOrderer, error) {
+	o := &BFTOrderer{
+		logger:     log.Named(`bft-orderer`),
+		consenters: consenters,
+		verify:     true,
+		suite:      crypto.DefaultSuite,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if len(connectedBFTConsenters(o.consenters)) == 0 {
+		return nil, ErrNoOrderers
+	}
+
+	return o, nil
+}
+
+// NewBFTOrdererFromConfig creates orderer from consenters of channel config: Orderers value (Fabric v3)
+// or SmartBFT consensus type metadata. Connection is used as template for each consenter:
+// host is set from consenter, consenter server TLS certificate is used as CA certificate, if CA isn't set.
+// Consenters are dialed without waiting for connection, consenters with invalid connection config
+// are used only for blocks verification. Consenters are updated from delivered config blocks
+func NewBFTOrdererFromConfig(
+	ctx context.Context,
+	channelConfig *common.Config,
+	connection config.ConnectionConfig,
+	log *zap.Logger,
+	opts ...BFTOrdererOpt,
+) (*BFTOrderer, error) {
+	consenters, err := block.ParseOrdererConsenters(*channelConfig)
+	if err != nil {
+		return nil, fmt.Errorf(`parse BFT consenters: %w`, err)
+	}
+
+	if len(consenters) == 0 {
+		return nil, ErrNoOrderers
+	}
+
+	bftConsenters := make([]BFTConsenter, len(consenters))
+	for i, consenter := range consenters {
+		bftConsenters[i] = dialBFTConsenter(ctx, consenter, connection, log)
+	}
+
+	o, err := NewBFTOrderer(bftConsenters, log, opts...)
+	if err != nil {
+		return nil, err
+	}
+	o.connection = &connection
+
+	return o, nil
+}
+
+// dialBFTConsenter dials consenter with connection template, consenter without connection is returned,
+// if it can't be dialed
+func dialBFTConsenter(
+	ctx context.Context, consenter *common.Consenter, connection config.ConnectionConfig, log *zap.Logger) BFTConsenter {
+	conn := connection
+	conn.Host = net.JoinHostPort(consenter.Host, strconv.Itoa(int(consenter.Port)))
+	if conn.Tls.Enabled && len(conn.Tls.CACert) == 0 && conn.Tls.CACertPath == `` {
+		conn.Tls.CACert = consenter.ServerTlsCert
+	}
+
+	orderer, err := NewOrdererNonBlocking(ctx, conn, log)
+	if err != nil {
+		// consenter without connection is used only for blocks verification
+		log.Warn(`dial to BFT consenter`, zap.Uint32(`id`, consenter.Id), zap.Error(err))
+		return BFTConsenter{Consenter: consenter}
+	}
+
+	return BFTConsenter{Consenter: consenter, Orderer: orderer}
+}
+
+// Consenters returns current consenters of orderer
+func (o *BFTOrderer) Consenters() []BFTConsenter {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	return append([]BFTConsenter(nil), o.consenters...)
+}
+
+// ObserveConfigBlock updates consenters from config block, blocks of other types are skipped.
+// Connections of consenters with the same id and endpoint are kept, new consenters are dialed,
+// if orderer is created with NewBFTOrdererFromConfig, connections of removed consenters are closed
+func (o *BFTOrderer) ObserveConfigBlock(ctx context.Context, configBlock *common.Block) error {
+	if !protoutil.IsConfigBlock(configBlock) {
+		return nil
+	}
+
+	channelConfig, err := block.ParseConfigBlock(configBlock)
+	if err != nil {
+		return fmt.Errorf(`parse config block: %w`, err)
+	}
+
+	consenters, err := block.ParseOrdererConsenters(*channelConfig)
+	if err != nil {
+		return fmt.Errorf(`parse BFT consenters: %w`, err)
+	}
+
+	if len(consenters) == 0 {
+		return ErrNoOrderers
+	}
+
+	o.updateConsenters(ctx, consenters)
+	return nil
+}
+
+func (o *BFTOrderer) updateConsenters(ctx context.Context, consenters []*common.Consenter) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	current := make(map[string]BFTConsenter, len(o.consenters))
+	for _, c := range o.consenters {
+		current[bftConsenterKey(c.Consenter)] = c
+	}
+
+	updated := make([]BFTConsenter, len(consenters))
+	for i, consenter := range consenters {
+		key := bftConsenterKey(consenter)
+		if c, ok := current[key]; ok && c.Orderer != nil {
+			updated[i] = BFTConsenter{Consenter: consenter, Orderer: c.Orderer}
+			delete(current, key)
+			continue
+		}
+
+		updated[i] = BFTConsenter{Consenter: consenter}
+		if o.connection != nil {
+			updated[i] = dialBFTConsenter(ctx, consenter, *o.connection, o.logger)
+		}
+	}
+
+	// connections of removed consenters are closed, consenters passed to NewBFTOrderer are closed by owner
+	if o.connection != nil {
+		for _, c := range current {
+			if closer, ok := c.Orderer.(io.Closer); ok {
+				_ = closer.Close()
+			}
+		}
+	}
+
+	o.consenters = updated
+	o.logger.Info(`BFT consenters updated`, zap.Int(`consenters`, len(updated)))
+}
+
+func bftConsenterKey(c *common.Consenter) string {
+	return fmt.Sprintf(`%d/%s`, c.Id, net.JoinHostPort(c.Host, strconv.Itoa(int(c.Port))))
+}
+
+// BFTQuorum returns max number of faulty consenters f and quorum size for n consenters
+func BFTQuorum(n int) (f int, quorum int) {
+	f = (n - 1) / 3
+	// ceil((n + f + 1) / 2)
+	quorum = (n + f + 2) / 2
+	return f, quorum
+}
+
+type bftBroadcastResult struct {
+	consenter *common.Consenter
+	response  *fabricOrderer.BroadcastResponse
+	err       error
+}
+
+// Broadcast sends envelope to f+1 consenters (all with WithBFTBroadcastAll), if consenter fails,
+// envelope is sent to the next one. Returns error if f+1 consenters didn't acknowledge envelope
+// or if there are less than f+1 connected consenters
+func (o *BFTOrderer) Broadcast(ctx context.Context, envelope *common.Envelope) (*fabricOrderer.BroadcastResponse, error) {
+	all := o.Consenters()
+	consenters := connectedBFTConsenters(all)
+	f, _ := BFTQuorum(len(all))
+	required := f + 1
+
+	if len(consenters) < required {
+		return nil, fmt.Errorf(`%w: connected %d of %d consenters, required %d`,
+			ErrBFTConsentersNotConnected, len(consenters), len(all), required)
+	}
+
+	initial := required
+	if o.broadcastAll {
+		initial = len(consenters)
+	}
+
+	// buffered, so sends, which are not awaited, don't block
+	results := make(chan bftBroadcastResult, len(consenters))
+	sent, pending := 0, 0
+	send := func() {
+		c := consenters[sent]
+		sent++
+		pending++
+		go func() {
+			response, err := c.Orderer.Broadcast(ctx, envelope)
+			results <- bftBroadcastResult{consenter: c.Consenter, response: response, err: err}
+		}()
+	}
+
+	for sent < initial && sent < len(consenters) {
+		send()
+	}
+
+	acks := 0
+	errs := new(clienterrors.MultiError)
+	for pending > 0 {
+		res := <-results
+		pending--
+
+		if res.err != nil {
+			errs.Add(fmt.Errorf(`consenter id=%d: %w`, res.consenter.Id, res.err))
+			if sent < len(consenters) {
+				send()
+			}
+			continue
+		}
+
+		acks++
+		if acks >= required {
+			return res.response, nil
+		}
+	}
+
+	return nil, fmt.Errorf(`%w: acknowledged %d of required %d: %w`, ErrBFTQuorumNotReached, acks, required, errs)
+}
+
+// Deliver fetches block from consenter, failing over to the next one, and verifies block signatures
+func (o *BFTOrderer) Deliver(ctx context.Context, envelope *common.Envelope) (*common.Block, error) {
+	return o.fetch(ctx, func(orderer api.Orderer) (*common.Block, error) {
+		return orderer.Deliver(ctx, envelope)
+	})
+}
+
+// GetConfigBlock returns last config block from consenter, failing over to the next one, and verifies its signatures
+func (o *BFTOrderer) GetConfigBlock(ctx context.Context, signer msp.SigningIdentity, channelName string) (*common.Block, error) {
+	return o.fetch(ctx, func(orderer api.Orderer) (*common.Block, error) {
+		return orderer.GetConfigBlock(ctx, signer, channelName)
+	})
+}
+
+// Close closes consenters connections
+func (o *BFTOrderer) Close() error {
+	errs := new(clienterrors.MultiError)
+	for _, c := range connectedBFTConsenters(o.Consenters()) {
+		if closer, ok := c.Orderer.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs.Add(fmt.Errorf(`close consenter id=%d: %w`, c.Consenter.Id, err))
+			}
+		}
+	}
+
+	if len(errs.Errors) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (o *BFTOrderer) fetch(ctx context.Context, call func(orderer api.Orderer) (*common.Block, error)) (*common.Block, error) {
+	errs := new(clienterrors.MultiError)
+	for _, c := range connectedBFTConsenters(o.Consenters()) {
+		b, err := call(c.Orderer)
+		if err == nil && o.verify {
+			err = o.VerifyBlock(b)
+		}
+
+		if err == nil {
+			// consenters of verified config block are used for next requests and blocks verification
+			if protoutil.IsConfigBlock(b) {
+				if err = o.ObserveConfigBlock(ctx, b); err != nil {
+					o.logger.Warn(`update BFT consenters from config block`, zap.Error(err))
+				}
+			}
+			return b, nil
+		}
+
+		if ctx.Err() != nil {
+			return nil, err
+		}
+
+		o.logger.Warn(`fetch block from BFT consenter`, zap.Uint32(`id`, c.Consenter.Id), zap.Error(err))
+		errs.Add(fmt.Errorf(`consenter id=%d: %w`, c.Consenter.Id, err))
+	}
+
+	return nil, errs
+}
+
+// VerifyBlock verifies that block is signed by quorum of orderer consenters
+func (o *BFTOrderer) VerifyBlock(b *common.Block) error {
+	current := o.Consenters()
+	consenters := make([]*common.Consenter, len(current))
+	for i, c := range current {
+		consenters[i] = c.Consenter
+	}
+
+	return VerifyBFTBlockSignatures(b, consenters, o.suite)
+}
+
+// connectedBFTConsenters returns consenters with connection
+func connectedBFTConsenters(all []BFTConsenter) []BFTConsenter {
+	var consenters []BFTConsenter
+	for _, c := range all {
+		if c.Orderer != nil {
+			consenters = append(consenters, c)
+		}
+	}
+	return consenters
+}
+
+// VerifyBFTBlockSignatures verifies that block is signed by quorum of consenters.
+// Genesis block has no signatures and isn't verified
+func VerifyBFTBlockSignatures(b *common.Block, consenters []*common.Consenter, suite crypto.Suite) error {
+	if b.GetHeader().GetNumber() == 0 {
+		return nil
+	}
+
+	metadata, err := protoutil.GetMetadataFromBlock(b, common.BlockMetadataIndex_SIGNATURES)
+	if err != nil {
+		return fmt.Errorf(`get signatures metadata: %w`, err)
+	}
+
+	headerBytes := protoutil.BlockHeaderBytes(b.Header)
+	signed := make(map[uint32]struct{})
+
+	for _, signature := range metadata.Signatures {
+		consenter, header, err := bftSigner(signature, consenters)
+		if err != nil {
+			continue
+		}
+
+		if _, ok := signed[consenter.Id]; ok {
+			continue
+		}
+
+		publicKey, err := bftConsenterPublicKey(consenter)
+		if err != nil {
+			continue
+		}
+
+		msg := bytes.Join([][]byte{metadata.Value, header, headerBytes}, nil)
+		if err = suite.Verify(publicKey, msg, signature.Signature); err != nil {
+			continue
+		}
+
+		signed[consenter.Id] = struct{}{}
+	}
+
+	if _, quorum := BFTQuorum(len(consenters)); len(signed) < quorum {
+		return fmt.Errorf(`%w: block=%d signed by %d consenters, quorum is %d`,
+			ErrBFTBlockSignatures, b.GetHeader().GetNumber(), len(signed), quorum)
+	}
+
+	return nil
+}
+
+// bftSigner returns consenter, which made signature, and signature header bytes
+func bftSigner(signature *common.MetadataSignature, consenters []*common.Consenter) (*common.Consenter, []byte, error) {
+	if len(signature.IdentifierHeader) > 0 {
+		identifierHeader := &common.IdentifierHeader{}
+		if err := proto.Unmarshal(signature.IdentifierHeader, identifierHeader); err != nil {
+			return nil, nil, err
+		}
+
+		for _, c := range consenters {
+			if c.Id == identifierHeader.Identifier {
+				return c, signature.IdentifierHeader, nil
+			}
+		}
+
+		return nil, nil, ErrBFTBlockSignatures
+	}
+
+	signatureHeader, err := protoutil.UnmarshalSignatureHeader(signature.SignatureHeader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, c := range consenters {
+		if bytes.Equal(c.Identity, signatureHeader.Creator) {
+			return c, signature.SignatureHeader, nil
+		}
+	}
+
+	return nil, nil, ErrBFTBlockSignatures
+}
+
+func bftConsenterPublicKey(consenter *common.Consenter) (interface{}, error) {
+	identity := &mspproto.SerializedIdentity{}
+	if err := proto.Unmarshal(consenter.Identity, identity); err != nil {
+		return nil, err
+	}
+
+	certBlock, _ := pem.Decode(identity.IdBytes)
+	if certBlock == nil {
+		return nil, fmt.Errorf(`consenter id=%d: certificate is not PEM encoded`, consenter.Id)
+	}
+
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return cert.PublicKey, nil
+}
@@ -0,0 +1,281 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	stdcrypto "crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	mspproto "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/block"
+	"github.com/s7techlab/hlf-sdk-go/block/smartbft"
+	"github.com/s7techlab/hlf-sdk-go/client"
+	"github.com/s7techlab/hlf-sdk-go/crypto"
+)
+
+// bftConsenterMock acknowledges broadcast, if err isn't set, and delivers block
+type bftConsenterMock struct {
+	api.Orderer
+
+	mu         sync.Mutex
+	err        error
+	broadcasts int
+	block      *common.Block
+}
+
+func (o *bftConsenterMock) Broadcast(context.Context, *common.Envelope) (*orderer.BroadcastResponse, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.broadcasts++
+	if o.err != nil {
+		return nil, o.err
+	}
+	return &orderer.BroadcastResponse{Status: common.Status_SUCCESS}, nil
+}
+
+func (o *bftConsenterMock) Deliver(context.Context, *common.Envelope) (*common.Block, error) {
+	return o.block, nil
+}
+
+func (o *bftConsenterMock) broadcasted() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.broadcasts
+}
+
+type bftTestConsenter struct {
+	consenter *common.Consenter
+	key       interface{}
+}
+
+func newBFTTestConsenter(t *testing.T, id uint32) bftTestConsenter {
+	key, err := crypto.DefaultSuite.NewPrivateKey()
+	require.NoError(t, err)
+
+	signer := key.(stdcrypto.Signer)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(int64(id)),
+		Subject:      pkix.Name{CommonName: `orderer`},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(nil, template, template, signer.Public(), key)
+	require.NoError(t, err)
+
+	identity, err := proto.Marshal(&mspproto.SerializedIdentity{
+		Mspid:   `OrdererMSP`,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: `CERTIFICATE`, Bytes: der}),
+	})
+	require.NoError(t, err)
+
+	return bftTestConsenter{consenter: &common.Consenter{Id: id, MspId: `OrdererMSP`, Identity: identity}, key: key}
+}
+
+// signedBlock returns block signed by consenters
+func signedBlock(t *testing.T, number uint64, signers ...bftTestConsenter) *common.Block {
+	b := protoutil.NewBlock(number, []byte(`prev`))
+	metadata := &common.Metadata{Value: []byte(`last config`)}
+
+	for _, s := range signers {
+		identifierHeader, err := proto.Marshal(&common.IdentifierHeader{Identifier: s.consenter.Id, Nonce: []byte(`nonce`)})
+		require.NoError(t, err)
+
+		msg := bytes.Join([][]byte{metadata.Value, identifierHeader, protoutil.BlockHeaderBytes(b.Header)}, nil)
+		signature, err := crypto.DefaultSuite.Sign(msg, s.key)
+		require.NoError(t, err)
+
+		metadata.Signatures = append(metadata.Signatures,
+			&common.MetadataSignature{IdentifierHeader: identifierHeader, Signature: signature})
+	}
+
+	b.Metadata.Metadata[common.BlockMetadataIndex_SIGNATURES] = protoutil.MarshalOrPanic(metadata)
+	return b
+}
+
+// configBlock returns config block with orderer group values
+func configBlock(number uint64, ordererValues map[string]proto.Message) *common.Block {
+	values := make(map[string]*common.ConfigValue, len(ordererValues))
+	for key, value := range ordererValues {
+		values[key] = &common.ConfigValue{Value: protoutil.MarshalOrPanic(value)}
+	}
+
+	envelope := &common.Envelope{Payload: protoutil.MarshalOrPanic(&common.Payload{
+		Header: &common.Header{ChannelHeader: protoutil.MarshalOrPanic(
+			&common.ChannelHeader{Type: int32(common.HeaderType_CONFIG), ChannelId: `channel`})},
+		Data: protoutil.MarshalOrPanic(&common.ConfigEnvelope{Config: &common.Config{
+			ChannelGroup: &common.ConfigGroup{Groups: map[string]*common.ConfigGroup{
+				`Orderer`: {Values: values},
+			}},
+		}}),
+	})}
+
+	b := protoutil.NewBlock(number, []byte(`prev`))
+	b.Data.Data = [][]byte{protoutil.MarshalOrPanic(envelope)}
+	return b
+}
+
+func TestBFTQuorum(t *testing.T) {
+	for n, expected := range map[int][2]int{1: {0, 1}, 4: {1, 3}, 5: {1, 4}, 7: {2, 5}, 10: {3, 7}} {
+		f, quorum := client.BFTQuorum(n)
+		assert.Equal(t, expected, [2]int{f, quorum}, `n=%d`, n)
+	}
+}
+
+func TestBFTOrderer(t *testing.T) {
+	ctx := context.Background()
+
+	var testConsenters []bftTestConsenter
+	var consenters []*common.Consenter
+	for id := uint32(1); id <= 4; id++ {
+		c := newBFTTestConsenter(t, id)
+		testConsenters = append(testConsenters, c)
+		consenters = append(consenters, c.consenter)
+	}
+
+	newOrderer := func(mocks []*bftConsenterMock, opts ...client.BFTOrdererOpt) *client.BFTOrderer {
+		var bftConsenters []client.BFTConsenter
+		for i, m := range mocks {
+			bftConsenters = append(bftConsenters, client.BFTConsenter{Consenter: consenters[i], Orderer: m})
+		}
+
+		o, err := client.NewBFTOrderer(bftConsenters, zap.NewNop(), opts...)
+		require.NoError(t, err)
+		return o
+	}
+
+	t.Run(`broadcast to f+1 consenters`, func(t *testing.T) {
+		mocks := []*bftConsenterMock{{}, {}, {}, {}}
+		_, err := newOrderer(mocks).Broadcast(ctx, &common.Envelope{})
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{1, 1, 0, 0}, []int{
+			mocks[0].broadcasted(), mocks[1].broadcasted(), mocks[2].broadcasted(), mocks[3].broadcasted()})
+	})
+
+	t.Run(`failed consenter is replaced`, func(t *testing.T) {
+		unavailable := &api.OrdererStatusError{Status: common.Status_SERVICE_UNAVAILABLE}
+		mocks := []*bftConsenterMock{{err: unavailable}, {}, {err: unavailable}, {}}
+		_, err := newOrderer(mocks).Broadcast(ctx, &common.Envelope{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, mocks[3].broadcasted())
+	})
+
+	t.Run(`broadcast to all consenters`, func(t *testing.T) {
+		mocks := []*bftConsenterMock{{}, {}, {}, {}}
+		_, err := newOrderer(mocks, client.WithBFTBroadcastAll()).Broadcast(ctx, &common.Envelope{})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			return mocks[2].broadcasted() == 1 && mocks[3].broadcasted() == 1
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run(`quorum not reached`, func(t *testing.T) {
+		rejected := &api.OrdererStatusError{Status: common.Status_BAD_REQUEST}
+		mocks := []*bftConsenterMock{{err: rejected}, {err: rejected}, {err: rejected}, {}}
+		_, err := newOrderer(mocks).Broadcast(ctx, &common.Envelope{})
+		assert.ErrorIs(t, err, client.ErrBFTQuorumNotReached)
+
+		var statusErr *api.OrdererStatusError
+		assert.ErrorAs(t, err, &statusErr)
+	})
+
+	t.Run(`not enough connected consenters`, func(t *testing.T) {
+		connected := &bftConsenterMock{}
+		// consenters without connection are used only for blocks verification
+		o, err := client.NewBFTOrderer([]client.BFTConsenter{
+			{Consenter: consenters[0], Orderer: connected},
+			{Consenter: consenters[1]}, {Consenter: consenters[2]}, {Consenter: consenters[3]},
+		}, zap.NewNop())
+		require.NoError(t, err)
+
+		_, err = o.Broadcast(ctx, &common.Envelope{})
+		assert.ErrorIs(t, err, client.ErrBFTConsentersNotConnected)
+		assert.Equal(t, 0, connected.broadcasted())
+	})
+
+	t.Run(`deliver verifies block signatures`, func(t *testing.T) {
+		quorumSigned := signedBlock(t, 5, testConsenters[0], testConsenters[1], testConsenters[2])
+		mocks := []*bftConsenterMock{
+			// signed by f+1 consenters only
+			{block: signedBlock(t, 5, testConsenters[0], testConsenters[1])},
+			{block: quorumSigned}, {}, {},
+		}
+
+		b, err := newOrderer(mocks).Deliver(ctx, &common.Envelope{})
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(quorumSigned, b))
+	})
+
+	t.Run(`consenters are updated from config block`, func(t *testing.T) {
+		mocks := []*bftConsenterMock{{}, {}, {}, {}}
+		o := newOrderer(mocks)
+
+		added := newBFTTestConsenter(t, 5).consenter
+		err := o.ObserveConfigBlock(ctx, configBlock(6, map[string]proto.Message{
+			block.OrderersKey: &common.Orderers{ConsenterMapping: []*common.Consenter{
+				consenters[0], consenters[1], consenters[2], added}},
+		}))
+		require.NoError(t, err)
+
+		updated := o.Consenters()
+		require.Len(t, updated, 4)
+		// connection of kept consenter is reused, consenter of orderer without connection template isn't dialed
+		assert.Same(t, mocks[0], updated[0].Orderer)
+		assert.Equal(t, uint32(5), updated[3].Consenter.Id)
+		assert.Nil(t, updated[3].Orderer)
+
+		// SmartBFT consenters are set in consensus type metadata
+		err = o.ObserveConfigBlock(ctx, configBlock(7, map[string]proto.Message{
+			`ConsensusType`: &orderer.ConsensusType{
+				Type: block.ConsensusTypeSmartBFT,
+				Metadata: protoutil.MarshalOrPanic(&smartbft.ConfigMetadata{Consenters: []*smartbft.Consenter{
+					{ConsenterId: 1, Identity: consenters[0].Identity},
+					{ConsenterId: 2, Identity: consenters[1].Identity},
+				}}),
+			},
+		}))
+		require.NoError(t, err)
+
+		updated = o.Consenters()
+		require.Len(t, updated, 2)
+		assert.Equal(t, consenters[1].Identity, updated[1].Consenter.Identity)
+	})
+
+	t.Run(`signatures verification`, func(t *testing.T) {
+		suite := crypto.DefaultSuite
+
+		assert.NoError(t, client.VerifyBFTBlockSignatures(
+			signedBlock(t, 1, testConsenters[3], testConsenters[1], testConsenters[0]), consenters, suite))
+
+		// duplicated signatures are counted once
+		err := client.VerifyBFTBlockSignatures(
+			signedBlock(t, 1, testConsenters[0], testConsenters[0], testConsenters[1]), consenters, suite)
+		assert.ErrorIs(t, err, client.ErrBFTBlockSignatures)
+
+		// signature of another block
+		b := signedBlock(t, 1, testConsenters[0], testConsenters[1], testConsenters[2])
+		b.Header.Number = 2
+		err = client.VerifyBFTBlockSignatures(b, consenters, suite)
+		assert.ErrorIs(t, err, client.ErrBFTBlockSignatures)
+
+		// genesis block isn't signed
+		assert.NoError(t, client.VerifyBFTBlockSignatures(protoutil.NewBlock(0, nil), consenters, suite))
+	})
+}
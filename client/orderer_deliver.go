@@ -0,0 +1,290 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	fabricOrderer "github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric/msp"
+	"go.uber.org/zap"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/block"
+	clienterrors "github.com/s7techlab/hlf-sdk-go/client/errors"
+	"github.com/s7techlab/hlf-sdk-go/client/tx"
+)
+
+const (
+	DefaultDeliverBlocksRetryMax   = 10
+	DefaultDeliverBlocksRetryDelay = time.Second
+)
+
+var _ api.BlockSubscription = (*BlocksSubscription)(nil)
+
+type deliverBlocksOpts struct {
+	retryMax    uint
+	retryDelay  time.Duration
+	tlsCertHash []byte
+	logger      *zap.Logger
+}
+
+// DeliverBlocksOpt describes option which will be applied to DeliverBlocks
+type DeliverBlocksOpt func(opts *deliverBlocksOpts)
+
+// WithDeliverBlocksRetry sets max number of consecutive reconnects after stream failure
+// and delay between them, default: DefaultDeliverBlocksRetryMax, DefaultDeliverBlocksRetryDelay
+func WithDeliverBlocksRetry(max uint, delay time.Duration) DeliverBlocksOpt {
+	return func(opts *deliverBlocksOpts) {
+		opts.retryMax = max
+		opts.retryDelay = delay
+	}
+}
+
+// WithDeliverBlocksTLSCertHash sets hash of client TLS certificate, required by orderer with mutual TLS
+func WithDeliverBlocksTLSCertHash(tlsCertHash []byte) DeliverBlocksOpt {
+	return func(opts *deliverBlocksOpts) {
+		opts.tlsCertHash = tlsCertHash
+	}
+}
+
+// WithDeliverBlocksLogger sets logger of reconnects
+func WithDeliverBlocksLogger(logger *zap.Logger) DeliverBlocksOpt {
+	return func(opts *deliverBlocksOpts) {
+		opts.logger = logger
+	}
+}
+
+// BlocksSubscription - stream of blocks range. Blocks channel is closed, when range is delivered,
+// subscription is closed or it fails, in the last case error is sent to errors channel before
+type BlocksSubscription struct {
+	blocks chan *common.Block
+	errs   chan error
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (s *BlocksSubscription) Blocks() <-chan *common.Block {
+	return s.blocks
+}
+
+func (s *BlocksSubscription) Errors() chan error {
+	return s.errs
+}
+
+// Close stops blocks delivery
+func (s *BlocksSubscription) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// deliverOpener opens deliver stream for seek envelope, release is called with stream error when stream is finished
+type deliverOpener func(ctx context.Context, envelope *common.Envelope) (
+	stream fabricOrderer.AtomicBroadcast_DeliverClient, release func(err error), err error)
+
+// DeliverBlocks streams channel blocks from seekFrom to seekTo positions.
+// If stream fails, it's reopened from the block next to the last received one
+func (o *Orderer) DeliverBlocks(
+	ctx context.Context,
+	channel string,
+	signer msp.SigningIdentity,
+	seekFrom, seekTo *fabricOrderer.SeekPosition,
+	opts ...DeliverBlocksOpt,
+) (*BlocksSubscription, error) {
+	return deliverBlocks(ctx, channel, signer, seekFrom, seekTo, append([]DeliverBlocksOpt{
+		WithDeliverBlocksLogger(o.logger)}, opts...),
+		func(ctx context.Context, envelope *common.Envelope) (
+			fabricOrderer.AtomicBroadcast_DeliverClient, func(err error), error) {
+			stream, err := o.deliverStream(ctx, envelope)
+			return stream, func(error) {}, err
+		})
+}
+
+// DeliverBlocks streams channel blocks from seekFrom to seekTo positions from pool orderer.
+// If stream fails, it's reopened on the next orderer from the block next to the last received one
+func (p *OrdererPool) DeliverBlocks(
+	ctx context.Context,
+	channel string,
+	signer msp.SigningIdentity,
+	seekFrom, seekTo *fabricOrderer.SeekPosition,
+	opts ...DeliverBlocksOpt,
+) (*BlocksSubscription, error) {
+	return deliverBlocks(ctx, channel, signer, seekFrom, seekTo, append([]DeliverBlocksOpt{
+		WithDeliverBlocksLogger(p.logger)}, opts...),
+		func(ctx context.Context, envelope *common.Envelope) (
+			fabricOrderer.AtomicBroadcast_DeliverClient, func(err error), error) {
+			errs := new(clienterrors.MultiError)
+			for _, o := range p.candidates() {
+				orderer, ok := o.orderer.(*Orderer)
+				if !ok {
+					continue
+				}
+
+				stream, err := orderer.deliverStream(ctx, envelope)
+				if err != nil {
+					p.failed(o, err)
+					errs.Add(fmt.Errorf(`orderer=%s: %w`, o.uri, err))
+					continue
+				}
+
+				return stream, func(err error) {
+					if ctx.Err() == nil && isOrdererUnavailable(err) {
+						p.failed(o, err)
+					}
+				}, nil
+			}
+
+			if len(errs.Errors) == 0 {
+				return nil, nil, ErrNoOrderers
+			}
+			return nil, nil, errs
+		})
+}
+
+func deliverBlocks(
+	ctx context.Context,
+	channel string,
+	signer msp.SigningIdentity,
+	seekFrom, seekTo *fabricOrderer.SeekPosition,
+	opts []DeliverBlocksOpt,
+	open deliverOpener,
+) (*BlocksSubscription, error) {
+	if signer == nil {
+		return nil, ErrSignerNotDefined
+	}
+
+	options := &deliverBlocksOpts{
+		retryMax:   DefaultDeliverBlocksRetryMax,
+		retryDelay: DefaultDeliverBlocksRetryDelay,
+		logger:     zap.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	sub := &BlocksSubscription{
+		blocks: make(chan *common.Block),
+		errs:   make(chan error, 1),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(sub.done)
+		defer close(sub.blocks)
+		defer cancel()
+
+		if err := sub.deliver(ctx, channel, signer, seekFrom, seekTo, options, open); err != nil {
+			sub.errs <- err
+		}
+	}()
+
+	return sub, nil
+}
+
+// deliver streams blocks until range is delivered, returns error if stream can't be reopened
+func (s *BlocksSubscription) deliver(
+	ctx context.Context,
+	channel string,
+	signer msp.SigningIdentity,
+	seekFrom, seekTo *fabricOrderer.SeekPosition,
+	opts *deliverBlocksOpts,
+	open deliverOpener,
+) error {
+	var (
+		next     uint64
+		received bool
+		failures uint
+	)
+
+	for {
+		from := seekFrom
+		if received {
+			// stream failed after the last block of range
+			if to := seekTo.GetSpecified(); to != nil && next > to.Number {
+				return nil
+			}
+			from = block.NewSeekSpecified(next)
+		}
+
+		envelope, err := tx.NewSeekBlockEnvelope(channel, signer, from, seekTo, opts.tlsCertHash)
+		if err != nil {
+			return fmt.Errorf(`create seek envelope: %w`, err)
+		}
+
+		var blocksReceived bool
+		streamCtx, streamCancel := context.WithCancel(ctx)
+		stream, release, err := open(streamCtx, envelope)
+		if err == nil {
+			blocksReceived, err = s.receive(ctx, stream, &next, &received)
+			release(err)
+		}
+		streamCancel()
+
+		if err == nil {
+			return nil
+		}
+
+		// stream, which delivered blocks, is not a consecutive failure
+		if blocksReceived {
+			failures = 0
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if !isOrdererUnavailable(err) || failures >= opts.retryMax {
+			return err
+		}
+
+		failures++
+		opts.logger.Warn(`deliver blocks stream failed, reconnect`,
+			zap.String(`channel`, channel), zap.Uint64(`from`, next), zap.Uint(`attempt`, failures), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(opts.retryDelay):
+		}
+	}
+}
+
+// receive sends stream blocks to subscription until orderer reports the end of range (nil error is returned)
+// or stream fails, next is updated with the number of block next to the last received one
+func (s *BlocksSubscription) receive(
+	ctx context.Context, stream fabricOrderer.AtomicBroadcast_DeliverClient, next *uint64, received *bool) (
+	blocksReceived bool, err error) {
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return blocksReceived, fmt.Errorf(`receive response: %w`, err)
+		}
+
+		switch respType := resp.Type.(type) {
+		case *fabricOrderer.DeliverResponse_Status:
+			if respType.Status != common.Status_SUCCESS {
+				return blocksReceived, &api.OrdererStatusError{Status: respType.Status}
+			}
+			return blocksReceived, nil
+
+		case *fabricOrderer.DeliverResponse_Block:
+			number := respType.Block.GetHeader().GetNumber()
+			// skip blocks, which are already received
+			if *received && number < *next {
+				continue
+			}
+
+			select {
+			case s.blocks <- respType.Block:
+			case <-ctx.Done():
+				return blocksReceived, ctx.Err()
+			}
+
+			*next, *received, blocksReceived = number+1, true, true
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package client_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/block"
+	"github.com/s7techlab/hlf-sdk-go/client"
+	"github.com/s7techlab/hlf-sdk-go/client/tx"
+	"github.com/s7techlab/hlf-sdk-go/identity"
+)
+
+// deliverServer delivers blocks of ledger with height, the first stream breaks after breakAfter blocks
+type deliverServer struct {
+	orderer.UnimplementedAtomicBroadcastServer
+
+	height     uint64
+	breakAfter int
+
+	mu     sync.Mutex
+	starts []uint64
+}
+
+func (s *deliverServer) Deliver(stream orderer.AtomicBroadcast_DeliverServer) error {
+	envelope, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	if err != nil {
+		return err
+	}
+	seekInfo := &orderer.SeekInfo{}
+	if err = proto.Unmarshal(payload.Data, seekInfo); err != nil {
+		return err
+	}
+
+	start := seekInfo.Start.GetSpecified().GetNumber()
+	stop := seekInfo.Stop.GetSpecified().GetNumber()
+
+	s.mu.Lock()
+	s.starts = append(s.starts, start)
+	breakAfter := 0
+	if len(s.starts) == 1 {
+		breakAfter = s.breakAfter
+	}
+	s.mu.Unlock()
+
+	if stop >= s.height {
+		return stream.Send(&orderer.DeliverResponse{
+			Type: &orderer.DeliverResponse_Status{Status: common.Status_NOT_FOUND}})
+	}
+
+	for number := start; number <= stop; number++ {
+		if breakAfter > 0 && number == start+uint64(breakAfter) {
+			return status.Error(codes.Unavailable, `orderer is restarting`)
+		}
+
+		if err = stream.Send(&orderer.DeliverResponse{
+			Type: &orderer.DeliverResponse_Block{Block: protoutil.NewBlock(number, nil)}}); err != nil {
+			return err
+		}
+	}
+
+	return stream.Send(&orderer.DeliverResponse{
+		Type: &orderer.DeliverResponse_Status{Status: common.Status_SUCCESS}})
+}
+
+func (s *deliverServer) seekStarts() []uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]uint64{}, s.starts...)
+}
+
+func newDeliverOrderer(t *testing.T, server *deliverServer) *client.Orderer {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	orderer.RegisterAtomicBroadcastServer(grpcServer, server)
+
+	go func() { _ = grpcServer.Serve(listener) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(`passthrough:///bufnet`,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	o, err := client.NewOrdererFromGRPC(conn)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = o.Close() })
+
+	return o
+}
+
+func newTestSigner(t *testing.T) *identity.SigningIdentity {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: `client`},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return identity.NewSigning(`Org1MSP`, cert, key)
+}
+
+func receiveBlocks(t *testing.T, sub api.BlockSubscription) ([]uint64, error) {
+	var numbers []uint64
+	for b := range sub.Blocks() {
+		numbers = append(numbers, b.Header.Number)
+	}
+
+	select {
+	case err := <-sub.Errors():
+		return numbers, err
+	default:
+		return numbers, nil
+	}
+}
+
+func TestOrdererDeliverBlocks(t *testing.T) {
+	ctx := context.Background()
+	signer := newTestSigner(t)
+
+	t.Run(`range`, func(t *testing.T) {
+		o := newDeliverOrderer(t, &deliverServer{height: 10})
+
+		sub, err := o.DeliverBlocks(ctx, `channel`, signer, block.NewSeekSpecified(3), block.NewSeekSpecified(6))
+		require.NoError(t, err)
+
+		numbers, err := receiveBlocks(t, sub)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{3, 4, 5, 6}, numbers)
+	})
+
+	t.Run(`stream is resumed after failure`, func(t *testing.T) {
+		server := &deliverServer{height: 10, breakAfter: 2}
+		o := newDeliverOrderer(t, server)
+
+		sub, err := o.DeliverBlocks(ctx, `channel`, signer, block.NewSeekSpecified(0), block.NewSeekSpecified(5),
+			client.WithDeliverBlocksRetry(3, time.Millisecond))
+		require.NoError(t, err)
+
+		numbers, err := receiveBlocks(t, sub)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{0, 1, 2, 3, 4, 5}, numbers)
+		assert.Equal(t, []uint64{0, 2}, server.seekStarts())
+	})
+
+	t.Run(`orderer status error`, func(t *testing.T) {
+		o := newDeliverOrderer(t, &deliverServer{height: 10})
+
+		sub, err := o.DeliverBlocks(ctx, `channel`, signer, block.NewSeekSpecified(5), block.NewSeekSpecified(20),
+			client.WithDeliverBlocksRetry(2, time.Millisecond))
+		require.NoError(t, err)
+
+		_, err = receiveBlocks(t, sub)
+		var statusErr *api.OrdererStatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, common.Status_NOT_FOUND, statusErr.Status)
+	})
+
+	t.Run(`close`, func(t *testing.T) {
+		o := newDeliverOrderer(t, &deliverServer{height: math.MaxUint64})
+
+		sub, err := o.DeliverBlocks(ctx, `channel`, signer, block.NewSeekSpecified(0), block.NewSeekSpecified(1000))
+		require.NoError(t, err)
+
+		<-sub.Blocks()
+		require.NoError(t, sub.Close())
+
+		_, err = receiveBlocks(t, sub)
+		assert.NoError(t, err)
+	})
+
+	t.Run(`signer required`, func(t *testing.T) {
+		o := newDeliverOrderer(t, &deliverServer{})
+
+		_, err := o.DeliverBlocks(ctx, `channel`, nil, block.NewSeekSpecified(0), block.NewSeekSpecified(1))
+		assert.ErrorIs(t, err, client.ErrSignerNotDefined)
+	})
+
+	t.Run(`single block`, func(t *testing.T) {
+		o := newDeliverOrderer(t, &deliverServer{height: 10})
+
+		envelope, err := tx.NewSeekBlockEnvelope(`channel`, signer,
+			block.NewSeekSpecified(7), block.NewSeekSpecified(9), nil)
+		require.NoError(t, err)
+
+		b, err := o.Deliver(ctx, envelope)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(7), b.Header.Number)
+	})
+}
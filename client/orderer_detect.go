@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	fabricOrderer "github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric/msp"
+	"go.uber.org/zap"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+)
+
+var _ api.Orderer = (*detectingOrderer)(nil)
+
+// detectBFTOrderer returns BFT orderer of channel, nil is returned if channel orderer isn't BFT
+type detectBFTOrderer func(ctx context.Context) (*BFTOrderer, error)
+
+// detectingOrderer detects BFT orderer of channel on the first request, so channel creation doesn't wait
+// for channel config. Detection result is cached, requests are sent to BFT orderer, if channel orderer is BFT,
+// or to base orderer otherwise. Detection is retried on the next request, if caller's context is done
+type detectingOrderer struct {
+	base       api.Orderer
+	baseCloser io.Closer
+	detect     detectBFTOrderer
+	logger     *zap.Logger
+
+	mu       sync.Mutex
+	detected bool
+	bft      *BFTOrderer
+	// detecting - closed, when detection by one of requests is finished
+	detecting chan struct{}
+}
+
+func newDetectingOrderer(base api.Orderer, baseCloser io.Closer, detect detectBFTOrderer, logger *zap.Logger) *detectingOrderer {
+	return &detectingOrderer{base: base, baseCloser: baseCloser, detect: detect, logger: logger}
+}
+
+func (o *detectingOrderer) Broadcast(ctx context.Context, envelope *common.Envelope) (*fabricOrderer.BroadcastResponse, error) {
+	ord, err := o.orderer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ord.Broadcast(ctx, envelope)
+}
+
+func (o *detectingOrderer) Deliver(ctx context.Context, envelope *common.Envelope) (*common.Block, error) {
+	ord, err := o.orderer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ord.Deliver(ctx, envelope)
+}
+
+func (o *detectingOrderer) GetConfigBlock(ctx context.Context, signer msp.SigningIdentity, channelName string) (*common.Block, error) {
+	ord, err := o.orderer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ord.GetConfigBlock(ctx, signer, channelName)
+}
+
+// ObserveConfigBlock updates consenters of BFT orderer, config block is skipped, if orderer isn't BFT
+func (o *detectingOrderer) ObserveConfigBlock(ctx context.Context, configBlock *common.Block) error {
+	o.mu.Lock()
+	bft := o.bft
+	o.mu.Unlock()
+
+	if bft == nil {
+		return nil
+	}
+	return bft.ObserveConfigBlock(ctx, configBlock)
+}
+
+// Close closes BFT orderer, if it's detected, and base orderer, if it's created for channel
+func (o *detectingOrderer) Close() error {
+	o.mu.Lock()
+	bft := o.bft
+	o.mu.Unlock()
+
+	if bft != nil {
+		_ = bft.Close()
+	}
+	if o.baseCloser != nil {
+		return o.baseCloser.Close()
+	}
+	return nil
+}
+
+// orderer returns detected channel orderer, orderer is detected, if it isn't detected yet
+func (o *detectingOrderer) orderer(ctx context.Context) (api.Orderer, error) {
+	o.mu.Lock()
+	for !o.detected {
+		if detecting := o.detecting; detecting != nil {
+			o.mu.Unlock()
+			select {
+			case <-detecting:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			o.mu.Lock()
+			continue
+		}
+
+		detecting := make(chan struct{})
+		o.detecting = detecting
+		o.mu.Unlock()
+
+		bft, err := o.detect(ctx)
+
+		o.mu.Lock()
+		o.detecting = nil
+		close(detecting)
+
+		if err != nil && ctx.Err() != nil {
+			o.mu.Unlock()
+			return nil, ctx.Err()
+		}
+
+		if err != nil {
+			o.logger.Warn(`Failed to initialize BFT orderer, orderer pool is used`, zap.Error(err))
+		}
+
+		o.detected, o.bft = true, bft
+		if bft != nil {
+			o.logger.Info(`channel orderer is BFT`, zap.Int(`consenters`, len(bft.Consenters())))
+		}
+	}
+	bft := o.bft
+	o.mu.Unlock()
+
+	if bft != nil {
+		return bft, nil
+	}
+	return o.base, nil
+}
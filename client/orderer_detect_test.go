@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	fabricOrderer "github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+)
+
+type broadcastCounterMock struct {
+	api.Orderer
+
+	broadcasts int
+}
+
+func (o *broadcastCounterMock) Broadcast(context.Context, *common.Envelope) (*fabricOrderer.BroadcastResponse, error) {
+	o.broadcasts++
+	return &fabricOrderer.BroadcastResponse{Status: common.Status_SUCCESS}, nil
+}
+
+func TestDetectingOrderer(t *testing.T) {
+	base := &broadcastCounterMock{}
+	detects := 0
+	o := newDetectingOrderer(base, nil, func(ctx context.Context) (*BFTOrderer, error) {
+		detects++
+		return nil, ctx.Err()
+	}, zap.NewNop())
+
+	// channel orderer isn't detected, until the first request
+	assert.Equal(t, 0, detects)
+
+	// detection isn't cached, if caller's context is done
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := o.Broadcast(canceled, &common.Envelope{})
+	require.ErrorIs(t, err, context.Canceled)
+
+	for i := 0; i < 2; i++ {
+		_, err = o.Broadcast(context.Background(), &common.Envelope{})
+		require.NoError(t, err)
+	}
+
+	// channel orderer isn't BFT, result is cached
+	assert.Equal(t, 2, detects)
+	assert.Equal(t, 2, base.broadcasts)
+}
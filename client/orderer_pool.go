@@ -0,0 +1,339 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	fabricOrderer "github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/hyperledger/fabric/msp"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/api/config"
+	clienterrors "github.com/s7techlab/hlf-sdk-go/client/errors"
+)
+
+const (
+	DefaultOrdererPoolCooldown = 10 * time.Second
+)
+
+var _ api.Orderer = (*OrdererPool)(nil)
+
+// OrdererPool sends requests to one of several orderers and fails over to the next orderer,
+// if orderer is unavailable: responds with SERVICE_UNAVAILABLE or NOT_FOUND status, or fails with transport error.
+// Broadcast is failed over only if envelope didn't reach orderer, so transaction isn't submitted twice.
+// Failed orderer is marked unhealthy and is tried after healthy ones until cooldown passes.
+// Leader orderer, if known, goes first, then orderer of the last successful request
+type OrdererPool struct {
+	logger   *zap.Logger
+	cooldown time.Duration
+	now      func() time.Time
+	// ordererOpts are applied to orderers, dialed by NewOrdererPoolFromConfigs
+	ordererOpts []OrdererOpt
+
+	mu       sync.Mutex
+	orderers []*poolOrderer
+	leader   string
+	// last - index of orderer, which handled the last successful request
+	last int
+}
+
+type poolOrderer struct {
+	uri      string
+	orderer  api.Orderer
+	failures uint
+	failedAt time.Time
+	lastErr  error
+}
+
+// OrdererHealth - health state of pool orderer
+type OrdererHealth struct {
+	URI     string
+	Healthy bool
+	// Failures - consecutive failures count
+	Failures uint
+	LastErr  error
+}
+
+// OrdererPoolOpt describes option which will be applied to OrdererPool
+type OrdererPoolOpt func(p *OrdererPool)
+
+// WithOrdererPoolCooldown sets period during which failed orderer is tried only after healthy ones
+func WithOrdererPoolCooldown(cooldown time.Duration) OrdererPoolOpt {
+	return func(p *OrdererPool) {
+		p.cooldown = cooldown
+	}
+}
+
+// WithOrdererLeader sets orderer, which is tried first, i.e. known Raft or BFT leader
+func WithOrdererLeader(uri string) OrdererPoolOpt {
+	return func(p *OrdererPool) {
+		p.leader = uri
+	}
+}
+
+// WithOrdererOpts sets options of orderers, dialed by NewOrdererPoolFromConfigs
+func WithOrdererOpts(opts ...OrdererOpt) OrdererPoolOpt {
+	return func(p *OrdererPool) {
+		p.ordererOpts = opts
+	}
+}
+
+func NewOrdererPool(log *zap.Logger, opts ...OrdererPoolOpt) *OrdererPool {
+	p := &OrdererPool{
+		logger:   log.Named(`orderer-pool`),
+		cooldown: DefaultOrdererPoolCooldown,
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// NewOrdererPoolFromConfigs adds orderers to pool without waiting for connections, so orderers, which are down,
+// are tried after healthy ones and become available when they are up.
+// Orderers with invalid config are skipped, error is returned if none of them are added
+func NewOrdererPoolFromConfigs(
+	ctx context.Context, log *zap.Logger, configs []config.ConnectionConfig, opts ...OrdererPoolOpt) (*OrdererPool, error) {
+	if len(configs) == 0 {
+		return nil, ErrNoOrderers
+	}
+
+	p := NewOrdererPool(log, opts...)
+
+	dialErrs := new(clienterrors.MultiError)
+	for _, c := range configs {
+		orderer, err := NewOrdererNonBlocking(ctx, c, log, p.ordererOpts...)
+		if err != nil {
+			p.logger.Warn(`dial to orderer`, zap.String(`host`, c.Host), zap.Error(err))
+			dialErrs.Add(fmt.Errorf(`orderer=%s: %w`, c.Host, err))
+			continue
+		}
+
+		p.Add(c.Host, orderer)
+	}
+
+	if len(p.orderers) == 0 {
+		return nil, dialErrs
+	}
+
+	return p, nil
+}
+
+// Add adds orderer with uri to pool
+func (p *OrdererPool) Add(uri string, orderer api.Orderer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.orderers = append(p.orderers, &poolOrderer{uri: uri, orderer: orderer})
+}
+
+// SetLeader sets orderer, which is tried first, empty uri resets leader
+func (p *OrdererPool) SetLeader(uri string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.leader = uri
+}
+
+// Health returns health state of pool orderers
+func (p *OrdererPool) Health() []OrdererHealth {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	health := make([]OrdererHealth, len(p.orderers))
+	for i, o := range p.orderers {
+		health[i] = OrdererHealth{
+			URI:      o.uri,
+			Healthy:  p.healthy(o),
+			Failures: o.failures,
+			LastErr:  o.lastErr,
+		}
+	}
+
+	return health
+}
+
+func (p *OrdererPool) Broadcast(ctx context.Context, envelope *common.Envelope) (*fabricOrderer.BroadcastResponse, error) {
+	var resp *fabricOrderer.BroadcastResponse
+	err := p.do(ctx, isEnvelopeNotReceived, func(orderer api.Orderer) (err error) {
+		resp, err = orderer.Broadcast(ctx, envelope)
+		return err
+	})
+
+	return resp, err
+}
+
+func (p *OrdererPool) Deliver(ctx context.Context, envelope *common.Envelope) (*common.Block, error) {
+	var block *common.Block
+	err := p.do(ctx, isOrdererUnavailable, func(orderer api.Orderer) (err error) {
+		block, err = orderer.Deliver(ctx, envelope)
+		return err
+	})
+
+	return block, err
+}
+
+func (p *OrdererPool) GetConfigBlock(ctx context.Context, signer msp.SigningIdentity, channelName string) (*common.Block, error) {
+	var block *common.Block
+	err := p.do(ctx, isOrdererUnavailable, func(orderer api.Orderer) (err error) {
+		block, err = orderer.GetConfigBlock(ctx, signer, channelName)
+		return err
+	})
+
+	return block, err
+}
+
+// Close closes pool orderers, which can be closed. Connections are closed without pool lock
+func (p *OrdererPool) Close() error {
+	p.mu.Lock()
+	orderers := p.orderers
+	p.mu.Unlock()
+
+	errs := new(clienterrors.MultiError)
+	for _, o := range orderers {
+		if closer, ok := o.orderer.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs.Add(fmt.Errorf(`close orderer=%s: %w`, o.uri, err))
+			}
+		}
+	}
+
+	if len(errs.Errors) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// do calls orderers in preferred order until call succeeds or fails with error, which can't be retried.
+// Orderer is marked failed, if it's unavailable, even if call isn't retried on the next orderer
+func (p *OrdererPool) do(ctx context.Context, retryable func(err error) bool, call func(orderer api.Orderer) error) error {
+	orderers := p.candidates()
+	if len(orderers) == 0 {
+		return ErrNoOrderers
+	}
+
+	errs := new(clienterrors.MultiError)
+	for _, o := range orderers {
+		err := call(o.orderer)
+		if err == nil {
+			p.succeeded(o)
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return err
+		}
+
+		if isOrdererUnavailable(err) {
+			p.failed(o, err)
+		}
+
+		if !retryable(err) {
+			return err
+		}
+		errs.Add(fmt.Errorf(`orderer=%s: %w`, o.uri, err))
+	}
+
+	return errs
+}
+
+// candidates returns orderers in order of trying: leader, orderer of the last successful request,
+// other healthy orderers and unhealthy ones
+func (p *OrdererPool) candidates() []*poolOrderer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var leader, healthy, unhealthy []*poolOrderer
+	for i := range p.orderers {
+		o := p.orderers[(p.last+i)%len(p.orderers)]
+
+		switch {
+		case !p.healthy(o):
+			unhealthy = append(unhealthy, o)
+		case p.leader != `` && o.uri == p.leader:
+			leader = append(leader, o)
+		default:
+			healthy = append(healthy, o)
+		}
+	}
+
+	return append(append(leader, healthy...), unhealthy...)
+}
+
+// healthy reports whether orderer didn't fail during cooldown, must be called under lock
+func (p *OrdererPool) healthy(o *poolOrderer) bool {
+	return o.failures == 0 || p.now().Sub(o.failedAt) >= p.cooldown
+}
+
+func (p *OrdererPool) succeeded(o *poolOrderer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	o.failures = 0
+	o.lastErr = nil
+	for i := range p.orderers {
+		if p.orderers[i] == o {
+			p.last = i
+		}
+	}
+}
+
+func (p *OrdererPool) failed(o *poolOrderer, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	o.failures++
+	o.failedAt = p.now()
+	o.lastErr = err
+
+	p.logger.Warn(`orderer unavailable, failover to next orderer`,
+		zap.String(`uri`, o.uri), zap.Uint(`failures`, o.failures), zap.Error(err))
+}
+
+// isOrdererUnavailable reports whether request can be retried on another orderer
+func isOrdererUnavailable(err error) bool {
+	var statusErr *api.OrdererStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Status == common.Status_SERVICE_UNAVAILABLE || statusErr.Status == common.Status_NOT_FOUND
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, ErrBroadcastEnvelopeNotProcessed) ||
+		errors.Is(err, ErrBroadcastEnvelopeNotSent) {
+		return true
+	}
+
+	s, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+
+	switch s.Code() {
+	case codes.Unavailable, codes.Aborted, codes.ResourceExhausted, codes.Internal:
+		return true
+	default:
+		return false
+	}
+}
+
+// isEnvelopeNotReceived reports whether broadcast can be retried on another orderer without duplicate submission:
+// envelope wasn't sent or orderer responded, that it didn't accept envelope.
+// Transport error after envelope is sent is returned, because orderer could accept envelope
+func isEnvelopeNotReceived(err error) bool {
+	var statusErr *api.OrdererStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Status == common.Status_SERVICE_UNAVAILABLE || statusErr.Status == common.Status_NOT_FOUND
+	}
+
+	return errors.Is(err, ErrBroadcastEnvelopeNotProcessed) || errors.Is(err, ErrBroadcastEnvelopeNotSent)
+}
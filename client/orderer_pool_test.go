@@ -0,0 +1,149 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/api/config"
+	"github.com/s7techlab/hlf-sdk-go/client"
+)
+
+// ordererMock responds to broadcast with error, if set, and counts broadcasts
+type ordererMock struct {
+	api.Orderer
+
+	err        error
+	broadcasts int
+}
+
+func (o *ordererMock) Broadcast(context.Context, *common.Envelope) (*orderer.BroadcastResponse, error) {
+	o.broadcasts++
+	if o.err != nil {
+		return nil, o.err
+	}
+	return &orderer.BroadcastResponse{Status: common.Status_SUCCESS}, nil
+}
+
+func newOrdererPool(orderers map[string]*ordererMock, uris []string, opts ...client.OrdererPoolOpt) *client.OrdererPool {
+	pool := client.NewOrdererPool(zap.NewNop(), opts...)
+	for _, uri := range uris {
+		pool.Add(uri, orderers[uri])
+	}
+	return pool
+}
+
+func TestOrdererPool(t *testing.T) {
+	ctx := context.Background()
+	unavailable := &api.OrdererStatusError{Status: common.Status_SERVICE_UNAVAILABLE}
+
+	t.Run(`failover on unavailable orderer`, func(t *testing.T) {
+		orderers := map[string]*ordererMock{
+			`o1`: {err: unavailable},
+			`o2`: {err: fmt.Errorf(`%w: %w`, client.ErrBroadcastEnvelopeNotSent, status.Error(codes.Unavailable, `connection refused`))},
+			`o3`: {},
+		}
+		pool := newOrdererPool(orderers, []string{`o1`, `o2`, `o3`})
+
+		_, err := pool.Broadcast(ctx, &common.Envelope{})
+		require.NoError(t, err)
+
+		health := pool.Health()
+		assert.False(t, health[0].Healthy)
+		assert.ErrorIs(t, health[0].LastErr, unavailable)
+		assert.False(t, health[1].Healthy)
+		assert.True(t, health[2].Healthy)
+
+		// unhealthy orderers are skipped during cooldown
+		_, err = pool.Broadcast(ctx, &common.Envelope{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, orderers[`o1`].broadcasts)
+		assert.Equal(t, 2, orderers[`o3`].broadcasts)
+	})
+
+	t.Run(`orderer rejection isn't retried`, func(t *testing.T) {
+		orderers := map[string]*ordererMock{
+			`o1`: {err: &api.OrdererStatusError{Status: common.Status_BAD_REQUEST}},
+			`o2`: {},
+		}
+		pool := newOrdererPool(orderers, []string{`o1`, `o2`})
+
+		_, err := pool.Broadcast(ctx, &common.Envelope{})
+		var statusErr *api.OrdererStatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, common.Status_BAD_REQUEST, statusErr.Status)
+		assert.Equal(t, 0, orderers[`o2`].broadcasts)
+		assert.True(t, pool.Health()[0].Healthy)
+	})
+
+	t.Run(`envelope sent to orderer isn't resent to another orderer`, func(t *testing.T) {
+		orderers := map[string]*ordererMock{
+			`o1`: {err: fmt.Errorf(`receive response: %w`, status.Error(codes.Unavailable, `connection reset`))},
+			`o2`: {},
+		}
+		pool := newOrdererPool(orderers, []string{`o1`, `o2`})
+
+		// orderer could accept envelope before connection failure
+		_, err := pool.Broadcast(ctx, &common.Envelope{})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, 0, orderers[`o2`].broadcasts)
+		assert.False(t, pool.Health()[0].Healthy)
+	})
+
+	t.Run(`leader goes first`, func(t *testing.T) {
+		orderers := map[string]*ordererMock{`o1`: {}, `o2`: {}}
+		pool := newOrdererPool(orderers, []string{`o1`, `o2`}, client.WithOrdererLeader(`o2`))
+
+		_, err := pool.Broadcast(ctx, &common.Envelope{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, orderers[`o2`].broadcasts)
+
+		pool.SetLeader(`o1`)
+		_, err = pool.Broadcast(ctx, &common.Envelope{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, orderers[`o1`].broadcasts)
+	})
+
+	t.Run(`unhealthy orderers are tried after cooldown`, func(t *testing.T) {
+		orderers := map[string]*ordererMock{`o1`: {err: unavailable}, `o2`: {err: unavailable}}
+		pool := newOrdererPool(orderers, []string{`o1`, `o2`}, client.WithOrdererPoolCooldown(10*time.Millisecond))
+
+		_, err := pool.Broadcast(ctx, &common.Envelope{})
+		assert.ErrorIs(t, err, unavailable)
+
+		orderers[`o1`].err = nil
+		time.Sleep(20 * time.Millisecond)
+		_, err = pool.Broadcast(ctx, &common.Envelope{})
+		require.NoError(t, err)
+		assert.True(t, pool.Health()[0].Healthy)
+	})
+
+	t.Run(`unreachable orderer is added to pool`, func(t *testing.T) {
+		pool, err := client.NewOrdererPoolFromConfigs(ctx, zap.NewNop(), []config.ConnectionConfig{{Host: `127.0.0.1:1`}})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = pool.Close() })
+		require.Len(t, pool.Health(), 1)
+
+		_, err = pool.Broadcast(ctx, &common.Envelope{})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.False(t, pool.Health()[0].Healthy)
+	})
+
+	t.Run(`empty pool`, func(t *testing.T) {
+		_, err := client.NewOrdererPool(zap.NewNop()).Broadcast(ctx, &common.Envelope{})
+		assert.ErrorIs(t, err, client.ErrNoOrderers)
+
+		_, err = client.NewOrdererPoolFromConfigs(ctx, zap.NewNop(), nil)
+		assert.ErrorIs(t, err, client.ErrNoOrderers)
+	})
+}
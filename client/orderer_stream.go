@@ -0,0 +1,247 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	fabricOrderer "github.com/hyperledger/fabric-protos-go/orderer"
+	"go.uber.org/zap"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+)
+
+const (
+	DefaultBroadcastStreamMaxInFlight = 1000
+	// DefaultBroadcastStreamResends - max number of envelope resends, if envelope wasn't processed by orderer,
+	// because orderer closed stream after rejecting previous envelope
+	DefaultBroadcastStreamResends = 3
+)
+
+// broadcastStream sends envelopes through one long-lived broadcast stream. Orderer responds to envelopes
+// of stream in order, so responses are matched with pending envelopes queue.
+// If stream breaks, pending broadcasts fail with stream error and the next broadcast opens new stream.
+// Orderer closes stream after non-success response, so envelopes pending after rejected one are resent
+// with new stream. Number of pending broadcasts is bounded, broadcast waits for free slot
+type broadcastStream struct {
+	client   fabricOrderer.AtomicBroadcastClient
+	logger   *zap.Logger
+	inFlight chan struct{}
+
+	// sendMu serializes stream sends and pending queue appends, so queue order matches send order
+	sendMu sync.Mutex
+	mu     sync.Mutex
+	conn   *broadcastConn
+	closed bool
+}
+
+// broadcastConn - broadcast stream with queue of pending broadcasts
+type broadcastConn struct {
+	stream   fabricOrderer.AtomicBroadcast_BroadcastClient
+	cancel   context.CancelFunc
+	inFlight chan struct{}
+
+	mu      sync.Mutex
+	pending []chan broadcastResult
+	err     error
+}
+
+type broadcastResult struct {
+	response *fabricOrderer.BroadcastResponse
+	err      error
+}
+
+func newBroadcastStream(client fabricOrderer.AtomicBroadcastClient, maxInFlight int, logger *zap.Logger) *broadcastStream {
+	return &broadcastStream{
+		client:   client,
+		logger:   logger,
+		inFlight: make(chan struct{}, maxInFlight),
+	}
+}
+
+func (s *broadcastStream) Broadcast(ctx context.Context, envelope *common.Envelope) (*fabricOrderer.BroadcastResponse, error) {
+	for resend := 0; ; resend++ {
+		resp, err := s.broadcast(ctx, envelope)
+		if !errors.Is(err, ErrBroadcastEnvelopeNotProcessed) || resend >= DefaultBroadcastStreamResends {
+			return resp, err
+		}
+
+		s.logger.Debug(`resend envelope, not processed by orderer`, zap.Int(`resend`, resend+1))
+	}
+}
+
+func (s *broadcastStream) broadcast(ctx context.Context, envelope *common.Envelope) (*fabricOrderer.BroadcastResponse, error) {
+	// backpressure: wait for free in-flight slot, slot is released when broadcast result is received
+	select {
+	case s.inFlight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	// buffered, so result of broadcast with done context doesn't block stream
+	done := make(chan broadcastResult, 1)
+	if err := s.send(envelope, done); err != nil {
+		return nil, err
+	}
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			return nil, res.err
+		}
+
+		if res.response.Status != common.Status_SUCCESS {
+			return nil, &api.OrdererStatusError{
+				Status: res.response.Status,
+				Info:   res.response.Info,
+			}
+		}
+
+		return res.response, nil
+
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *broadcastStream) send(envelope *common.Envelope, done chan broadcastResult) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	conn, err := s.connection()
+	if err != nil {
+		<-s.inFlight
+		return err
+	}
+
+	if !conn.enqueue(done) {
+		return nil
+	}
+
+	if err = conn.stream.Send(envelope); err != nil {
+		// enqueued broadcast receives error too
+		conn.fail(fmt.Errorf(`send envelope: %w`, err))
+	}
+
+	return nil
+}
+
+// connection returns current stream or opens new one, if stream is broken
+func (s *broadcastStream) connection() (*broadcastConn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, fmt.Errorf(`%w: %w`, ErrBroadcastEnvelopeNotSent, ErrBroadcastStreamClosed)
+	}
+
+	if s.conn != nil && !s.conn.broken() {
+		return s.conn, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := s.client.Broadcast(ctx)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf(`%w: initialize broadcast client: %w`, ErrBroadcastEnvelopeNotSent, err)
+	}
+
+	if s.conn != nil {
+		s.logger.Debug(`broadcast stream reconnected`)
+	}
+
+	s.conn = &broadcastConn{stream: stream, cancel: cancel, inFlight: s.inFlight}
+	go s.conn.receive()
+
+	return s.conn, nil
+}
+
+// Close closes stream, pending broadcasts fail
+func (s *broadcastStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.conn != nil {
+		s.conn.fail(ErrBroadcastStreamClosed)
+	}
+
+	return nil
+}
+
+// receive matches stream responses with pending broadcasts until stream breaks
+func (c *broadcastConn) receive() {
+	for {
+		resp, err := c.stream.Recv()
+		if err != nil {
+			c.fail(fmt.Errorf(`receive response: %w`, err))
+			return
+		}
+
+		c.mu.Lock()
+		if len(c.pending) == 0 {
+			c.mu.Unlock()
+			c.fail(ErrBroadcastStreamUnexpectedResponse)
+			return
+		}
+
+		done := c.pending[0]
+		c.pending = c.pending[1:]
+		c.mu.Unlock()
+
+		// orderer closes stream after non-success response, envelopes sent after rejected one aren't processed
+		if resp.Status != common.Status_SUCCESS {
+			c.fail(fmt.Errorf(`%w: previous envelope rejected with status %s`,
+				ErrBroadcastEnvelopeNotProcessed, resp.Status))
+			c.resolve(done, broadcastResult{response: resp})
+			return
+		}
+
+		c.resolve(done, broadcastResult{response: resp})
+	}
+}
+
+// enqueue adds broadcast to pending queue, returns false and fails broadcast if stream is broken
+func (c *broadcastConn) enqueue(done chan broadcastResult) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		c.resolve(done, broadcastResult{err: c.err})
+		return false
+	}
+
+	c.pending = append(c.pending, done)
+	return true
+}
+
+// fail marks stream broken and fails pending broadcasts
+func (c *broadcastConn) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err == nil {
+		c.err = err
+		c.cancel()
+	}
+
+	for _, done := range c.pending {
+		c.resolve(done, broadcastResult{err: c.err})
+	}
+	c.pending = nil
+}
+
+func (c *broadcastConn) broken() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err != nil
+}
+
+// resolve sends broadcast result and releases in-flight slot
+func (c *broadcastConn) resolve(done chan broadcastResult, res broadcastResult) {
+	done <- res
+	<-c.inFlight
+}
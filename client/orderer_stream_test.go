@@ -0,0 +1,225 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/orderer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/client"
+)
+
+// broadcastServer responds to envelopes in order with envelope payload as info.
+// Payload `break` breaks stream, `reject` is rejected, `hold` waits for release, `hold-reject` waits
+// for release and is rejected. Like Fabric orderer, server closes stream after rejection
+type broadcastServer struct {
+	orderer.UnimplementedAtomicBroadcastServer
+
+	release chan struct{}
+
+	mu       sync.Mutex
+	streams  int
+	received int
+}
+
+func (s *broadcastServer) Broadcast(stream orderer.AtomicBroadcast_BroadcastServer) error {
+	s.mu.Lock()
+	s.streams++
+	s.mu.Unlock()
+
+	// envelopes are received ahead of responses, as orderer receives pipelined envelopes
+	envelopes := make(chan *common.Envelope, 100)
+	go func() {
+		defer close(envelopes)
+		for {
+			envelope, err := stream.Recv()
+			if err != nil {
+				return
+			}
+
+			s.mu.Lock()
+			s.received++
+			s.mu.Unlock()
+			envelopes <- envelope
+		}
+	}()
+
+	for envelope := range envelopes {
+		resp := &orderer.BroadcastResponse{Status: common.Status_SUCCESS, Info: string(envelope.Payload)}
+		switch string(envelope.Payload) {
+		case `break`:
+			return status.Error(codes.Unavailable, `orderer is restarting`)
+		case `reject`:
+			resp.Status = common.Status_BAD_REQUEST
+		case `hold`:
+			<-s.release
+		case `hold-reject`:
+			<-s.release
+			resp.Status = common.Status_BAD_REQUEST
+		}
+
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+
+		if resp.Status != common.Status_SUCCESS {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (s *broadcastServer) receivedCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.received
+}
+
+func (s *broadcastServer) streamsCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.streams
+}
+
+func newStreamOrderer(t *testing.T, maxInFlight int) (*client.Orderer, *broadcastServer) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	broadcast := &broadcastServer{release: make(chan struct{})}
+	orderer.RegisterAtomicBroadcastServer(server, broadcast)
+
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(`passthrough:///bufnet`,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	o, err := client.NewOrdererFromGRPC(conn, client.WithBroadcastStream(maxInFlight))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = o.Close() })
+
+	return o, broadcast
+}
+
+func TestOrdererBroadcastStream(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run(`pipelined broadcasts share stream`, func(t *testing.T) {
+		o, server := newStreamOrderer(t, 10)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(payload string) {
+				defer wg.Done()
+
+				resp, err := o.Broadcast(ctx, &common.Envelope{Payload: []byte(payload)})
+				if assert.NoError(t, err) {
+					assert.Equal(t, payload, resp.Info)
+				}
+			}(fmt.Sprintf(`tx%d`, i))
+		}
+		wg.Wait()
+
+		assert.Equal(t, 1, server.streamsCount())
+	})
+
+	t.Run(`rejected envelope`, func(t *testing.T) {
+		o, server := newStreamOrderer(t, 10)
+
+		_, err := o.Broadcast(ctx, &common.Envelope{Payload: []byte(`reject`)})
+		var statusErr *api.OrdererStatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, common.Status_BAD_REQUEST, statusErr.Status)
+
+		// orderer closes stream after rejection
+		_, err = o.Broadcast(ctx, &common.Envelope{Payload: []byte(`tx`)})
+		require.NoError(t, err)
+		assert.Equal(t, 2, server.streamsCount())
+	})
+
+	t.Run(`envelopes pending after rejected one are resent`, func(t *testing.T) {
+		o, server := newStreamOrderer(t, 10)
+
+		rejected := make(chan error)
+		go func() {
+			_, err := o.Broadcast(ctx, &common.Envelope{Payload: []byte(`hold-reject`)})
+			rejected <- err
+		}()
+		require.Eventually(t, func() bool { return server.receivedCount() == 1 }, time.Second, 5*time.Millisecond)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func(payload string) {
+				defer wg.Done()
+
+				resp, err := o.Broadcast(ctx, &common.Envelope{Payload: []byte(payload)})
+				if assert.NoError(t, err) {
+					assert.Equal(t, payload, resp.Info)
+				}
+			}(fmt.Sprintf(`tx%d`, i))
+		}
+		require.Eventually(t, func() bool { return server.receivedCount() == 4 }, time.Second, 5*time.Millisecond)
+
+		close(server.release)
+		var statusErr *api.OrdererStatusError
+		require.ErrorAs(t, <-rejected, &statusErr)
+		assert.Equal(t, common.Status_BAD_REQUEST, statusErr.Status)
+
+		wg.Wait()
+		assert.Equal(t, 2, server.streamsCount())
+	})
+
+	t.Run(`broken stream is reopened`, func(t *testing.T) {
+		o, server := newStreamOrderer(t, 10)
+
+		_, err := o.Broadcast(ctx, &common.Envelope{Payload: []byte(`break`)})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+
+		resp, err := o.Broadcast(ctx, &common.Envelope{Payload: []byte(`tx`)})
+		require.NoError(t, err)
+		assert.Equal(t, `tx`, resp.Info)
+		assert.Equal(t, 2, server.streamsCount())
+	})
+
+	t.Run(`in-flight broadcasts are bounded`, func(t *testing.T) {
+		o, server := newStreamOrderer(t, 1)
+
+		held := make(chan error)
+		go func() {
+			_, err := o.Broadcast(ctx, &common.Envelope{Payload: []byte(`hold`)})
+			held <- err
+		}()
+
+		require.Eventually(t, func() bool { return server.streamsCount() == 1 }, time.Second, 5*time.Millisecond)
+
+		waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, err := o.Broadcast(waitCtx, &common.Envelope{Payload: []byte(`tx`)})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		close(server.release)
+		require.NoError(t, <-held)
+
+		_, err = o.Broadcast(ctx, &common.Envelope{Payload: []byte(`tx`)})
+		require.NoError(t, err)
+	})
+}
@@ -16,23 +16,52 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/api/config"
 	clienterrors "github.com/s7techlab/hlf-sdk-go/client/errors"
 )
 
 var ErrEndorsingMSPsRequired = errors.New(`endorsing MSPs required`)
 
 type PeerPool struct {
-	ctx    context.Context
-	cancel context.CancelFunc
-	logger *zap.Logger
+	ctx      context.Context
+	cancel   context.CancelFunc
+	logger   *zap.Logger
+	balancer Balancer
+	// breakerConfig - if set, each pool peer gets own circuit breaker
+	breakerConfig *config.CircuitBreakerConfig
 
 	mspPeers map[string][]*peerPoolPeer
+	closed   bool
 	storeMx  sync.RWMutex
 }
 
 type peerPoolPeer struct {
-	peer  api.Peer
-	ready bool
+	// endorsement statistics for balancer, accessed atomically
+	inFlight int64
+	latency  int64
+
+	peer    api.Peer
+	ready   bool
+	breaker *circuitBreaker
+	// cancel stops peer checks
+	cancel context.CancelFunc
+}
+
+// PeerPoolOpt describes option which will be applied to PeerPool
+type PeerPoolOpt func(p *PeerPool)
+
+// WithBalancer sets strategy of choosing MSP peer for endorsement, default is FirstBalancer
+func WithBalancer(balancer Balancer) PeerPoolOpt {
+	return func(p *PeerPool) {
+		p.balancer = balancer
+	}
+}
+
+// WithCircuitBreaker enables per peer circuit breaker, which stops sending endorsements to failing peer
+func WithCircuitBreaker(cfg config.CircuitBreakerConfig) PeerPoolOpt {
+	return func(p *PeerPool) {
+		p.breakerConfig = &cfg
+	}
 }
 
 type endorseChannelResponse struct {
@@ -40,18 +69,28 @@ type endorseChannelResponse struct {
 	Error    error
 }
 
-func NewPeerPool(ctx context.Context, log *zap.Logger) *PeerPool {
+func NewPeerPool(ctx context.Context, log *zap.Logger, opts ...PeerPoolOpt) *PeerPool {
 	ctx, cancel := context.WithCancel(ctx)
 
-	return &PeerPool{
+	p := &PeerPool{
 		mspPeers: make(map[string][]*peerPoolPeer),
 		logger:   log.Named(`peer-pool`),
+		balancer: FirstBalancer(),
 		ctx:      ctx,
 		cancel:   cancel,
 	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
 func (p *PeerPool) GetPeers() map[string][]api.Peer {
+	p.storeMx.RLock()
+	defer p.storeMx.RUnlock()
+
 	m := make(map[string][]api.Peer, 0)
 
 	for mspId, peers := range p.mspPeers {
@@ -64,6 +103,9 @@ func (p *PeerPool) GetPeers() map[string][]api.Peer {
 }
 
 func (p *PeerPool) GetMSPPeers(mspID string) []api.Peer {
+	p.storeMx.RLock()
+	defer p.storeMx.RUnlock()
+
 	var peers []api.Peer
 	if mspPeers, ok := p.mspPeers[mspID]; ok {
 		for _, mspPeer := range mspPeers {
@@ -81,6 +123,10 @@ func (p *PeerPool) Add(mspId string, peer api.Peer, peerChecker api.PeerPoolChec
 	p.storeMx.Lock()
 	defer p.storeMx.Unlock()
 
+	if p.closed {
+		return ErrPoolClosed
+	}
+
 	if peers, ok := p.mspPeers[mspId]; !ok {
 		p.mspPeers[mspId] = p.addPeer(peer, make([]*peerPoolPeer, 0), peerChecker)
 	} else {
@@ -92,21 +138,119 @@ func (p *PeerPool) Add(mspId string, peer api.Peer, peerChecker api.PeerPoolChec
 }
 
 func (p *PeerPool) addPeer(peer api.Peer, peerSet []*peerPoolPeer, peerChecker api.PeerPoolCheckStrategy) []*peerPoolPeer {
-	pp := &peerPoolPeer{peer: peer, ready: true}
+	return append(peerSet, p.newPoolPeer(peer, peerChecker))
+}
+
+// newPoolPeer wraps peer and starts its checks, which are stopped on peer removal or pool close
+func (p *PeerPool) newPoolPeer(peer api.Peer, peerChecker api.PeerPoolCheckStrategy) *peerPoolPeer {
+	ctx, cancel := context.WithCancel(p.ctx)
+
+	pp := &peerPoolPeer{peer: peer, ready: true, cancel: cancel}
+	if p.breakerConfig != nil {
+		pp.breaker = newCircuitBreaker(*p.breakerConfig)
+	}
 	aliveChan := make(chan bool)
-	go peerChecker(p.ctx, peer, aliveChan)
-	go p.poolChecker(p.ctx, aliveChan, pp)
-	return append(peerSet, pp)
+	go peerChecker(ctx, peer, aliveChan)
+	go p.poolChecker(ctx, aliveChan, pp)
+	return pp
 }
 
 func (p *PeerPool) isPeerInPool(peer api.Peer, peerSet []*peerPoolPeer) bool {
-	for _, pp := range peerSet {
-		if peer.URI() == pp.peer.URI() {
-			return true
+	return peerPos(peer.URI(), peerSet) >= 0
+}
+
+func peerPos(uri string, peerSet []*peerPoolPeer) int {
+	for pos, pp := range peerSet {
+		if uri == pp.peer.URI() {
+			return pos
 		}
 	}
 
-	return false
+	return -1
+}
+
+// Remove removes peer from pool, peer connection is closed without pool lock
+func (p *PeerPool) Remove(mspId string, uri string) error {
+	p.logger.Debug(`remove peer`,
+		zap.String(`msp_id`, mspId),
+		zap.String(`peer_URI`, uri))
+
+	removed, err := p.remove(mspId, uri)
+	if err != nil {
+		return err
+	}
+
+	return removed.close()
+}
+
+func (p *PeerPool) remove(mspId string, uri string) (*peerPoolPeer, error) {
+	p.storeMx.Lock()
+	defer p.storeMx.Unlock()
+
+	peers := p.mspPeers[mspId]
+	pos := peerPos(uri, peers)
+	if pos < 0 {
+		return nil, fmt.Errorf(`msp_id=%s, uri=%s: %w`, mspId, uri, ErrPeerNotFound)
+	}
+
+	// endorsements can iterate over current peers slice, so it is not modified in place
+	rest := make([]*peerPoolPeer, 0, len(peers)-1)
+	rest = append(rest, peers[:pos]...)
+	rest = append(rest, peers[pos+1:]...)
+
+	if len(rest) == 0 {
+		delete(p.mspPeers, mspId)
+	} else {
+		p.mspPeers[mspId] = rest
+	}
+
+	return peers[pos], nil
+}
+
+// Replace replaces pool peer with the same URI or adds peer, replaced peer connection is closed without pool lock
+func (p *PeerPool) Replace(mspId string, peer api.Peer, peerChecker api.PeerPoolCheckStrategy) error {
+	p.logger.Debug(`replace peer`,
+		zap.String(`msp_id`, mspId),
+		zap.String(`peer_URI`, peer.URI()))
+
+	replaced, err := p.replace(mspId, peer, peerChecker)
+	if err != nil || replaced == nil {
+		return err
+	}
+
+	return replaced.close()
+}
+
+func (p *PeerPool) replace(mspId string, peer api.Peer, peerChecker api.PeerPoolCheckStrategy) (*peerPoolPeer, error) {
+	p.storeMx.Lock()
+	defer p.storeMx.Unlock()
+
+	if p.closed {
+		return nil, ErrPoolClosed
+	}
+
+	peers := p.mspPeers[mspId]
+	pos := peerPos(peer.URI(), peers)
+	if pos < 0 {
+		p.mspPeers[mspId] = p.addPeer(peer, peers, peerChecker)
+		return nil, nil
+	}
+
+	replaced := make([]*peerPoolPeer, len(peers))
+	copy(replaced, peers)
+	replaced[pos] = p.newPoolPeer(peer, peerChecker)
+	p.mspPeers[mspId] = replaced
+
+	return peers[pos], nil
+}
+
+// close stops peer checks and closes peer connection
+func (pp *peerPoolPeer) close() error {
+	pp.cancel()
+	if err := pp.peer.Close(); err != nil {
+		return fmt.Errorf(`close peer uri=%s: %w`, pp.peer.URI(), err)
+	}
+	return nil
 }
 
 func (p *PeerPool) poolChecker(ctx context.Context, aliveChan chan bool, peer *peerPoolPeer) {
@@ -136,7 +280,7 @@ func (p *PeerPool) poolChecker(ctx context.Context, aliveChan chan bool, peer *p
 }
 
 // EndorseOnMSP finds first ready peer in pool for specified mspId , endorses proposal and returns proposal response
-// - no load balancing between msp peers
+// - msp peers are tried in order provided by pool Balancer
 // - no data is not sent to the orderer
 func (p *PeerPool) EndorseOnMSP(ctx context.Context, mspID string, proposal *peerproto.SignedProposal) (*peerproto.ProposalResponse, error) {
 	p.storeMx.RLock()
@@ -155,25 +299,43 @@ func (p *PeerPool) EndorseOnMSP(ctx context.Context, mspID string, proposal *pee
 
 	var lastError error
 
-	for pos, poolPeer := range peers {
-		if !poolPeer.ready {
+	balanced := make([]BalancedPeer, len(peers))
+	for i := range peers {
+		balanced[i] = peers[i]
+	}
+
+	for pos, balancedPeer := range p.balancer.Order(mspID, balanced) {
+		poolPeer := balancedPeer.(*peerPoolPeer)
+
+		p.storeMx.RLock()
+		ready := poolPeer.ready
+		p.storeMx.RUnlock()
+
+		if !ready {
 			p.logger.Debug(ErrPeerNotReady.Error(), zap.String(`uri`, poolPeer.peer.URI()))
 			continue
 		}
 
+		if poolPeer.breaker != nil && !poolPeer.breaker.allow() {
+			p.logger.Debug(`peer circuit breaker is open`, zap.String(`uri`, poolPeer.peer.URI()))
+			continue
+		}
+
 		log.Debug(`Sending endorse to peer...`,
 			zap.String(`mspId`, mspID),
 			zap.String(`uri`, poolPeer.peer.URI()),
 			zap.Int(`peerPos`, pos),
 			zap.Int(`peers in msp pool`, len(peers)))
 
+		endorseDone := poolPeer.endorseStarted()
 		propResp, err := poolPeer.peer.Endorse(ctx, proposal)
+		endorseDone(err)
+		p.recordEndorse(ctx, mspID, poolPeer, err)
 		if err != nil {
 			// GRPC error
 			if s, ok := status.FromError(err); ok {
 				if s.Code() == codes.Unavailable {
 					log.Debug(`peer GRPC unavailable`, zap.String(`mspId`, mspID), zap.String(`peer_uri`, poolPeer.peer.URI()))
-					//poolPeer.ready = false
 				} else {
 					log.Debug(`unexpected GRPC error code from peer`,
 						zap.String(`peer_uri`, poolPeer.peer.URI()), zap.Uint32(`code`, uint32(s.Code())),
@@ -191,6 +353,13 @@ func (p *PeerPool) EndorseOnMSP(ctx context.Context, mspID string, proposal *pee
 				zap.String(`peer_uri`, poolPeer.peer.URI()),
 				zap.String(`error`, err.Error()))
 
+			// chaincode error is returned with endorsing MSP and peer
+			var endorseErr api.PeerEndorseError
+			if errors.As(err, &endorseErr) {
+				endorseErr.MspID, endorseErr.PeerURI = mspID, poolPeer.peer.URI()
+				err = endorseErr
+			}
+
 			return propResp, errors.Wrap(err, poolPeer.peer.URI())
 		}
 
@@ -206,6 +375,26 @@ func (p *PeerPool) EndorseOnMSP(ctx context.Context, mspID string, proposal *pee
 	return nil, lastError
 }
 
+// recordEndorse passes endorsement outcome to peer circuit breaker.
+// Error after caller's context is done isn't counted, i.e. DeadlineExceeded of client-side timeout
+func (p *PeerPool) recordEndorse(ctx context.Context, mspID string, poolPeer *peerPoolPeer, err error) {
+	if poolPeer.breaker == nil {
+		return
+	}
+
+	if err != nil && ctx.Err() != nil {
+		poolPeer.breaker.release()
+		return
+	}
+
+	if state, changed := poolPeer.breaker.record(isPeerFailure(err)); changed {
+		p.logger.Warn(`peer circuit breaker state changed`,
+			zap.String(`msp_id`, mspID),
+			zap.String(`peer_uri`, poolPeer.peer.URI()),
+			zap.String(`state`, state.String()))
+	}
+}
+
 func (p *PeerPool) EndorseOnMSPs(ctx context.Context, mspIDs []string, proposal *peerproto.SignedProposal) ([]*peerproto.ProposalResponse, error) {
 	if len(mspIDs) == 0 {
 		return nil, ErrEndorsingMSPsRequired
@@ -266,8 +455,11 @@ func (p *PeerPool) FirstReadyPeer(mspId string) (api.Peer, error) {
 		log.Error(ErrNoPeersForMSP.Error(), zap.String(`mspId`, mspId))
 	}
 
+	p.storeMx.RLock()
+	defer p.storeMx.RUnlock()
+
 	for _, poolPeer := range peers {
-		if poolPeer.ready {
+		if poolPeer.ready && (poolPeer.breaker == nil || poolPeer.breaker.available()) {
 			return poolPeer.peer, nil
 		}
 	}
@@ -275,7 +467,47 @@ func (p *PeerPool) FirstReadyPeer(mspId string) (api.Peer, error) {
 	return nil, clienterrors.ErrNoReadyPeers{MspId: mspId}
 }
 
+// ReadyPeers returns ready peers of MSP, which circuit breakers allow requests
+func (p *PeerPool) ReadyPeers(mspId string) []api.Peer {
+	p.storeMx.RLock()
+	defer p.storeMx.RUnlock()
+
+	var peers []api.Peer
+	for _, poolPeer := range p.mspPeers[mspId] {
+		if poolPeer.ready && (poolPeer.breaker == nil || poolPeer.breaker.available()) {
+			peers = append(peers, poolPeer.peer)
+		}
+	}
+
+	return peers
+}
+
 func (p *PeerPool) Close() error {
+	p.storeMx.Lock()
+	if p.closed {
+		p.storeMx.Unlock()
+		return nil
+	}
+	p.closed = true
+
+	mspPeers := p.mspPeers
+	p.mspPeers = make(map[string][]*peerPoolPeer)
+	p.cancel()
+	p.storeMx.Unlock()
+
+	// peers connections are closed without pool lock
+	mErr := new(clienterrors.MultiError)
+	for _, peers := range mspPeers {
+		for _, poolPeer := range peers {
+			if err := poolPeer.close(); err != nil {
+				mErr.Add(err)
+			}
+		}
+	}
+
+	if len(mErr.Errors) > 0 {
+		return mErr
+	}
 	return nil
 }
 
@@ -289,10 +521,8 @@ func StrategyGRPC(d time.Duration) api.PeerPoolCheckStrategy {
 			case <-ctx.Done():
 				return
 			case <-t.C:
-				if peer.Conn().GetState() == connectivity.Ready {
-					alive <- true
-				} else {
-					alive <- false
+				if !sendAlive(ctx, alive, peer.Conn().GetState() == connectivity.Ready) {
+					return
 				}
 			}
 		}
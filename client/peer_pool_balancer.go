@@ -0,0 +1,243 @@
+package client
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+)
+
+// Balancer types, can be set in config.PoolConfig
+const (
+	BalancerFirst         = `first`
+	BalancerRoundRobin    = `round_robin`
+	BalancerLeastInFlight = `least_in_flight`
+	BalancerLatency       = `latency`
+	BalancerRandom        = `random`
+)
+
+// latencyEWMAWeight - weight of the last endorse duration in peer latency moving average
+const latencyEWMAWeight = 0.3
+
+// BalancedPeer - pool peer with endorsement statistics, used by Balancer
+type BalancedPeer interface {
+	Peer() api.Peer
+	// InFlight returns number of endorsements currently processed by peer
+	InFlight() int64
+	// Latency returns moving average of peer endorse durations, zero if peer has not endorsed yet
+	Latency() time.Duration
+}
+
+// Balancer returns order in which MSP peers are tried for endorsement
+type Balancer interface {
+	Order(mspID string, peers []BalancedPeer) []BalancedPeer
+}
+
+// NewBalancer returns balancer by type, empty type means BalancerFirst
+func NewBalancer(balancerType string) (Balancer, error) {
+	switch balancerType {
+	case ``, BalancerFirst:
+		return FirstBalancer(), nil
+	case BalancerRoundRobin:
+		return RoundRobinBalancer(), nil
+	case BalancerLeastInFlight:
+		return LeastInFlightBalancer(), nil
+	case BalancerLatency:
+		return LatencyBalancer(), nil
+	case BalancerRandom:
+		return RandomBalancer(), nil
+	default:
+		return nil, fmt.Errorf("unknown balancer type=%s. available: %s, %s, %s, %s, %s", balancerType,
+			BalancerFirst, BalancerRoundRobin, BalancerLeastInFlight, BalancerLatency, BalancerRandom)
+	}
+}
+
+type firstBalancer struct{}
+
+// FirstBalancer keeps peers in order they were added to pool, so first ready peer takes all traffic
+func FirstBalancer() Balancer {
+	return firstBalancer{}
+}
+
+func (firstBalancer) Order(_ string, peers []BalancedPeer) []BalancedPeer {
+	return peers
+}
+
+type roundRobinBalancer struct {
+	counters map[string]uint64
+	mu       sync.Mutex
+}
+
+// RoundRobinBalancer starts each next endorsement from the next MSP peer
+func RoundRobinBalancer() Balancer {
+	return &roundRobinBalancer{
+		counters: make(map[string]uint64),
+	}
+}
+
+func (b *roundRobinBalancer) Order(mspID string, peers []BalancedPeer) []BalancedPeer {
+	if len(peers) == 0 {
+		return peers
+	}
+
+	b.mu.Lock()
+	start := b.counters[mspID] % uint64(len(peers))
+	b.counters[mspID]++
+	b.mu.Unlock()
+
+	ordered := make([]BalancedPeer, 0, len(peers))
+	ordered = append(ordered, peers[start:]...)
+	return append(ordered, peers[:start]...)
+}
+
+type leastInFlightBalancer struct{}
+
+// LeastInFlightBalancer prefers peers with the least number of endorsements in progress
+func LeastInFlightBalancer() Balancer {
+	return leastInFlightBalancer{}
+}
+
+func (leastInFlightBalancer) Order(_ string, peers []BalancedPeer) []BalancedPeer {
+	ordered := make([]BalancedPeer, len(peers))
+	copy(ordered, peers)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].InFlight() < ordered[j].InFlight()
+	})
+
+	return ordered
+}
+
+type latencyBalancer struct {
+	rnd *rand.Rand
+	mu  sync.Mutex
+}
+
+// LatencyBalancer randomly orders peers with probability inversely proportional to their recent endorse latency.
+// Peers without latency statistics are weighted as the fastest ones, so they will be measured soon
+func LatencyBalancer() Balancer {
+	return &latencyBalancer{
+		rnd: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (b *latencyBalancer) Order(_ string, peers []BalancedPeer) []BalancedPeer {
+	var minLatency time.Duration
+	for _, p := range peers {
+		if l := p.Latency(); l > 0 && (minLatency == 0 || l < minLatency) {
+			minLatency = l
+		}
+	}
+
+	weights := make([]float64, len(peers))
+	for i, p := range peers {
+		latency := p.Latency()
+		if latency == 0 {
+			latency = minLatency
+		}
+
+		if latency == 0 {
+			weights[i] = 1
+		} else {
+			weights[i] = 1 / latency.Seconds()
+		}
+	}
+
+	remaining := make([]BalancedPeer, len(peers))
+	copy(remaining, peers)
+	ordered := make([]BalancedPeer, 0, len(peers))
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// weighted random sampling without replacement
+	for len(remaining) > 0 {
+		var total float64
+		for _, w := range weights {
+			total += w
+		}
+
+		pos, point := 0, b.rnd.Float64()*total
+		for ; pos < len(weights)-1; pos++ {
+			if point < weights[pos] {
+				break
+			}
+			point -= weights[pos]
+		}
+
+		ordered = append(ordered, remaining[pos])
+		remaining = append(remaining[:pos], remaining[pos+1:]...)
+		weights = append(weights[:pos], weights[pos+1:]...)
+	}
+
+	return ordered
+}
+
+type randomBalancer struct {
+	rnd *rand.Rand
+	mu  sync.Mutex
+}
+
+// RandomBalancer shuffles MSP peers for each endorsement
+func RandomBalancer() Balancer {
+	return &randomBalancer{
+		rnd: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (b *randomBalancer) Order(_ string, peers []BalancedPeer) []BalancedPeer {
+	ordered := make([]BalancedPeer, len(peers))
+	copy(ordered, peers)
+
+	b.mu.Lock()
+	b.rnd.Shuffle(len(ordered), func(i, j int) {
+		ordered[i], ordered[j] = ordered[j], ordered[i]
+	})
+	b.mu.Unlock()
+
+	return ordered
+}
+
+func (pp *peerPoolPeer) Peer() api.Peer {
+	return pp.peer
+}
+
+func (pp *peerPoolPeer) InFlight() int64 {
+	return atomic.LoadInt64(&pp.inFlight)
+}
+
+func (pp *peerPoolPeer) Latency() time.Duration {
+	return time.Duration(atomic.LoadInt64(&pp.latency))
+}
+
+// endorseStarted marks endorsement start and returns func, which must be called with endorsement error.
+// Latency of failed endorsement isn't observed, otherwise fast failing peer looks the fastest one
+func (pp *peerPoolPeer) endorseStarted() func(err error) {
+	started := time.Now()
+	atomic.AddInt64(&pp.inFlight, 1)
+
+	return func(err error) {
+		atomic.AddInt64(&pp.inFlight, -1)
+		if err == nil {
+			pp.observeLatency(time.Since(started))
+		}
+	}
+}
+
+func (pp *peerPoolPeer) observeLatency(d time.Duration) {
+	for {
+		prev := atomic.LoadInt64(&pp.latency)
+		next := int64(d)
+		if prev != 0 {
+			next = int64(latencyEWMAWeight*float64(d) + (1-latencyEWMAWeight)*float64(prev))
+		}
+
+		if atomic.CompareAndSwapInt64(&pp.latency, prev, next) {
+			return
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/client"
+)
+
+type balancedPeerMock struct {
+	name     string
+	inFlight int64
+	latency  time.Duration
+}
+
+func (p *balancedPeerMock) Peer() api.Peer         { return nil }
+func (p *balancedPeerMock) InFlight() int64        { return p.inFlight }
+func (p *balancedPeerMock) Latency() time.Duration { return p.latency }
+
+func names(peers []client.BalancedPeer) (res []string) {
+	for _, p := range peers {
+		res = append(res, p.(*balancedPeerMock).name)
+	}
+	return res
+}
+
+func TestRoundRobinBalancer(t *testing.T) {
+	peers := []client.BalancedPeer{
+		&balancedPeerMock{name: `peer0`}, &balancedPeerMock{name: `peer1`}, &balancedPeerMock{name: `peer2`}}
+
+	b, err := client.NewBalancer(client.BalancerRoundRobin)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{`peer0`, `peer1`, `peer2`}, names(b.Order(`org1`, peers)))
+	assert.Equal(t, []string{`peer1`, `peer2`, `peer0`}, names(b.Order(`org1`, peers)))
+	// counters are separate for each MSP
+	assert.Equal(t, []string{`peer0`, `peer1`, `peer2`}, names(b.Order(`org2`, peers)))
+	assert.Equal(t, []string{`peer2`, `peer0`, `peer1`}, names(b.Order(`org1`, peers)))
+}
+
+func TestLeastInFlightBalancer(t *testing.T) {
+	peers := []client.BalancedPeer{
+		&balancedPeerMock{name: `peer0`, inFlight: 5},
+		&balancedPeerMock{name: `peer1`, inFlight: 1},
+		&balancedPeerMock{name: `peer2`, inFlight: 3},
+	}
+
+	assert.Equal(t, []string{`peer1`, `peer2`, `peer0`}, names(client.LeastInFlightBalancer().Order(`org1`, peers)))
+}
+
+func TestLatencyBalancer(t *testing.T) {
+	peers := []client.BalancedPeer{
+		&balancedPeerMock{name: `slow`, latency: time.Second},
+		&balancedPeerMock{name: `fast`, latency: time.Millisecond},
+	}
+
+	b := client.LatencyBalancer()
+	firstPicks := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		ordered := b.Order(`org1`, peers)
+		require.Len(t, ordered, 2)
+		firstPicks[ordered[0].(*balancedPeerMock).name]++
+	}
+
+	assert.Greater(t, firstPicks[`fast`], firstPicks[`slow`]*10)
+}
+
+func TestNewBalancerUnknown(t *testing.T) {
+	_, err := client.NewBalancer(`unknown`)
+	assert.Error(t, err)
+}
+
+// latencyRecorder records latencies of ordered peers
+type latencyRecorder struct {
+	latencies []time.Duration
+}
+
+func (r *latencyRecorder) Order(_ string, peers []client.BalancedPeer) []client.BalancedPeer {
+	for _, p := range peers {
+		r.latencies = append(r.latencies, p.Latency())
+	}
+	return peers
+}
+
+func TestPeerPoolLatencyOfFailedEndorse(t *testing.T) {
+	recorder := &latencyRecorder{}
+	pool := client.NewPeerPool(context.Background(), zap.NewNop(), client.WithBalancer(recorder))
+	stopped := make(chan string, 1)
+
+	require.NoError(t, pool.Add(`org1`, &endorseErrPeerMock{closablePeerMock: closablePeerMock{uri: `peer1`},
+		err: status.Error(codes.Unavailable, `unavailable`)}, checkStrategyMock(stopped)))
+
+	for i := 0; i < 2; i++ {
+		_, err := pool.EndorseOnMSP(context.Background(), `org1`, &peer.SignedProposal{})
+		require.Error(t, err)
+	}
+
+	// failed endorsement doesn't make peer look fast
+	assert.Equal(t, []time.Duration{0, 0}, recorder.latencies)
+}
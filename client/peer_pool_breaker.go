@@ -0,0 +1,209 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/s7techlab/hlf-sdk-go/api/config"
+)
+
+const (
+	DefaultCircuitBreakerWindow   = 20
+	DefaultCircuitBreakerCooldown = 10 * time.Second
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+func (s circuitState) String() string {
+	switch s {
+	case circuitClosed:
+		return `closed`
+	case circuitOpen:
+		return `open`
+	case circuitHalfOpen:
+		return `half-open`
+	default:
+		return `unknown`
+	}
+}
+
+// circuitBreaker stops sending endorsements to peer after failures.
+// After cooldown, breaker becomes half-open and lets one probe endorsement through:
+// success closes breaker, failure opens it again
+type circuitBreaker struct {
+	cfg config.CircuitBreakerConfig
+	now func() time.Time
+
+	mu          sync.Mutex
+	state       circuitState
+	openedAt    time.Time
+	probing     bool
+	consecutive uint
+	// ring buffer of last endorsement outcomes, true means failure
+	outcomes []bool
+	pos      int
+	filled   bool
+}
+
+func newCircuitBreaker(cfg config.CircuitBreakerConfig) *circuitBreaker {
+	if cfg.ErrorRate > 0 && cfg.Window == 0 {
+		cfg.Window = DefaultCircuitBreakerWindow
+	}
+
+	if cfg.Cooldown.Duration == 0 {
+		cfg.Cooldown.Duration = DefaultCircuitBreakerCooldown
+	}
+
+	return &circuitBreaker{
+		cfg:      cfg,
+		now:      time.Now,
+		outcomes: make([]bool, cfg.Window),
+	}
+}
+
+// allow reports whether endorsement can be sent to peer, in half-open state only one probe is allowed
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.halfOpenAfterCooldown()
+
+	switch b.state {
+	case circuitClosed:
+		return true
+	case circuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return false
+	}
+}
+
+// available reports whether peer can be used, without reserving half-open probe
+func (b *circuitBreaker) available() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.halfOpenAfterCooldown()
+	return b.state == circuitClosed || (b.state == circuitHalfOpen && !b.probing)
+}
+
+// record registers endorsement outcome, returns new breaker state and true if state was changed
+func (b *circuitBreaker) record(failure bool) (circuitState, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	prevState := b.state
+
+	switch b.state {
+	case circuitHalfOpen:
+		b.probing = false
+		if failure {
+			b.open()
+		} else {
+			b.close()
+		}
+
+	case circuitClosed:
+		if failure {
+			b.consecutive++
+		} else {
+			b.consecutive = 0
+		}
+
+		if len(b.outcomes) > 0 {
+			b.outcomes[b.pos] = failure
+			b.pos = (b.pos + 1) % len(b.outcomes)
+			if b.pos == 0 {
+				b.filled = true
+			}
+		}
+
+		if b.tripped() {
+			b.open()
+		}
+	}
+
+	return b.state, b.state != prevState
+}
+
+// release frees half-open probe without outcome, i.e. if endorsement is interrupted by caller
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *circuitBreaker) tripped() bool {
+	if b.cfg.ConsecutiveFailures > 0 && b.consecutive >= b.cfg.ConsecutiveFailures {
+		return true
+	}
+
+	if b.cfg.ErrorRate > 0 && b.filled {
+		var failures int
+		for _, failure := range b.outcomes {
+			if failure {
+				failures++
+			}
+		}
+
+		return float64(failures)/float64(len(b.outcomes)) >= b.cfg.ErrorRate
+	}
+
+	return false
+}
+
+func (b *circuitBreaker) halfOpenAfterCooldown() {
+	if b.state == circuitOpen && b.now().Sub(b.openedAt) >= b.cfg.Cooldown.Duration {
+		b.state = circuitHalfOpen
+		b.probing = false
+	}
+}
+
+func (b *circuitBreaker) open() {
+	b.state = circuitOpen
+	b.openedAt = b.now()
+}
+
+func (b *circuitBreaker) close() {
+	b.state = circuitClosed
+	b.consecutive = 0
+	b.pos = 0
+	b.filled = false
+	for i := range b.outcomes {
+		b.outcomes[i] = false
+	}
+}
+
+// isPeerFailure reports whether endorsement error is caused by peer unavailability,
+// chaincode errors are not peer failures
+func isPeerFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	s, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+
+	switch s.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/api/config"
+)
+
+type breakerClock struct {
+	now time.Time
+}
+
+func (c *breakerClock) Now() time.Time {
+	return c.now
+}
+
+func newTestBreaker(cfg config.CircuitBreakerConfig) (*circuitBreaker, *breakerClock) {
+	clock := &breakerClock{now: time.Unix(0, 0)}
+	b := newCircuitBreaker(cfg)
+	b.now = clock.Now
+	return b, clock
+}
+
+func TestCircuitBreakerConsecutiveFailures(t *testing.T) {
+	b, clock := newTestBreaker(config.CircuitBreakerConfig{
+		ConsecutiveFailures: 3,
+		Cooldown:            config.Duration{Duration: time.Second},
+	})
+
+	b.record(true)
+	b.record(true)
+	b.record(false)
+	b.record(true)
+	b.record(true)
+	assert.True(t, b.allow())
+
+	state, changed := b.record(true)
+	assert.Equal(t, circuitOpen, state)
+	assert.True(t, changed)
+	assert.False(t, b.allow())
+	assert.False(t, b.available())
+
+	clock.now = clock.now.Add(time.Second)
+	assert.True(t, b.available())
+	// only one probe in half-open state
+	assert.True(t, b.allow())
+	assert.False(t, b.allow())
+	assert.False(t, b.available())
+
+	state, changed = b.record(true)
+	assert.Equal(t, circuitOpen, state)
+	assert.True(t, changed)
+
+	clock.now = clock.now.Add(time.Second)
+	assert.True(t, b.allow())
+
+	state, changed = b.record(false)
+	assert.Equal(t, circuitClosed, state)
+	assert.True(t, changed)
+	assert.True(t, b.allow())
+}
+
+func TestCircuitBreakerErrorRate(t *testing.T) {
+	b, _ := newTestBreaker(config.CircuitBreakerConfig{
+		ErrorRate: 0.5,
+		Window:    4,
+	})
+
+	// window is not filled yet
+	b.record(true)
+	b.record(true)
+	assert.True(t, b.allow())
+
+	b.record(false)
+	state, _ := b.record(true)
+	assert.Equal(t, circuitOpen, state)
+}
+
+func TestIsPeerFailure(t *testing.T) {
+	assert.False(t, isPeerFailure(nil))
+	assert.False(t, isPeerFailure(errors.New(`chaincode error`)))
+	assert.False(t, isPeerFailure(status.Error(codes.Unknown, `chaincode error`)))
+	assert.True(t, isPeerFailure(status.Error(codes.Unavailable, `connection refused`)))
+	assert.True(t, isPeerFailure(status.Error(codes.DeadlineExceeded, `timeout`)))
+}
+
+type uriPeerMock struct {
+	api.Peer
+	uri string
+}
+
+func (p uriPeerMock) URI() string {
+	return p.uri
+}
+
+func (p uriPeerMock) Close() error {
+	return nil
+}
+
+func TestRecordEndorseCallerTimeout(t *testing.T) {
+	pool := NewPeerPool(context.Background(), zap.NewNop(), WithCircuitBreaker(config.CircuitBreakerConfig{
+		ConsecutiveFailures: 1,
+		Cooldown:            config.Duration{Duration: time.Minute},
+	}))
+	poolPeer := &peerPoolPeer{peer: uriPeerMock{uri: `peer1:7051`}, breaker: newCircuitBreaker(*pool.breakerConfig)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// deadline of caller's context isn't peer failure
+	pool.recordEndorse(ctx, `org1`, poolPeer, status.Error(codes.DeadlineExceeded, `timeout`))
+	assert.True(t, poolPeer.breaker.allow())
+
+	pool.recordEndorse(context.Background(), `org1`, poolPeer, status.Error(codes.DeadlineExceeded, `timeout`))
+	assert.False(t, poolPeer.breaker.allow())
+}
@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/client/grpc"
+)
+
+// PeerCheckStrategyProvider returns check strategy for peer of specified MSP, added to pool by Client
+type PeerCheckStrategyProvider func(mspID string) api.PeerPoolCheckStrategy
+
+// DefaultPeerCheckStrategy checks only GRPC connectivity state of peer
+func DefaultPeerCheckStrategy(string) api.PeerPoolCheckStrategy {
+	return StrategyGRPC(grpc.DefaultGrpcCheckPeriod)
+}
+
+// StrategyQueryProbe periodically queries qscc GetChainInfo on channel,
+// peer is alive if it processes query within check period
+func StrategyQueryProbe(channel string, d time.Duration) api.PeerPoolCheckStrategy {
+	return func(ctx context.Context, peer api.Peer, alive chan bool) {
+		t := time.NewTicker(d)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				probeCtx, cancel := context.WithTimeout(ctx, d)
+				_, err := peer.GetChainInfo(probeCtx, channel)
+				cancel()
+
+				if !sendAlive(ctx, alive, err == nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// StrategyComposite combines strategies, peer is alive only if it is alive for each of them.
+// Until strategy reports peer state, peer is considered alive by it
+func StrategyComposite(strategies ...api.PeerPoolCheckStrategy) api.PeerPoolCheckStrategy {
+	return func(ctx context.Context, peer api.Peer, alive chan bool) {
+		type strategyState struct {
+			pos   int
+			alive bool
+		}
+
+		updates := make(chan strategyState)
+		states := make([]bool, len(strategies))
+
+		for i, strategy := range strategies {
+			states[i] = true
+			strategyAlive := make(chan bool)
+
+			go strategy(ctx, peer, strategyAlive)
+			go func(pos int) {
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case a := <-strategyAlive:
+						select {
+						case updates <- strategyState{pos: pos, alive: a}:
+						case <-ctx.Done():
+							return
+						}
+					}
+				}
+			}(i)
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case update := <-updates:
+				states[update.pos] = update.alive
+
+				allAlive := true
+				for _, a := range states {
+					allAlive = allAlive && a
+				}
+
+				if !sendAlive(ctx, alive, allAlive) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// LedgerHeightChecker compares channels ledger heights of MSP peers.
+// Peer is not ready if it lags behind the best peer of its MSP more than on maxLag blocks on any of channels
+type LedgerHeightChecker struct {
+	channels []string
+	maxLag   uint64
+	period   time.Duration
+
+	// msp id -> channel -> peer uri -> height
+	heights map[string]map[string]map[string]uint64
+	mu      sync.Mutex
+}
+
+func NewLedgerHeightChecker(channels []string, maxLag uint64, period time.Duration) *LedgerHeightChecker {
+	return &LedgerHeightChecker{
+		channels: channels,
+		maxLag:   maxLag,
+		period:   period,
+		heights:  make(map[string]map[string]map[string]uint64),
+	}
+}
+
+// Strategy returns check strategy for peers of specified MSP.
+// Peer heights are evicted, when its check is stopped, i.e. peer is removed from pool
+func (c *LedgerHeightChecker) Strategy(mspID string) api.PeerPoolCheckStrategy {
+	return func(ctx context.Context, peer api.Peer, alive chan bool) {
+		t := time.NewTicker(c.period)
+		defer t.Stop()
+		defer c.evict(mspID, peer.URI())
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				if !sendAlive(ctx, alive, c.check(ctx, mspID, peer)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *LedgerHeightChecker) check(ctx context.Context, mspID string, peer api.Peer) bool {
+	heights := make(map[string]uint64, len(c.channels))
+
+	for _, channel := range c.channels {
+		checkCtx, cancel := context.WithTimeout(ctx, c.period)
+		info, err := peer.GetChainInfo(checkCtx, channel)
+		cancel()
+
+		if err != nil {
+			// height of unavailable peer doesn't raise best height
+			c.evict(mspID, peer.URI())
+			return false
+		}
+		heights[channel] = info.Height
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	mspHeights, ok := c.heights[mspID]
+	if !ok {
+		mspHeights = make(map[string]map[string]uint64)
+		c.heights[mspID] = mspHeights
+	}
+
+	inSync := true
+	for channel, height := range heights {
+		channelHeights, ok := mspHeights[channel]
+		if !ok {
+			channelHeights = make(map[string]uint64)
+			mspHeights[channel] = channelHeights
+		}
+		channelHeights[peer.URI()] = height
+
+		var best uint64
+		for _, h := range channelHeights {
+			if h > best {
+				best = h
+			}
+		}
+
+		if best-height > c.maxLag {
+			inSync = false
+		}
+	}
+
+	return inSync
+}
+
+// evict removes peer heights
+func (c *LedgerHeightChecker) evict(mspID, uri string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, channelHeights := range c.heights[mspID] {
+		delete(channelHeights, uri)
+	}
+}
+
+func sendAlive(ctx context.Context, alive chan bool, isAlive bool) bool {
+	select {
+	case alive <- isAlive:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
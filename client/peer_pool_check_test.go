@@ -0,0 +1,124 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/client"
+)
+
+type chainInfoPeerMock struct {
+	api.Peer
+
+	uri    string
+	height uint64
+	err    error
+	mu     sync.Mutex
+}
+
+func (p *chainInfoPeerMock) URI() string {
+	return p.uri
+}
+
+func (p *chainInfoPeerMock) GetChainInfo(context.Context, string) (*common.BlockchainInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &common.BlockchainInfo{Height: p.height}, nil
+}
+
+func (p *chainInfoPeerMock) setHeight(height uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.height = height
+}
+
+func runStrategy(ctx context.Context, strategy api.PeerPoolCheckStrategy, peer api.Peer) chan bool {
+	alive := make(chan bool)
+	go strategy(ctx, peer, alive)
+	return alive
+}
+
+func TestLedgerHeightChecker(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	checker := client.NewLedgerHeightChecker([]string{`channel`}, 5, 10*time.Millisecond)
+
+	best := &chainInfoPeerMock{uri: `peer0`, height: 100}
+	lagging := &chainInfoPeerMock{uri: `peer1`, height: 90}
+
+	bestAlive := runStrategy(ctx, checker.Strategy(`org1`), best)
+	assert.True(t, <-bestAlive)
+
+	laggingAlive := runStrategy(ctx, checker.Strategy(`org1`), lagging)
+	assert.False(t, <-laggingAlive)
+
+	// peer of other MSP is compared only with peers of its MSP
+	otherMSPAlive := runStrategy(ctx, checker.Strategy(`org2`), &chainInfoPeerMock{uri: `peer2`, height: 10})
+	assert.True(t, <-otherMSPAlive)
+
+	lagging.setHeight(97)
+	assert.Eventually(t, func() bool { return <-laggingAlive }, time.Second, time.Millisecond)
+}
+
+func TestLedgerHeightCheckerEviction(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	checker := client.NewLedgerHeightChecker([]string{`channel`}, 5, 10*time.Millisecond)
+
+	removedCtx, remove := context.WithCancel(ctx)
+	removedAlive := runStrategy(removedCtx, checker.Strategy(`org1`), &chainInfoPeerMock{uri: `peer0`, height: 100})
+	assert.True(t, <-removedAlive)
+
+	live := &chainInfoPeerMock{uri: `peer1`, height: 90}
+	liveAlive := runStrategy(ctx, checker.Strategy(`org1`), live)
+	assert.False(t, <-liveAlive)
+
+	// height of removed peer doesn't mark live peer lagging
+	remove()
+	assert.Eventually(t, func() bool { return <-liveAlive }, time.Second, time.Millisecond)
+}
+
+func TestStrategyComposite(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	peer := &chainInfoPeerMock{uri: `peer0`, err: errors.New(`unavailable`)}
+
+	alwaysAlive := func(ctx context.Context, _ api.Peer, alive chan bool) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case alive <- true:
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}
+
+	alive := runStrategy(ctx, client.StrategyComposite(alwaysAlive, client.StrategyQueryProbe(`channel`, 5*time.Millisecond)), peer)
+
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case a := <-alive:
+			if !a {
+				return
+			}
+		case <-deadline:
+			t.Fatal(`composite strategy must report peer as not alive`)
+		}
+	}
+}
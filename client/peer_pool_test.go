@@ -0,0 +1,123 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/client"
+)
+
+type closablePeerMock struct {
+	api.Peer
+
+	uri    string
+	closed bool
+}
+
+func (p *closablePeerMock) URI() string {
+	return p.uri
+}
+
+func (p *closablePeerMock) Close() error {
+	p.closed = true
+	return nil
+}
+
+// checkStrategyMock reports when peer checks are stopped
+func checkStrategyMock(stopped chan string) api.PeerPoolCheckStrategy {
+	return func(ctx context.Context, peer api.Peer, alive chan bool) {
+		<-ctx.Done()
+		stopped <- peer.URI()
+	}
+}
+
+func TestPeerPoolRemove(t *testing.T) {
+	pool := client.NewPeerPool(context.Background(), zap.NewNop())
+	stopped := make(chan string, 2)
+
+	peer1 := &closablePeerMock{uri: `peer1`}
+	peer2 := &closablePeerMock{uri: `peer2`}
+	require.NoError(t, pool.Add(`org1`, peer1, checkStrategyMock(stopped)))
+	require.NoError(t, pool.Add(`org1`, peer2, checkStrategyMock(stopped)))
+
+	require.NoError(t, pool.Remove(`org1`, `peer1`))
+	assert.True(t, peer1.closed)
+	assert.False(t, peer2.closed)
+	assert.Equal(t, `peer1`, <-stopped)
+	assert.Equal(t, []api.Peer{peer2}, pool.GetMSPPeers(`org1`))
+
+	assert.ErrorIs(t, pool.Remove(`org1`, `peer1`), client.ErrPeerNotFound)
+
+	require.NoError(t, pool.Remove(`org1`, `peer2`))
+	_, err := pool.FirstReadyPeer(`org1`)
+	assert.ErrorIs(t, err, client.ErrMSPNotFound)
+}
+
+func TestPeerPoolReplace(t *testing.T) {
+	pool := client.NewPeerPool(context.Background(), zap.NewNop())
+	stopped := make(chan string, 1)
+
+	oldPeer := &closablePeerMock{uri: `peer1`}
+	newPeer := &closablePeerMock{uri: `peer1`}
+	require.NoError(t, pool.Add(`org1`, oldPeer, checkStrategyMock(stopped)))
+	require.NoError(t, pool.Replace(`org1`, newPeer, checkStrategyMock(stopped)))
+
+	assert.True(t, oldPeer.closed)
+	assert.Equal(t, `peer1`, <-stopped)
+
+	peers := pool.GetMSPPeers(`org1`)
+	require.Len(t, peers, 1)
+	assert.Same(t, newPeer, peers[0])
+}
+
+func TestPeerPoolClose(t *testing.T) {
+	pool := client.NewPeerPool(context.Background(), zap.NewNop())
+	stopped := make(chan string, 2)
+
+	peer1 := &closablePeerMock{uri: `peer1`}
+	peer2 := &closablePeerMock{uri: `peer2`}
+	require.NoError(t, pool.Add(`org1`, peer1, checkStrategyMock(stopped)))
+	require.NoError(t, pool.Add(`org2`, peer2, checkStrategyMock(stopped)))
+
+	require.NoError(t, pool.Close())
+	assert.True(t, peer1.closed)
+	assert.True(t, peer2.closed)
+	assert.ElementsMatch(t, []string{`peer1`, `peer2`}, []string{<-stopped, <-stopped})
+	assert.Empty(t, pool.GetPeers())
+
+	assert.ErrorIs(t, pool.Add(`org1`, peer1, checkStrategyMock(stopped)), client.ErrPoolClosed)
+	assert.NoError(t, pool.Close())
+}
+
+type endorseErrPeerMock struct {
+	closablePeerMock
+
+	err error
+}
+
+func (p *endorseErrPeerMock) Endorse(context.Context, *peer.SignedProposal) (*peer.ProposalResponse, error) {
+	return nil, p.err
+}
+
+func TestPeerPoolEndorseError(t *testing.T) {
+	pool := client.NewPeerPool(context.Background(), zap.NewNop())
+	stopped := make(chan string, 2)
+
+	require.NoError(t, pool.Add(`org1`, &endorseErrPeerMock{closablePeerMock: closablePeerMock{uri: `peer1`}},
+		checkStrategyMock(stopped)))
+	require.NoError(t, pool.Add(`org2`, &endorseErrPeerMock{closablePeerMock: closablePeerMock{uri: `peer2`},
+		err: api.PeerEndorseError{Status: 500, Message: `chaincode error`}}, checkStrategyMock(stopped)))
+
+	_, err := pool.EndorseOnMSPs(context.Background(), []string{`org1`, `org2`}, &peer.SignedProposal{})
+
+	// endorsement error of MSP is matched through multi error
+	var endorseErr api.PeerEndorseError
+	require.ErrorAs(t, err, &endorseErr)
+	assert.Equal(t, api.PeerEndorseError{Status: 500, Message: `chaincode error`, MspID: `org2`, PeerURI: `peer2`}, endorseErr)
+}
@@ -0,0 +1,336 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric/common/policydsl"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+)
+
+// MaxPolicyMSPs limits number of distinct MSPs in policy, layouts are computed by enumerating MSP combinations
+const MaxPolicyMSPs = 16
+
+var (
+	ErrNilPolicy           = errors.New(`nil signature policy`)
+	ErrNilPolicyRule       = errors.New(`nil signature policy rule`)
+	ErrTooManyPolicyMSPs   = errors.New(`too many MSPs in policy`)
+	ErrUnknownPrincipal    = errors.New(`unknown principal classification`)
+	ErrPrincipalOutOfRange = errors.New(`signed by principal index out of range`)
+)
+
+// FromString parses endorsement policy in DSL format, i.e. "OutOf(2, 'Org1MSP.peer', 'Org2MSP.peer')"
+func FromString(policy string) (*common.SignaturePolicyEnvelope, error) {
+	env, err := policydsl.FromString(policy)
+	if err != nil {
+		return nil, fmt.Errorf(`parse policy: %w`, err)
+	}
+	return env, nil
+}
+
+// LayoutsFromString returns minimal endorsement layouts for policy in DSL format
+func LayoutsFromString(policy string) ([]api.EndorsementLayout, error) {
+	env, err := FromString(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return Layouts(env)
+}
+
+// MSPs returns distinct MSP identifiers of policy principals in order of appearance
+func MSPs(env *common.SignaturePolicyEnvelope) ([]string, error) {
+	if env == nil {
+		return nil, ErrNilPolicy
+	}
+
+	var (
+		mspIDs []string
+		seen   = make(map[string]struct{})
+	)
+
+	for _, principal := range env.Identities {
+		mspID, err := PrincipalMSP(principal)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := seen[mspID]; ok {
+			continue
+		}
+		seen[mspID] = struct{}{}
+		mspIDs = append(mspIDs, mspID)
+	}
+
+	return mspIDs, nil
+}
+
+// PrincipalMSP returns MSP identifier of policy principal
+func PrincipalMSP(principal *msp.MSPPrincipal) (string, error) {
+	switch principal.PrincipalClassification {
+	case msp.MSPPrincipal_ROLE:
+		role := &msp.MSPRole{}
+		if err := proto.Unmarshal(principal.Principal, role); err != nil {
+			return ``, fmt.Errorf(`unmarshal msp role: %w`, err)
+		}
+		return role.MspIdentifier, nil
+
+	case msp.MSPPrincipal_ORGANIZATION_UNIT:
+		ou := &msp.OrganizationUnit{}
+		if err := proto.Unmarshal(principal.Principal, ou); err != nil {
+			return ``, fmt.Errorf(`unmarshal organization unit: %w`, err)
+		}
+		return ou.MspIdentifier, nil
+
+	case msp.MSPPrincipal_IDENTITY:
+		id := &msp.SerializedIdentity{}
+		if err := proto.Unmarshal(principal.Principal, id); err != nil {
+			return ``, fmt.Errorf(`unmarshal serialized identity: %w`, err)
+		}
+		return id.Mspid, nil
+
+	default:
+		return ``, fmt.Errorf(`%w: %s`, ErrUnknownPrincipal, principal.PrincipalClassification)
+	}
+}
+
+// Satisfied checks that endorsements from one peer of each provided MSP satisfy signature policy.
+// As in fabric policy evaluation, each endorsement can be used only once
+func Satisfied(env *common.SignaturePolicyEnvelope, mspIDs []string) (bool, error) {
+	if env == nil {
+		return false, ErrNilPolicy
+	}
+
+	principals := make([]string, len(env.Identities))
+	for i, principal := range env.Identities {
+		mspID, err := PrincipalMSP(principal)
+		if err != nil {
+			return false, err
+		}
+		principals[i] = mspID
+	}
+
+	available := make(map[string]bool, len(mspIDs))
+	for _, mspID := range mspIDs {
+		available[mspID] = true
+	}
+
+	return evaluate(env.Rule, principals, available, make(map[string]bool))
+}
+
+func evaluate(rule *common.SignaturePolicy, principals []string, available, used map[string]bool) (bool, error) {
+	if rule == nil {
+		return false, ErrNilPolicyRule
+	}
+
+	switch t := rule.Type.(type) {
+	case *common.SignaturePolicy_SignedBy:
+		if t.SignedBy < 0 || int(t.SignedBy) >= len(principals) {
+			return false, fmt.Errorf(`%w: %d`, ErrPrincipalOutOfRange, t.SignedBy)
+		}
+
+		mspID := principals[t.SignedBy]
+		if available[mspID] && !used[mspID] {
+			used[mspID] = true
+			return true, nil
+		}
+		return false, nil
+
+	case *common.SignaturePolicy_NOutOf_:
+		// evaluate on a copy, used endorsements are committed only if rule is satisfied
+		ruleUsed := make(map[string]bool, len(used))
+		for k, v := range used {
+			ruleUsed[k] = v
+		}
+
+		var verified int32
+		for _, sub := range t.NOutOf.Rules {
+			ok, err := evaluate(sub, principals, available, ruleUsed)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				verified++
+			}
+		}
+
+		if verified < t.NOutOf.N {
+			return false, nil
+		}
+
+		for k, v := range ruleUsed {
+			used[k] = v
+		}
+		return true, nil
+
+	default:
+		return false, fmt.Errorf(`unknown signature policy type: %T`, rule.Type)
+	}
+}
+
+// Layouts returns all minimal sets of MSPs, endorsements from which satisfy signature policy.
+// Layouts are sorted by number of MSPs, so the cheapest layout goes first
+func Layouts(env *common.SignaturePolicyEnvelope) ([]api.EndorsementLayout, error) {
+	mspIDs, err := MSPs(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(mspIDs) > MaxPolicyMSPs {
+		return nil, fmt.Errorf(`%w: %d, max %d`, ErrTooManyPolicyMSPs, len(mspIDs), MaxPolicyMSPs)
+	}
+
+	// deterministic order of MSPs inside layouts
+	sort.Strings(mspIDs)
+
+	var masks []uint32
+	for mask := uint32(1); mask < 1<<len(mspIDs); mask++ {
+		masks = append(masks, mask)
+	}
+
+	// check smaller combinations first, supersets of satisfying combination are not minimal
+	sort.SliceStable(masks, func(i, j int) bool {
+		return bitsCount(masks[i]) < bitsCount(masks[j])
+	})
+
+	var (
+		satisfying []uint32
+		layouts    []api.EndorsementLayout
+	)
+
+masks:
+	for _, mask := range masks {
+		for _, s := range satisfying {
+			if mask&s == s {
+				continue masks
+			}
+		}
+
+		layout := layoutFromMask(mspIDs, mask)
+		ok, err := Satisfied(env, layout)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			satisfying = append(satisfying, mask)
+			layouts = append(layouts, layout)
+		}
+	}
+
+	return layouts, nil
+}
+
+// SortLayouts sorts layouts by number of MSPs, keeping original order of equal layouts
+func SortLayouts(layouts []api.EndorsementLayout) []api.EndorsementLayout {
+	sorted := make([]api.EndorsementLayout, len(layouts))
+	copy(sorted, layouts)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i]) < len(sorted[j])
+	})
+
+	return sorted
+}
+
+// MergeLayouts returns minimal layouts, satisfying each of layout sets, i.e. endorsement policies of
+// several chaincodes, called in one transaction. Empty layout set doesn't restrict result
+func MergeLayouts(layoutSets ...[]api.EndorsementLayout) []api.EndorsementLayout {
+	merged := []map[string]struct{}{{}}
+
+	for _, layouts := range layoutSets {
+		if len(layouts) == 0 {
+			continue
+		}
+
+		var product []map[string]struct{}
+		for _, base := range merged {
+			for _, layout := range layouts {
+				union := make(map[string]struct{}, len(base)+len(layout))
+				for mspID := range base {
+					union[mspID] = struct{}{}
+				}
+				for _, mspID := range layout {
+					union[mspID] = struct{}{}
+				}
+				product = append(product, union)
+			}
+		}
+		merged = product
+	}
+
+	var layouts []api.EndorsementLayout
+	for _, set := range merged {
+		if len(set) == 0 {
+			continue
+		}
+
+		layout := make(api.EndorsementLayout, 0, len(set))
+		for mspID := range set {
+			layout = append(layout, mspID)
+		}
+		sort.Strings(layout)
+		layouts = append(layouts, layout)
+	}
+
+	// sort by size and content for deterministic result, then drop layouts containing smaller ones
+	sort.SliceStable(layouts, func(i, j int) bool {
+		if len(layouts[i]) != len(layouts[j]) {
+			return len(layouts[i]) < len(layouts[j])
+		}
+		return strings.Join(layouts[i], `,`) < strings.Join(layouts[j], `,`)
+	})
+
+	var minimal []api.EndorsementLayout
+	for _, layout := range layouts {
+		if !containsAnyLayout(layout, minimal) {
+			minimal = append(minimal, layout)
+		}
+	}
+
+	return minimal
+}
+
+// containsAnyLayout reports whether layout contains all MSPs of any of layouts
+func containsAnyLayout(layout api.EndorsementLayout, layouts []api.EndorsementLayout) bool {
+	mspIDs := make(map[string]struct{}, len(layout))
+	for _, mspID := range layout {
+		mspIDs[mspID] = struct{}{}
+	}
+
+layouts:
+	for _, l := range layouts {
+		for _, mspID := range l {
+			if _, ok := mspIDs[mspID]; !ok {
+				continue layouts
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+func layoutFromMask(mspIDs []string, mask uint32) api.EndorsementLayout {
+	var layout api.EndorsementLayout
+	for i, mspID := range mspIDs {
+		if mask&(1<<i) != 0 {
+			layout = append(layout, mspID)
+		}
+	}
+	return layout
+}
+
+func bitsCount(mask uint32) int {
+	var count int
+	for ; mask != 0; mask &= mask - 1 {
+		count++
+	}
+	return count
+}
@@ -0,0 +1,93 @@
+package policy_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/client/policy"
+)
+
+func TestLayoutsFromString(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		policy  string
+		layouts []api.EndorsementLayout
+	}{
+		{
+			name:    `single org`,
+			policy:  `AND('Org1MSP.admin')`,
+			layouts: []api.EndorsementLayout{{`Org1MSP`}},
+		},
+		{
+			name:    `all orgs`,
+			policy:  `AND('Org1MSP.peer', 'Org2MSP.peer')`,
+			layouts: []api.EndorsementLayout{{`Org1MSP`, `Org2MSP`}},
+		},
+		{
+			name:    `any org`,
+			policy:  `OR('Org1MSP.peer', 'Org2MSP.member')`,
+			layouts: []api.EndorsementLayout{{`Org1MSP`}, {`Org2MSP`}},
+		},
+		{
+			name:   `2 out of 3`,
+			policy: `OutOf(2, 'Org1MSP.peer', 'Org2MSP.peer', 'Org3MSP.peer')`,
+			layouts: []api.EndorsementLayout{
+				{`Org1MSP`, `Org2MSP`}, {`Org1MSP`, `Org3MSP`}, {`Org2MSP`, `Org3MSP`},
+			},
+		},
+		{
+			name:   `nested`,
+			policy: `AND('Org1MSP.peer', OR('Org2MSP.peer', AND('Org3MSP.peer', 'Org4MSP.peer')))`,
+			layouts: []api.EndorsementLayout{
+				{`Org1MSP`, `Org2MSP`}, {`Org1MSP`, `Org3MSP`, `Org4MSP`},
+			},
+		},
+		{
+			name:    `same org twice can not be satisfied with one endorsement`,
+			policy:  `OutOf(2, 'Org1MSP.peer', 'Org1MSP.member', 'Org2MSP.peer')`,
+			layouts: []api.EndorsementLayout{{`Org1MSP`, `Org2MSP`}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			layouts, err := policy.LayoutsFromString(tc.policy)
+			require.NoError(t, err)
+			assert.Equal(t, tc.layouts, layouts)
+		})
+	}
+}
+
+func TestSatisfied(t *testing.T) {
+	env, err := policy.FromString(`OutOf(2, 'Org1MSP.peer', 'Org2MSP.peer', 'Org3MSP.peer')`)
+	require.NoError(t, err)
+
+	ok, err := policy.Satisfied(env, []string{`Org1MSP`, `Org3MSP`})
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = policy.Satisfied(env, []string{`Org2MSP`, `Org4MSP`})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSortLayouts(t *testing.T) {
+	sorted := policy.SortLayouts([]api.EndorsementLayout{{`a`, `b`, `c`}, {`d`}, {`e`, `f`}, {`g`}})
+	assert.Equal(t, []api.EndorsementLayout{{`d`}, {`g`}, {`e`, `f`}, {`a`, `b`, `c`}}, sorted)
+}
+
+func TestMergeLayouts(t *testing.T) {
+	merged := policy.MergeLayouts(
+		[]api.EndorsementLayout{{`Org1MSP`}, {`Org2MSP`}},
+		[]api.EndorsementLayout{{`Org2MSP`, `Org3MSP`}},
+		nil,
+	)
+	assert.Equal(t, []api.EndorsementLayout{{`Org2MSP`, `Org3MSP`}}, merged)
+
+	merged = policy.MergeLayouts(
+		[]api.EndorsementLayout{{`Org1MSP`}, {`Org2MSP`}},
+		[]api.EndorsementLayout{{`Org1MSP`}, {`Org3MSP`}},
+	)
+	assert.Equal(t, []api.EndorsementLayout{{`Org1MSP`}, {`Org2MSP`, `Org3MSP`}}, merged)
+}
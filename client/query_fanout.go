@@ -0,0 +1,248 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	fabPeer "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/msp"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	clienterrors "github.com/s7techlab/hlf-sdk-go/client/errors"
+	"github.com/s7techlab/hlf-sdk-go/client/tx"
+)
+
+// QueryRequest - chaincode query, evaluated on several pool peers.
+// If Identity is nil, peer identity is used
+type QueryRequest struct {
+	Channel   string
+	Chaincode string
+	Args      [][]byte
+	Identity  msp.SigningIdentity
+	Transient map[string][]byte
+}
+
+// PeerQueryResponse - query response or error of pool peer
+type PeerQueryResponse struct {
+	MspID    string
+	PeerURI  string
+	Response *fabPeer.Response
+	Err      error
+}
+
+// QueryAllResult - query responses of all requested peers
+type QueryAllResult struct {
+	Responses []PeerQueryResponse
+	// Consistent - all peers responded successfully with the same payload
+	Consistent bool
+}
+
+// QueryAll evaluates query concurrently on all peers of MSPs (all pool MSPs if not set)
+// and compares their responses. Peer errors are returned in responses
+func QueryAll(ctx context.Context, pool api.PeerPool, req QueryRequest, mspIDs ...string) (*QueryAllResult, error) {
+	if len(mspIDs) == 0 {
+		for mspID := range pool.GetPeers() {
+			mspIDs = append(mspIDs, mspID)
+		}
+		sort.Strings(mspIDs)
+	}
+
+	var responses []PeerQueryResponse
+	var peers []api.Peer
+	for _, mspID := range mspIDs {
+		mspPeers := pool.GetMSPPeers(mspID)
+		if len(mspPeers) == 0 {
+			return nil, fmt.Errorf(`msp_id=%s: %w`, mspID, ErrNoPeersForMSP)
+		}
+
+		for _, p := range mspPeers {
+			responses = append(responses, PeerQueryResponse{MspID: mspID, PeerURI: p.URI()})
+			peers = append(peers, p)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range peers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i].Response, responses[i].Err = req.query(ctx, peers[i])
+		}(i)
+	}
+	wg.Wait()
+
+	return &QueryAllResult{
+		Responses:  responses,
+		Consistent: queryResponsesConsistent(responses),
+	}, nil
+}
+
+func queryResponsesConsistent(responses []PeerQueryResponse) bool {
+	for _, r := range responses {
+		if r.Err != nil || r.Response.GetStatus() != shim.OK ||
+			!bytes.Equal(r.Response.GetPayload(), responses[0].Response.GetPayload()) {
+			return false
+		}
+	}
+
+	return len(responses) > 0
+}
+
+// QueryHedged sends query to ready peer of MSPs (identity MSP if not set) and, if response isn't received
+// after delay or peer fails, sends it to the next peer. The first successful response is returned
+// and other requests are cancelled. Chaincode error response is returned without hedging
+func QueryHedged(
+	ctx context.Context, pool api.PeerPool, req QueryRequest, delay time.Duration, mspIDs ...string) (
+	*fabPeer.Response, error) {
+	if len(mspIDs) == 0 {
+		if req.Identity == nil {
+			return nil, ErrEndorsingMSPsRequired
+		}
+		mspIDs = []string{req.Identity.GetMSPIdentifier()}
+	}
+
+	peers := hedgedQueryPeers(pool, mspIDs)
+	if len(peers) == 0 {
+		return nil, fmt.Errorf(`msp_ids=%v: %w`, mspIDs, ErrNoPeersForMSP)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type queryResult struct {
+		uri      string
+		response *fabPeer.Response
+		err      error
+	}
+
+	results := make(chan queryResult, len(peers))
+	sent, pending := 0, 0
+	send := func() {
+		p := peers[sent]
+		sent++
+		pending++
+		go func() {
+			response, err := req.query(ctx, p)
+			results <- queryResult{uri: p.URI(), response: response, err: err}
+		}()
+	}
+
+	hedge := time.NewTimer(delay)
+	defer hedge.Stop()
+
+	// timer is stopped and drained before reset, so stale tick doesn't send next query without delay
+	resetHedge := func() {
+		if !hedge.Stop() {
+			select {
+			case <-hedge.C:
+			default:
+			}
+		}
+		hedge.Reset(delay)
+	}
+
+	send()
+	errs := new(clienterrors.MultiError)
+	for pending > 0 {
+		select {
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				return res.response, nil
+			}
+
+			var endorseErr api.PeerEndorseError
+			if errors.As(res.err, &endorseErr) {
+				return nil, res.err
+			}
+
+			errs.Add(fmt.Errorf(`peer %s: %w`, res.uri, res.err))
+			if sent < len(peers) {
+				send()
+				resetHedge()
+			}
+
+		case <-hedge.C:
+			if sent < len(peers) {
+				send()
+				hedge.Reset(delay)
+			}
+
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, errs
+}
+
+// readyPeersPool - peer pool, which returns ready peers of MSP with available circuit breakers
+type readyPeersPool interface {
+	ReadyPeers(mspID string) []api.Peer
+}
+
+// hedgedQueryPeers returns ready peers of MSPs. If pool can't list ready peers, first ready peer of each MSP is used
+func hedgedQueryPeers(pool api.PeerPool, mspIDs []string) []api.Peer {
+	var peers []api.Peer
+	for _, mspID := range mspIDs {
+		if readyPool, ok := pool.(readyPeersPool); ok {
+			peers = append(peers, readyPool.ReadyPeers(mspID)...)
+			continue
+		}
+
+		if ready, err := pool.FirstReadyPeer(mspID); err == nil {
+			peers = append(peers, ready)
+		}
+	}
+
+	return peers
+}
+
+func (r QueryRequest) query(ctx context.Context, peer api.Peer) (*fabPeer.Response, error) {
+	return peer.Query(ctx, r.Channel, r.Chaincode, r.Args, r.Identity, r.Transient)
+}
+
+// QueryAll evaluates query on all peers of MSPs (all pool MSPs if not set), see QueryAll
+func (c *Client) QueryAll(
+	ctx context.Context,
+	channel string,
+	chaincode string,
+	args [][]byte,
+	identity msp.SigningIdentity,
+	transient map[string][]byte,
+	mspIDs ...string,
+) (*QueryAllResult, error) {
+	return QueryAll(ctx, c.PeerPool(), QueryRequest{
+		Channel:   channel,
+		Chaincode: chaincode,
+		Args:      args,
+		Identity:  tx.ChooseSigner(ctx, identity, c.CurrentIdentity()),
+		Transient: transient,
+	}, mspIDs...)
+}
+
+// QueryHedged returns the first successful response of peers of MSPs (identity MSP if not set), see QueryHedged
+func (c *Client) QueryHedged(
+	ctx context.Context,
+	channel string,
+	chaincode string,
+	args [][]byte,
+	identity msp.SigningIdentity,
+	transient map[string][]byte,
+	delay time.Duration,
+	mspIDs ...string,
+) (*fabPeer.Response, error) {
+	return QueryHedged(ctx, c.PeerPool(), QueryRequest{
+		Channel:   channel,
+		Chaincode: chaincode,
+		Args:      args,
+		Identity:  tx.ChooseSigner(ctx, identity, c.CurrentIdentity()),
+		Transient: transient,
+	}, delay, mspIDs...)
+}
@@ -0,0 +1,191 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/s7techlab/hlf-sdk-go/api"
+	"github.com/s7techlab/hlf-sdk-go/client"
+)
+
+type queryPeerMock struct {
+	closablePeerMock
+
+	delay   time.Duration
+	payload string
+	err     error
+}
+
+func (p *queryPeerMock) Query(ctx context.Context, _, _ string, _ [][]byte, _ msp.SigningIdentity,
+	_ map[string][]byte) (*peer.Response, error) {
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &peer.Response{Status: 200, Payload: []byte(p.payload)}, nil
+}
+
+func newQueryPool(t *testing.T, peers map[string][]*queryPeerMock) api.PeerPool {
+	pool := client.NewPeerPool(context.Background(), zap.NewNop())
+	t.Cleanup(func() { _ = pool.Close() })
+
+	noCheck := func(ctx context.Context, peer api.Peer, alive chan bool) {}
+	for mspID, mspPeers := range peers {
+		for _, p := range mspPeers {
+			require.NoError(t, pool.Add(mspID, p, noCheck))
+		}
+	}
+
+	return pool
+}
+
+func queryPeer(uri, payload string, delay time.Duration, err error) *queryPeerMock {
+	return &queryPeerMock{closablePeerMock: closablePeerMock{uri: uri}, payload: payload, delay: delay, err: err}
+}
+
+func TestQueryAll(t *testing.T) {
+	ctx := context.Background()
+	req := client.QueryRequest{Channel: `channel`, Chaincode: `cc`}
+
+	t.Run(`consistent`, func(t *testing.T) {
+		pool := newQueryPool(t, map[string][]*queryPeerMock{
+			`org1`: {queryPeer(`peer1`, `value`, 0, nil), queryPeer(`peer2`, `value`, 0, nil)},
+			`org2`: {queryPeer(`peer3`, `value`, 10*time.Millisecond, nil)},
+		})
+
+		res, err := client.QueryAll(ctx, pool, req)
+		require.NoError(t, err)
+		assert.True(t, res.Consistent)
+		require.Len(t, res.Responses, 3)
+		assert.Equal(t, `org2`, res.Responses[2].MspID)
+		assert.Equal(t, `peer3`, res.Responses[2].PeerURI)
+		assert.Equal(t, []byte(`value`), res.Responses[2].Response.Payload)
+	})
+
+	t.Run(`payloads differ`, func(t *testing.T) {
+		pool := newQueryPool(t, map[string][]*queryPeerMock{
+			`org1`: {queryPeer(`peer1`, `value`, 0, nil)},
+			`org2`: {queryPeer(`peer2`, `other`, 0, nil)},
+		})
+
+		res, err := client.QueryAll(ctx, pool, req)
+		require.NoError(t, err)
+		assert.False(t, res.Consistent)
+	})
+
+	t.Run(`peer error`, func(t *testing.T) {
+		peerErr := errors.New(`unavailable`)
+		pool := newQueryPool(t, map[string][]*queryPeerMock{
+			`org1`: {queryPeer(`peer1`, `value`, 0, nil)},
+			`org2`: {queryPeer(`peer2`, ``, 0, peerErr)},
+		})
+
+		res, err := client.QueryAll(ctx, pool, req, `org2`)
+		require.NoError(t, err)
+		assert.False(t, res.Consistent)
+		require.Len(t, res.Responses, 1)
+		assert.ErrorIs(t, res.Responses[0].Err, peerErr)
+	})
+
+	t.Run(`unknown MSP`, func(t *testing.T) {
+		pool := newQueryPool(t, nil)
+
+		_, err := client.QueryAll(ctx, pool, req, `org1`)
+		assert.ErrorIs(t, err, client.ErrNoPeersForMSP)
+	})
+}
+
+func TestQueryHedged(t *testing.T) {
+	ctx := context.Background()
+	req := client.QueryRequest{Channel: `channel`, Chaincode: `cc`}
+
+	t.Run(`slow peer is hedged`, func(t *testing.T) {
+		pool := newQueryPool(t, map[string][]*queryPeerMock{
+			`org1`: {queryPeer(`peer1`, `slow`, time.Second, nil), queryPeer(`peer2`, `fast`, 0, nil)},
+		})
+
+		started := time.Now()
+		res, err := client.QueryHedged(ctx, pool, req, 20*time.Millisecond, `org1`)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(`fast`), res.Payload)
+		assert.Less(t, time.Since(started), time.Second)
+	})
+
+	t.Run(`fast peer isn't hedged`, func(t *testing.T) {
+		pool := newQueryPool(t, map[string][]*queryPeerMock{
+			`org1`: {queryPeer(`peer1`, `first`, 0, nil), queryPeer(`peer2`, `second`, 0, nil)},
+		})
+
+		res, err := client.QueryHedged(ctx, pool, req, time.Second, `org1`)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(`first`), res.Payload)
+	})
+
+	t.Run(`failed peer is hedged without delay`, func(t *testing.T) {
+		pool := newQueryPool(t, map[string][]*queryPeerMock{
+			`org1`: {queryPeer(`peer1`, ``, 0, errors.New(`unavailable`))},
+			`org2`: {queryPeer(`peer2`, `value`, 0, nil)},
+		})
+
+		res, err := client.QueryHedged(ctx, pool, req, time.Minute, `org1`, `org2`)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(`value`), res.Payload)
+	})
+
+	t.Run(`chaincode error isn't hedged`, func(t *testing.T) {
+		pool := newQueryPool(t, map[string][]*queryPeerMock{
+			`org1`: {
+				queryPeer(`peer1`, ``, 0, api.PeerEndorseError{Status: 500, Message: `not found`}),
+				queryPeer(`peer2`, `value`, 0, nil),
+			},
+		})
+
+		_, err := client.QueryHedged(ctx, pool, req, time.Minute, `org1`)
+		var endorseErr api.PeerEndorseError
+		assert.ErrorAs(t, err, &endorseErr)
+	})
+
+	t.Run(`all peers failed`, func(t *testing.T) {
+		peerErr := errors.New(`unavailable`)
+		pool := newQueryPool(t, map[string][]*queryPeerMock{
+			`org1`: {queryPeer(`peer1`, ``, 0, peerErr), queryPeer(`peer2`, ``, 0, peerErr)},
+		})
+
+		_, err := client.QueryHedged(ctx, pool, req, time.Minute, `org1`)
+		assert.ErrorIs(t, err, peerErr)
+	})
+
+	t.Run(`not ready peer isn't queried`, func(t *testing.T) {
+		pool := client.NewPeerPool(context.Background(), zap.NewNop())
+		t.Cleanup(func() { _ = pool.Close() })
+
+		notAlive := func(ctx context.Context, peer api.Peer, alive chan bool) { alive <- false }
+		noCheck := func(ctx context.Context, peer api.Peer, alive chan bool) {}
+		require.NoError(t, pool.Add(`org1`, queryPeer(`peer1`, `not ready`, 0, nil), notAlive))
+		require.NoError(t, pool.Add(`org1`, queryPeer(`peer2`, `ready`, 0, nil), noCheck))
+
+		require.Eventually(t, func() bool { return len(pool.ReadyPeers(`org1`)) == 1 }, time.Second, time.Millisecond)
+
+		res, err := client.QueryHedged(ctx, pool, req, time.Minute, `org1`)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(`ready`), res.Payload)
+	})
+
+	t.Run(`MSPs required without identity`, func(t *testing.T) {
+		_, err := client.QueryHedged(ctx, newQueryPool(t, nil), req, time.Minute)
+		assert.ErrorIs(t, err, client.ErrEndorsingMSPsRequired)
+	})
+}
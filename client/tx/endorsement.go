@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/hyperledger/fabric-protos-go/common"
 	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric/msp"
 
@@ -44,29 +43,11 @@ func NewEndorsementSignedProposal(
 		return nil, ``, fmt.Errorf(`serialize signer: %w`, err)
 	}
 
-	txParams, err := GenerateParamsForSerializedIdentity(signerSerialized)
+	proposal, err := NewEndorsementUnsignedProposal(channel, chaincode, args, signerSerialized, transientMap)
 	if err != nil {
-		return nil, ``, fmt.Errorf(`tx id: %w`, err)
+		return nil, ``, err
 	}
 
-	header, err := block.NewMarshalledCommonHeader(
-		common.HeaderType_ENDORSER_TRANSACTION,
-		txParams.ID,
-		txParams.Nonce,
-		txParams.Timestamp,
-		signerSerialized,
-		channel,
-		chaincode,
-		nil)
-	if err != nil {
-		return nil, ``, fmt.Errorf(`tx header: %w`, err)
-	}
-
-	proposal, err := block.NewMarshaledPeerProposal(header, chaincode, args, transientMap)
-	if err != nil {
-		return nil, ``, fmt.Errorf(`proposal: %w`, err)
-	}
-
-	signedProposal, err = block.NewPeerSignedProposal(proposal, signer)
-	return signedProposal, txParams.ID, err
+	signedProposal, err = block.NewPeerSignedProposal(proposal.ProposalBytes, signer)
+	return signedProposal, proposal.TxID, err
 }
@@ -0,0 +1,176 @@
+package tx
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/protoutil"
+
+	"github.com/s7techlab/hlf-sdk-go/block"
+)
+
+var (
+	ErrCreatorNotDefined   = errors.New(`creator not defined`)
+	ErrSignatureNotDefined = errors.New(`signature not defined`)
+)
+
+// UnsignedProposal - endorsement proposal, signed outside of SDK, i.e. by HSM or mobile wallet.
+// Signer signs ProposalBytes (or Digest, if signer accepts prehashed message) with creator key,
+// ECDSA signature must be ASN.1 encoded with low S value, as fabric requires.
+// Struct is JSON serializable for passing between systems
+type UnsignedProposal struct {
+	TxID          string `json:"tx_id"`
+	Channel       string `json:"channel"`
+	ProposalBytes []byte `json:"proposal_bytes"`
+}
+
+// UnsignedTransaction - transaction from endorsed proposal, signed outside of SDK.
+// Signer signs PayloadBytes (or Digest) with the same key as proposal.
+// Struct is JSON serializable for passing between systems
+type UnsignedTransaction struct {
+	TxID         string `json:"tx_id"`
+	Channel      string `json:"channel"`
+	PayloadBytes []byte `json:"payload_bytes"`
+}
+
+// UnsignedProposal creates proposal for creator serialized identity, signer of endorsement is not used
+func (e Endorsement) UnsignedProposal(creator []byte) (*UnsignedProposal, error) {
+	return NewEndorsementUnsignedProposal(e.Channel, e.Chaincode, e.Args, creator, e.TransientMap)
+}
+
+func NewEndorsementUnsignedProposal(
+	channel, chaincode string, args [][]byte, creator []byte, transientMap map[string][]byte) (
+	*UnsignedProposal, error) {
+
+	if chaincode == `` {
+		return nil, ErrChaincodeNotDefined
+	}
+	if len(creator) == 0 {
+		return nil, ErrCreatorNotDefined
+	}
+
+	txParams, err := GenerateParamsForSerializedIdentity(creator)
+	if err != nil {
+		return nil, fmt.Errorf(`tx id: %w`, err)
+	}
+
+	header, err := block.NewMarshalledCommonHeader(
+		common.HeaderType_ENDORSER_TRANSACTION,
+		txParams.ID,
+		txParams.Nonce,
+		txParams.Timestamp,
+		creator,
+		channel,
+		chaincode,
+		nil)
+	if err != nil {
+		return nil, fmt.Errorf(`tx header: %w`, err)
+	}
+
+	proposal, err := block.NewMarshaledPeerProposal(header, chaincode, args, transientMap)
+	if err != nil {
+		return nil, fmt.Errorf(`proposal: %w`, err)
+	}
+
+	return &UnsignedProposal{
+		TxID:          txParams.ID,
+		Channel:       channel,
+		ProposalBytes: proposal,
+	}, nil
+}
+
+// Digest returns SHA-256 digest of proposal bytes
+func (p *UnsignedProposal) Digest() []byte {
+	return digest(p.ProposalBytes)
+}
+
+// SignedProposal returns proposal with signature, produced by creator key, ready for endorsement
+func (p *UnsignedProposal) SignedProposal(signature []byte) (*peer.SignedProposal, error) {
+	if len(signature) == 0 {
+		return nil, ErrSignatureNotDefined
+	}
+
+	return &peer.SignedProposal{
+		ProposalBytes: p.ProposalBytes,
+		Signature:     signature,
+	}, nil
+}
+
+// NewUnsignedTransaction creates transaction payload from proposal and endorsements, like protoutil.CreateSignedTx,
+// but without signing
+func NewUnsignedTransaction(proposal *peer.SignedProposal, responses []*peer.ProposalResponse) (
+	*UnsignedTransaction, error) {
+
+	prop := new(peer.Proposal)
+	if err := proto.Unmarshal(proposal.GetProposalBytes(), prop); err != nil {
+		return nil, fmt.Errorf(`unmarshal proposal: %w`, err)
+	}
+
+	header, err := protoutil.UnmarshalHeader(prop.Header)
+	if err != nil {
+		return nil, fmt.Errorf(`proposal header: %w`, err)
+	}
+
+	chHeader, err := protoutil.UnmarshalChannelHeader(header.ChannelHeader)
+	if err != nil {
+		return nil, fmt.Errorf(`proposal channel header: %w`, err)
+	}
+
+	sigHeader, err := protoutil.UnmarshalSignatureHeader(header.SignatureHeader)
+	if err != nil {
+		return nil, fmt.Errorf(`proposal signature header: %w`, err)
+	}
+
+	// payload bytes are captured instead of signing
+	signer := &payloadCapture{creator: sigHeader.Creator}
+	if _, err = protoutil.CreateSignedTx(prop, signer, responses...); err != nil {
+		return nil, fmt.Errorf(`create transaction: %w`, err)
+	}
+
+	return &UnsignedTransaction{
+		TxID:         chHeader.TxId,
+		Channel:      chHeader.ChannelId,
+		PayloadBytes: signer.payload,
+	}, nil
+}
+
+// Digest returns SHA-256 digest of transaction payload bytes
+func (t *UnsignedTransaction) Digest() []byte {
+	return digest(t.PayloadBytes)
+}
+
+// Envelope returns transaction envelope with signature, produced by creator key, ready for broadcast to orderer
+func (t *UnsignedTransaction) Envelope(signature []byte) (*common.Envelope, error) {
+	if len(signature) == 0 {
+		return nil, ErrSignatureNotDefined
+	}
+
+	return &common.Envelope{
+		Payload:   t.PayloadBytes,
+		Signature: signature,
+	}, nil
+}
+
+func digest(msg []byte) []byte {
+	h := sha256.Sum256(msg)
+	return h[:]
+}
+
+// payloadCapture implements protoutil.Signer and keeps message instead of signing it
+type payloadCapture struct {
+	creator []byte
+	payload []byte
+}
+
+func (c *payloadCapture) Serialize() ([]byte, error) {
+	return c.creator, nil
+}
+
+func (c *payloadCapture) Sign(msg []byte) ([]byte, error) {
+	c.payload = msg
+	return nil, nil
+}
@@ -0,0 +1,127 @@
+package tx_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/s7techlab/hlf-sdk-go/client/tx"
+)
+
+func TestOfflineSigning(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	creator, err := proto.Marshal(&msp.SerializedIdentity{Mspid: `Org1MSP`, IdBytes: []byte(`cert`)})
+	require.NoError(t, err)
+
+	unsignedProposal, err := tx.Endorsement{
+		Channel:   `channel`,
+		Chaincode: `chaincode`,
+		Args:      tx.StringArgsBytes(`fn`, `arg`),
+	}.UnsignedProposal(creator)
+	require.NoError(t, err)
+
+	t.Run(`proposal is JSON serializable`, func(t *testing.T) {
+		data, err := json.Marshal(unsignedProposal)
+		require.NoError(t, err)
+
+		restored := new(tx.UnsignedProposal)
+		require.NoError(t, json.Unmarshal(data, restored))
+		assert.Equal(t, unsignedProposal, restored)
+	})
+
+	digest := sha256.Sum256(unsignedProposal.ProposalBytes)
+	assert.Equal(t, digest[:], unsignedProposal.Digest())
+
+	_, err = unsignedProposal.SignedProposal(nil)
+	assert.ErrorIs(t, err, tx.ErrSignatureNotDefined)
+
+	signedProposal, err := unsignedProposal.SignedProposal(signDigest(t, key, unsignedProposal.Digest()))
+	require.NoError(t, err)
+	assert.True(t, ecdsa.VerifyASN1(&key.PublicKey, unsignedProposal.Digest(), signedProposal.Signature))
+
+	responses := []*peer.ProposalResponse{{
+		Response:    &peer.Response{Status: 200},
+		Payload:     []byte(`response payload`),
+		Endorsement: &peer.Endorsement{Endorser: []byte(`endorser`), Signature: []byte(`signature`)},
+	}}
+
+	unsignedTx, err := tx.NewUnsignedTransaction(signedProposal, responses)
+	require.NoError(t, err)
+	assert.Equal(t, unsignedProposal.TxID, unsignedTx.TxID)
+	assert.Equal(t, `channel`, unsignedTx.Channel)
+
+	t.Run(`transaction is JSON serializable`, func(t *testing.T) {
+		data, err := json.Marshal(unsignedTx)
+		require.NoError(t, err)
+
+		restored := new(tx.UnsignedTransaction)
+		require.NoError(t, json.Unmarshal(data, restored))
+		assert.Equal(t, unsignedTx, restored)
+	})
+
+	envelope, err := unsignedTx.Envelope(signDigest(t, key, unsignedTx.Digest()))
+	require.NoError(t, err)
+	assert.True(t, ecdsa.VerifyASN1(&key.PublicKey, unsignedTx.Digest(), envelope.Signature))
+
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	require.NoError(t, err)
+
+	chHeader, err := protoutil.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	require.NoError(t, err)
+	assert.Equal(t, unsignedProposal.TxID, chHeader.TxId)
+
+	sigHeader, err := protoutil.UnmarshalSignatureHeader(payload.Header.SignatureHeader)
+	require.NoError(t, err)
+	assert.Equal(t, creator, sigHeader.Creator)
+
+	transaction, err := protoutil.UnmarshalTransaction(payload.Data)
+	require.NoError(t, err)
+
+	ccActionPayload, err := protoutil.UnmarshalChaincodeActionPayload(transaction.Actions[0].Payload)
+	require.NoError(t, err)
+	assert.Equal(t, responses[0].Payload, ccActionPayload.Action.ProposalResponsePayload)
+	require.Len(t, ccActionPayload.Action.Endorsements, 1)
+	assert.True(t, proto.Equal(responses[0].Endorsement, ccActionPayload.Action.Endorsements[0]))
+}
+
+func TestOfflineSigningErrors(t *testing.T) {
+	_, err := tx.NewEndorsementUnsignedProposal(`channel`, `chaincode`, nil, nil, nil)
+	assert.ErrorIs(t, err, tx.ErrCreatorNotDefined)
+
+	_, err = tx.NewEndorsementUnsignedProposal(`channel`, ``, nil, []byte(`creator`), nil)
+	assert.ErrorIs(t, err, tx.ErrChaincodeNotDefined)
+
+	unsignedProposal, err := tx.NewEndorsementUnsignedProposal(`channel`, `chaincode`, nil, []byte(`creator`), nil)
+	require.NoError(t, err)
+
+	signedProposal, err := unsignedProposal.SignedProposal([]byte(`signature`))
+	require.NoError(t, err)
+
+	// failed endorsement can't be included in transaction
+	_, err = tx.NewUnsignedTransaction(signedProposal, []*peer.ProposalResponse{{
+		Response: &peer.Response{Status: 500, Message: `chaincode error`},
+	}})
+	assert.Error(t, err)
+
+	unsignedTx := &tx.UnsignedTransaction{PayloadBytes: []byte(`payload`)}
+	_, err = unsignedTx.Envelope(nil)
+	assert.ErrorIs(t, err, tx.ErrSignatureNotDefined)
+}
+
+func signDigest(t *testing.T, key *ecdsa.PrivateKey, digest []byte) []byte {
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest)
+	require.NoError(t, err)
+	return signature
+}
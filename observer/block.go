@@ -1,6 +1,22 @@
 package observer
 
+import (
+	"context"
+)
+
 type Block[T any] struct {
 	Channel string
 	Block   T
+
+	// ack saves block checkpoint, if observer checkpoint is set
+	ack func(ctx context.Context) error
+}
+
+// Ack marks block processed. If observer is created with checkpoint, block checkpoint is saved
+// and observing is resumed from the next block after restart
+func (b *Block[T]) Ack(ctx context.Context) error {
+	if b.ack == nil {
+		return nil
+	}
+	return b.ack(ctx)
 }
@@ -9,6 +9,7 @@ import (
 	"go.uber.org/zap"
 
 	hlfproto "github.com/s7techlab/hlf-sdk-go/block"
+	"github.com/s7techlab/hlf-sdk-go/client/deliver"
 )
 
 type (
@@ -22,8 +23,13 @@ type (
 
 		stopRecreateStream bool
 
+		// checkpoints - if set, observing is resumed from the block next to the last acknowledged one
+		checkpoints *deliver.Checkpointer
+
 		isWork        bool
 		cancelObserve context.CancelFunc
+		// observeDone - closed, when blocks delivery of the last Observe is finished
+		observeDone chan struct{}
 	}
 
 	ChannelBlocksOpts struct {
@@ -31,6 +37,9 @@ type (
 
 		// don't recreate stream if it has not any blocks
 		stopRecreateStream bool
+
+		checkpointStore deliver.CheckpointStore
+		checkpointName  string
 	}
 
 	ChannelBlocksOpt func(*ChannelBlocksOpts)
@@ -48,6 +57,17 @@ func WithChannelStopRecreateStream(stop bool) ChannelBlocksOpt {
 	}
 }
 
+// WithChannelBlocksCheckpoint sets store of channel blocks checkpoint with key of subscription name.
+// Block checkpoint is saved on Block.Ack, observing is started from the block next to checkpoint,
+// seekFromFetcher is used only if there is no checkpoint. Delivery is at-least-once:
+// blocks, which are not acknowledged before restart, are delivered again
+func WithChannelBlocksCheckpoint(store deliver.CheckpointStore, name string) ChannelBlocksOpt {
+	return func(opts *ChannelBlocksOpts) {
+		opts.checkpointStore = store
+		opts.checkpointName = name
+	}
+}
+
 var DefaultChannelBlocksOpts = &ChannelBlocksOpts{
 	Opts:               DefaultOpts,
 	stopRecreateStream: false,
@@ -61,12 +81,15 @@ func NewChannelBlocks[T any](
 	opts ...ChannelBlocksOpt,
 ) *ChannelBlocks[T] {
 
-	channelBlocksOpts := DefaultChannelBlocksOpts
+	// copy defaults, so options don't change them
+	defaultOpts := *DefaultChannelBlocksOpts.Opts
+	channelBlocksOpts := *DefaultChannelBlocksOpts
+	channelBlocksOpts.Opts = &defaultOpts
 	for _, opt := range opts {
-		opt(channelBlocksOpts)
+		opt(&channelBlocksOpts)
 	}
 
-	return &ChannelBlocks[T]{
+	cb := &ChannelBlocks[T]{
 		Channel: &Channel{
 			channel:         channel,
 			seekFromFetcher: seekFromFetcher,
@@ -78,37 +101,90 @@ func NewChannelBlocks[T any](
 		createStreamWithRetry: createStreamWithRetry,
 		stopRecreateStream:    channelBlocksOpts.stopRecreateStream,
 	}
+
+	if channelBlocksOpts.checkpointStore != nil {
+		cb.checkpoints = deliver.NewCheckpointer(
+			channelBlocksOpts.checkpointStore, deliver.CheckpointKey(channel, channelBlocksOpts.checkpointName))
+		cb.seekFromFetcher = cb.checkpointSeekFrom(seekFromFetcher)
+	}
+
+	return cb
 }
 
+// checkpointSeekFrom returns number of block next to checkpoint block, when observing is (re)started.
+// seekFromFetcher is used, if there is no checkpoint
+func (cb *ChannelBlocks[T]) checkpointSeekFrom(seekFromFetcher SeekFromFetcher) SeekFromFetcher {
+	return func(ctx context.Context, channel string) (uint64, error) {
+		checkpoint, err := cb.checkpoints.Load(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		if checkpoint != nil {
+			return checkpoint.Block + 1, nil
+		}
+
+		return seekFromFetcher(ctx, channel)
+	}
+}
+
+// Stop stops observing and waits until blocks delivery is finished
 func (cb *ChannelBlocks[T]) Stop() error {
 	cb.mu.Lock()
-	defer cb.mu.Unlock()
 
 	// cb.channelWithBlocks mustn't be closed here, because it is closed elsewhere
 
 	err := cb.Channel.stop()
 
-	// If primary context is done then cancel ctxObserver
+	// If primary context is done then cancel ctxObserve
 	if cb.cancelObserve != nil {
 		cb.cancelObserve()
 	}
 
 	cb.isWork = false
+	observeDone := cb.observeDone
+	cb.mu.Unlock()
+
+	// lock is released, so delivery can finish
+	if observeDone != nil {
+		<-observeDone
+	}
+
 	return err
 }
 
+// observeStopped stops observing, when observe context is done, if observing isn't stopped or restarted yet
+func (cb *ChannelBlocks[T]) observeStopped(observeDone chan struct{}) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if !cb.isWork || cb.observeDone != observeDone {
+		return
+	}
+
+	if err := cb.Channel.stop(); err != nil {
+		cb.lastError = err
+	}
+	cb.cancelObserve()
+	cb.isWork = false
+}
+
 func (cb *ChannelBlocks[T]) Observe(ctx context.Context) (<-chan *Block[T], error) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
+	// blocks delivery of previous Observe is finished before new one is started
+	for !cb.isWork && cb.observeDone != nil && !isClosed(cb.observeDone) {
+		prevDone := cb.observeDone
+		cb.mu.Unlock()
+		<-prevDone
+		cb.mu.Lock()
+	}
+
 	if cb.isWork {
 		return cb.channelWithBlocks, nil
 	}
 
-	// ctxObserve using for nested control process without stopped primary context
-	ctxObserve, cancel := context.WithCancel(ctx)
-	cb.cancelObserve = cancel
-
 	if err := cb.allowToObserve(); err != nil {
 		return nil, err
 	}
@@ -118,15 +194,36 @@ func (cb *ChannelBlocks[T]) Observe(ctx context.Context) (<-chan *Block[T], erro
 		return nil, err
 	}
 
-	cb.channelWithBlocks = make(chan *Block[T])
+	// ctxObserve using for nested control process without stopped primary context
+	ctxObserve, cancel := context.WithCancel(ctx)
+	cb.cancelObserve = cancel
+
+	// each Observe has own blocks channel and delivery position, so delivery of previous Observe
+	// doesn't affect it
+	blocks := make(chan *Block[T])
+	observeDone := make(chan struct{})
+	cb.channelWithBlocks, cb.observeDone, cb.isWork = blocks, observeDone, true
 
 	go func() {
-		cb.isWork = true
-
-		defer close(cb.channelWithBlocks)
+		defer close(observeDone)
+		defer close(blocks)
+
+		// stream of each Observe is started from checkpoint, blocks delivered, but not acknowledged
+		// before Stop, are delivered again. Stream, interrupted within Observe, is recreated from
+		// the block next to the last delivered one
+		var (
+			nextBlock uint64
+			delivered bool
+		)
+		createStream := func(ctx context.Context) (<-chan T, error) {
+			if cb.checkpoints != nil && delivered {
+				return cb.createStreamFrom(ctx, &nextBlock)
+			}
+			return cb.createStreamFrom(ctx, nil)
+		}
 
 		cb.logger.Debug(`creating block stream`)
-		incomingBlocks, errCreateStream := cb.createStreamWithRetry(ctxObserve, cb.createStream)
+		incomingBlocks, errCreateStream := cb.createStreamWithRetry(ctxObserve, createStream)
 		if errCreateStream != nil {
 			return
 		}
@@ -139,7 +236,7 @@ func (cb *ChannelBlocks[T]) Observe(ctx context.Context) (<-chan *Block[T], erro
 				var err error
 				if !hasMore && !cb.stopRecreateStream {
 					cb.logger.Debug(`block stream interrupted, recreate`)
-					incomingBlocks, err = cb.createStreamWithRetry(ctx, cb.createStream)
+					incomingBlocks, err = cb.createStreamWithRetry(ctxObserve, createStream)
 					if err != nil {
 						return
 					}
@@ -148,46 +245,72 @@ func (cb *ChannelBlocks[T]) Observe(ctx context.Context) (<-chan *Block[T], erro
 					continue
 				}
 
+				var (
+					number uint64
+					txs    int
+				)
 				switch t := any(incomingBlock).(type) {
 				case *common.Block:
 					if t == nil {
 						continue
 					}
+					number, txs = t.GetHeader().GetNumber(), len(t.GetData().GetData())
 
 				case *hlfproto.Block:
 					if t == nil {
 						continue
 					}
+					number, txs = t.GetHeader().GetNumber(), len(t.GetData().GetEnvelopes())
 
 				default:
 					continue
 				}
 
-				cb.channelWithBlocks <- &Block[T]{
+				block := &Block[T]{
 					Channel: cb.channel,
 					Block:   incomingBlock,
 				}
 
-			case <-ctxObserve.Done():
-				if err := cb.Stop(); err != nil {
-					cb.lastError = err
+				if cb.checkpoints != nil {
+					cb.checkpoints.Delivered(deliver.Checkpoint{Block: number, TxIndex: txs - 1})
+					block.ack = func(ctx context.Context) error {
+						return cb.checkpoints.Save(ctx, deliver.Checkpoint{Block: number, TxIndex: txs - 1})
+					}
+				}
+
+				select {
+				case blocks <- block:
+					nextBlock, delivered = number+1, true
+				case <-ctxObserve.Done():
+					cb.observeStopped(observeDone)
+					return
 				}
+
+			case <-ctxObserve.Done():
+				cb.observeStopped(observeDone)
 				return
 			}
 		}
 	}()
 
-	return cb.channelWithBlocks, nil
+	return blocks, nil
 }
 
-func (cb *ChannelBlocks[T]) createStream(ctx context.Context) (<-chan T, error) {
+// createStreamFrom creates blocks stream from resume block, if it's set, or from block of seek from fetcher
+func (cb *ChannelBlocks[T]) createStreamFrom(ctx context.Context, resumeFrom *uint64) (<-chan T, error) {
 	cb.preCreateStream()
 
 	cb.logger.Debug(`connecting to blocks stream, receiving seek offset`,
 		zap.Uint64(`attempt`, cb.connectAttempt))
 
-	seekFrom, err := cb.processSeekFrom(ctx)
-	if err != nil {
+	var (
+		seekFrom uint64
+		err      error
+	)
+	if resumeFrom != nil {
+		seekFrom = *resumeFrom
+		cb.lastSeekFrom = seekFrom
+	} else if seekFrom, err = cb.processSeekFrom(ctx); err != nil {
 		cb.logger.Warn(`seek from failed`, zap.Error(err))
 		return nil, err
 	}
@@ -218,3 +341,12 @@ func (cb *ChannelBlocks[T]) createStream(ctx context.Context) (<-chan T, error)
 
 	return blocks, nil
 }
+
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,104 @@
+package observer_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/s7techlab/hlf-sdk-go/client/deliver"
+	sdkmocks "github.com/s7techlab/hlf-sdk-go/client/deliver/testing"
+	"github.com/s7techlab/hlf-sdk-go/observer"
+	testdata "github.com/s7techlab/hlf-sdk-go/testdata/blocks"
+)
+
+var _ = Describe("Channel blocks checkpoint", func() {
+	const closeChannelWhenAllRead = false
+
+	var blockDelivererMock *sdkmocks.BlocksDelivererMock
+	BeforeEach(func() {
+		var err error
+		blockDelivererMock, err = sdkmocks.NewBlocksDelivererMock(fmt.Sprintf("../%s", testdata.Path), closeChannelWhenAllRead)
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	observe := func(ctx context.Context, store deliver.CheckpointStore) <-chan *observer.Block[*common.Block] {
+		channelBlocks := observer.NewChannelBlocksCommon(testdata.SampleChannel, blockDelivererMock,
+			observer.ChannelSeekOldest(), observer.WithChannelBlocksCheckpoint(store, `test`))
+
+		blocks, err := channelBlocks.Observe(ctx)
+		Expect(err).ShouldNot(HaveOccurred())
+		return blocks
+	}
+
+	It("should resume observing from the block next to the last acknowledged one", func() {
+		store := deliver.NewMemoryCheckpointStore()
+
+		ctx1, cancel1 := context.WithCancel(ctx)
+		blocks := observe(ctx1, store)
+
+		for i := uint64(0); i < 5; i++ {
+			b := <-blocks
+			Expect(b.Block.Header.Number).To(Equal(i))
+
+			// block 4 is received, but not processed before restart
+			if i < 4 {
+				Expect(b.Ack(ctx)).ShouldNot(HaveOccurred())
+			}
+		}
+		cancel1()
+
+		checkpoint, err := store.Load(ctx, deliver.CheckpointKey(testdata.SampleChannel, `test`))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(checkpoint.Block).To(Equal(uint64(3)))
+
+		ctx2, cancel2 := context.WithCancel(ctx)
+		defer cancel2()
+		blocks = observe(ctx2, store)
+
+		// at-least-once: not acknowledged block 4 is delivered again
+		for i := uint64(4); i < testdata.SampleChannelHeight; i++ {
+			b := <-blocks
+			Expect(b.Block.Header.Number).To(Equal(i))
+			Expect(b.Ack(ctx)).ShouldNot(HaveOccurred())
+		}
+
+		checkpoint, err = store.Load(ctx, deliver.CheckpointKey(testdata.SampleChannel, `test`))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(checkpoint.Block).To(Equal(testdata.SampleChannelHeight - 1))
+	})
+
+	It("should resume observing from checkpoint, when the same channel blocks are observed again", func() {
+		store := deliver.NewMemoryCheckpointStore()
+		channelBlocks := observer.NewChannelBlocksCommon(testdata.SampleChannel, blockDelivererMock,
+			observer.ChannelSeekOldest(), observer.WithChannelBlocksCheckpoint(store, `test`))
+
+		blocks, err := channelBlocks.Observe(ctx)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		var delivered []*observer.Block[*common.Block]
+		for i := uint64(0); i < 3; i++ {
+			delivered = append(delivered, <-blocks)
+		}
+
+		// out of order acknowledge: block 2 is acknowledged before block 1, block 0 isn't acknowledged
+		Expect(delivered[2].Ack(ctx)).ShouldNot(HaveOccurred())
+		Expect(delivered[1].Ack(ctx)).ShouldNot(HaveOccurred())
+		Expect(channelBlocks.Stop()).ShouldNot(HaveOccurred())
+
+		checkpoint, err := store.Load(ctx, deliver.CheckpointKey(testdata.SampleChannel, `test`))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(checkpoint).To(BeNil())
+
+		ctx2, cancel2 := context.WithCancel(ctx)
+		defer cancel2()
+		blocks, err = channelBlocks.Observe(ctx2)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		// not acknowledged block 0 is delivered again, though blocks up to 2 were delivered by previous Observe
+		b := <-blocks
+		Expect(b.Block.Header.Number).To(Equal(uint64(0)))
+	})
+})
@@ -7,6 +7,8 @@ import (
 
 	"github.com/hyperledger/fabric/msp"
 	"go.uber.org/zap"
+
+	"github.com/s7techlab/hlf-sdk-go/client/deliver"
 )
 
 const DefaultChannelsBLocksPeerRefreshPeriod = 10 * time.Second
@@ -28,6 +30,9 @@ type (
 		seekFromFetcher    SeekFromFetcher
 		stopRecreateStream bool
 
+		checkpointStore deliver.CheckpointStore
+		checkpointName  string
+
 		isWork        sync.Mutex
 		cancelObserve context.CancelFunc
 
@@ -40,6 +45,8 @@ type (
 		seekFromFetcher    SeekFromFetcher
 		refreshPeriod      time.Duration
 		stopRecreateStream bool
+		checkpointStore    deliver.CheckpointStore
+		checkpointName     string
 		logger             *zap.Logger
 	}
 
@@ -83,6 +90,14 @@ func WithBlockStopRecreateStream(stop bool) ChannelsBlocksPeerOpt {
 	}
 }
 
+// WithChannelsBlocksPeerCheckpoint sets store of channels blocks checkpoints, see WithChannelBlocksCheckpoint
+func WithChannelsBlocksPeerCheckpoint(store deliver.CheckpointStore, name string) ChannelsBlocksPeerOpt {
+	return func(opts *ChannelsBlocksPeerOpts) {
+		opts.checkpointStore = store
+		opts.checkpointName = name
+	}
+}
+
 func NewChannelsBlocksPeer[T any](
 	peerChannelsGetter PeerChannelsGetter,
 	deliverer func(context.Context, string, msp.SigningIdentity, ...int64) (<-chan T, func() error, error),
@@ -90,9 +105,10 @@ func NewChannelsBlocksPeer[T any](
 	opts ...ChannelsBlocksPeerOpt,
 ) *ChannelsBlocksPeer[T] {
 
-	channelsBlocksPeerOpts := DefaultChannelsBlocksPeerOpts
+	// copy defaults, so options don't change them
+	channelsBlocksPeerOpts := *DefaultChannelsBlocksPeerOpts
 	for _, opt := range opts {
-		opt(channelsBlocksPeerOpts)
+		opt(&channelsBlocksPeerOpts)
 	}
 
 	return &ChannelsBlocksPeer[T]{
@@ -107,6 +123,8 @@ func NewChannelsBlocksPeer[T any](
 		seekFrom:           channelsBlocksPeerOpts.seekFrom,
 		seekFromFetcher:    channelsBlocksPeerOpts.seekFromFetcher,
 		stopRecreateStream: channelsBlocksPeerOpts.stopRecreateStream,
+		checkpointStore:    channelsBlocksPeerOpts.checkpointStore,
+		checkpointName:     channelsBlocksPeerOpts.checkpointName,
 		logger:             channelsBlocksPeerOpts.logger,
 	}
 }
@@ -231,7 +249,8 @@ func (acb *ChannelsBlocksPeer[T]) initChannelsObservers() []*ChannelBlocks[T] {
 				acb.createStreamWithRetry,
 				seekFrom,
 				WithChannelBlockLogger(acb.logger),
-				WithChannelStopRecreateStream(acb.stopRecreateStream))
+				WithChannelStopRecreateStream(acb.stopRecreateStream),
+				WithChannelBlocksCheckpoint(acb.checkpointStore, acb.checkpointName))
 
 			acb.mu.Lock()
 			acb.channelObservers[channel] = chBlocks